@@ -0,0 +1,131 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package gocsi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+
+	log "github.com/sirupsen/logrus"
+
+	csictx "github.com/dell/gocsi/context"
+	"github.com/dell/gocsi/middleware/serialvolume"
+)
+
+// serveAdmin serves the admin and debug endpoints on the address
+// specified by X_CSI_ADMIN_ADDR. A nil server is returned if no address
+// is set. The endpoints that report the storage plug-in's state are only
+// served if the provider is a StoragePlugin.
+func serveAdmin(
+	ctx context.Context, sp StoragePluginProvider,
+) (*http.Server, error) {
+	addr, ok := csictx.LookupEnv(ctx, EnvVarAdminAddr)
+	if !ok || addr == "" {
+		return nil, nil
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/loglevel", handleLogLevel)
+
+	if p, ok := sp.(*StoragePlugin); ok {
+		p.registerAdminHandlers(ctx, mux)
+	}
+
+	return serveHTTP("admin", addr, mux)
+}
+
+// registerAdminHandlers registers the endpoints that report the storage
+// plug-in's effective configuration, interceptor chain, in-flight RPCs,
+// and held serial volume locks.
+func (sp *StoragePlugin) registerAdminHandlers(
+	ctx context.Context, mux *http.ServeMux,
+) {
+	ctx = csictx.WithLookupEnv(ctx, sp.lookupEnv)
+
+	mux.HandleFunc("/config", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, sp.effectiveConfig(ctx))
+	})
+	mux.HandleFunc("/interceptors", func(w http.ResponseWriter, _ *http.Request) {
+		sp.reloadL.Lock()
+		chain := sp.chain
+		sp.reloadL.Unlock()
+		if chain == nil {
+			writeJSON(w, interceptorNames{})
+			return
+		}
+		writeJSON(w, chain.names())
+	})
+	mux.HandleFunc("/inflight", func(w http.ResponseWriter, _ *http.Request) {
+		sp.reloadL.Lock()
+		inflight := sp.shared.inflight
+		sp.reloadL.Unlock()
+		rpcs := []inflightRPCInfo{}
+		if inflight != nil {
+			rpcs = inflight.list()
+		}
+		writeJSON(w, rpcs)
+	})
+	mux.HandleFunc("/locks", func(w http.ResponseWriter, _ *http.Request) {
+		sp.reloadL.Lock()
+		locks := sp.shared.locks
+		sp.reloadL.Unlock()
+		held := []serialvolume.HeldLock{}
+		if locks != nil {
+			held = locks.Held()
+		}
+		writeJSON(w, held)
+	})
+}
+
+// handleLogLevel reports the log level. A PUT or POST request with the
+// level parameter, ex. level=debug, sets the log level.
+func handleLogLevel(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		lvl, err := log.ParseLevel(r.FormValue("level"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.SetLevel(lvl)
+		log.WithField("level", lvl).Info("set log level")
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, map[string]string{"level": log.GetLevel().String()})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.WithError(err).Warn("failed to write admin response")
+	}
+}
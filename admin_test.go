@@ -0,0 +1,158 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package gocsi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	csictx "github.com/dell/gocsi/context"
+	"github.com/dell/gocsi/middleware/serialvolume"
+	"github.com/dell/gocsi/utils/middleware"
+)
+
+func getJSON(t *testing.T, srv *httptest.Server, path string, v interface{}) {
+	rep, err := http.Get(srv.URL + path)
+	assert.NoError(t, err)
+	defer rep.Body.Close()
+	assert.Equal(t, http.StatusOK, rep.StatusCode)
+	assert.NoError(t, json.NewDecoder(rep.Body).Decode(v))
+}
+
+func TestServeAdminDisabled(t *testing.T) {
+	srv, err := serveAdmin(context.Background(), &StoragePlugin{})
+	assert.NoError(t, err)
+	assert.Nil(t, srv)
+}
+
+func TestServeAdminEnvVarDefault(t *testing.T) {
+	sp := &StoragePlugin{EnvVars: []string{EnvVarAdminAddr + "=127.0.0.1:0"}}
+	srv, err := serveAdmin(withEnvVarDefaults(context.Background(), sp), sp)
+	assert.NoError(t, err)
+	if assert.NotNil(t, srv) {
+		assert.NoError(t, srv.Close())
+	}
+}
+
+func TestAdminHandlers(t *testing.T) {
+	t.Setenv(EnvVarReqLogging, "false")
+	t.Setenv(EnvVarRepLogging, "false")
+
+	sp := &StoragePlugin{EnvVars: []string{
+		EnvVarSerialVolAccess + "=true",
+		EnvVarSerialVolAccessEtcdPassword + "=secret",
+		"X_CSI_TEST_ADMIN=value",
+	}}
+	mux := http.NewServeMux()
+	sp.registerAdminHandlers(context.Background(), mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	// The handlers report no state before the storage plug-in serves.
+	var rpcs []inflightRPCInfo
+	getJSON(t, srv, "/inflight", &rpcs)
+	assert.Empty(t, rpcs)
+
+	ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+	sp.initEnvVars(ctx)
+	sp.reloadL.Lock()
+	assert.NoError(t, sp.initInterceptors(ctx))
+	sp.reloadL.Unlock()
+
+	var config map[string]string
+	getJSON(t, srv, "/config", &config)
+	assert.Equal(t, "true", config[EnvVarSerialVolAccess])
+	assert.Equal(t, "******", config[EnvVarSerialVolAccessEtcdPassword])
+	assert.Equal(t, "value", config["X_CSI_TEST_ADMIN"])
+
+	var names interceptorNames
+	getJSON(t, srv, "/interceptors", &names)
+	assert.Equal(t, interceptorNames{
+		Unary:  []string{"recovery", "context", "inflight", "serialvolume"},
+		Stream: []string{"recovery", "context", "inflight"},
+	}, names)
+
+	// The RPCs in flight and the locks they hold are reported.
+	_, err := middleware.ChainUnaryServer(sp.Interceptors...)(
+		context.Background(),
+		&csi.DeleteVolumeRequest{VolumeId: "vol-1"},
+		&grpc.UnaryServerInfo{FullMethod: "/csi.v1.Controller/DeleteVolume"},
+		func(context.Context, interface{}) (interface{}, error) {
+			var rpcs []inflightRPCInfo
+			getJSON(t, srv, "/inflight", &rpcs)
+			if assert.Len(t, rpcs, 1) {
+				assert.Equal(t, "/csi.v1.Controller/DeleteVolume", rpcs[0].Method)
+				assert.Equal(t, "vol-1", rpcs[0].VolumeID)
+			}
+
+			var locks []serialvolume.HeldLock
+			getJSON(t, srv, "/locks", &locks)
+			if assert.Len(t, locks, 1) {
+				assert.Equal(t, "DeleteVolume", locks[0].RPC)
+				assert.Equal(t, "volumeID", locks[0].Kind)
+				assert.Equal(t, "vol-1", locks[0].Key)
+			}
+			return &csi.DeleteVolumeResponse{}, nil
+		})
+	assert.NoError(t, err)
+
+	var locks []serialvolume.HeldLock
+	getJSON(t, srv, "/locks", &locks)
+	assert.Empty(t, locks)
+}
+
+func TestHandleLogLevel(t *testing.T) {
+	defer log.SetLevel(log.GetLevel())
+	log.SetLevel(log.InfoLevel)
+
+	srv := httptest.NewServer(http.HandlerFunc(handleLogLevel))
+	defer srv.Close()
+
+	var level map[string]string
+	getJSON(t, srv, "", &level)
+	assert.Equal(t, "info", level["level"])
+
+	rep, err := http.PostForm(srv.URL, url.Values{"level": {"debug"}})
+	assert.NoError(t, err)
+	assert.NoError(t, json.NewDecoder(rep.Body).Decode(&level))
+	rep.Body.Close()
+	assert.Equal(t, http.StatusOK, rep.StatusCode)
+	assert.Equal(t, "debug", level["level"])
+	assert.Equal(t, log.DebugLevel, log.GetLevel())
+
+	rep, err = http.PostForm(srv.URL, url.Values{"level": {"loud"}})
+	assert.NoError(t, err)
+	rep.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, rep.StatusCode)
+
+	req, _ := http.NewRequest(http.MethodDelete, srv.URL, nil)
+	rep, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	rep.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, rep.StatusCode)
+}
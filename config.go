@@ -0,0 +1,312 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package gocsi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	csictx "github.com/dell/gocsi/context"
+)
+
+// configKeys are the names of the environment variables defined by this
+// package that may be specified in the file named by X_CSI_CONFIG_FILE.
+var configKeys = []string{
+	EnvVarEndpoint,
+	EnvVarEndpointPerms,
+	EnvVarEndpointUser,
+	EnvVarEndpointGroup,
+	EnvVarEndpointRemoveStale,
+	EnvVarAdditionalEndpoints,
+	EnvVarTLSCert,
+	EnvVarTLSKey,
+	EnvVarTLSClientCA,
+	EnvVarHealthDisabled,
+	EnvVarHealthProbeInterval,
+	EnvVarHealthProbeFailureThreshold,
+	EnvVarShutdownTimeout,
+	EnvVarConfigPrint,
+	EnvVarDebug,
+	EnvVarLogLevel,
+	EnvVarPluginInfo,
+	EnvVarMode,
+	EnvVarReqLogging,
+	EnvVarRepLogging,
+	EnvVarLoggingDisableVolCtx,
+	EnvVarLogFormat,
+	EnvVarLogRedact,
+	EnvVarMetrics,
+	EnvVarMetricsAddr,
+	EnvVarAdminAddr,
+	EnvVarTracing,
+	EnvVarTracingPropagators,
+	EnvVarReqIDInjection,
+	EnvVarSpecValidation,
+	EnvVarSpecReqValidation,
+	EnvVarSpecRepValidation,
+	EnvVarDisableFieldLen,
+	EnvVarRequireStagingTargetPath,
+	EnvVarRequireVolContext,
+	EnvVarRequirePubContext,
+	EnvVarCreds,
+	EnvVarCredsCreateVol,
+	EnvVarCredsDeleteVol,
+	EnvVarCredsCtrlrPubVol,
+	EnvVarCredsCtrlrUnpubVol,
+	EnvVarCredsNodeStgVol,
+	EnvVarCredsNodePubVol,
+	EnvVarIdempotency,
+	EnvVarIdempotencyTTL,
+	EnvVarIdempotencyEtcd,
+	EnvVarIdempotencyEtcdDomain,
+	EnvVarIdempotencyEtcdTTL,
+	EnvVarLeaderElection,
+	EnvVarLeaderElectionIdentity,
+	EnvVarLeaderElectionForward,
+	EnvVarLeaderElectionForwardToken,
+	EnvVarLeaderElectionEtcdDomain,
+	EnvVarLeaderElectionEtcdTTL,
+	EnvVarRPCTimeout,
+	EnvVarRPCTimeoutMax,
+	EnvVarRPCTimeoutPerMethod,
+	EnvVarRPCTimeoutMaxPerMethod,
+	EnvVarDisableRecovery,
+	EnvVarConcurrencyMaxInFlight,
+	EnvVarConcurrencyMaxInFlightPerMethod,
+	EnvVarConcurrencyMaxQueue,
+	EnvVarConcurrencyRejectCode,
+	EnvVarCapabilityEnforcement,
+	EnvVarSelfCheck,
+	EnvVarSerialVolAccess,
+	EnvVarSerialVolAccessTimeout,
+	EnvVarSerialVolAccessRPCs,
+	EnvVarSerialVolAccessEtcdDomain,
+	EnvVarSerialVolAccessEtcdTTL,
+	EnvVarSerialVolAccessEtcdEndpoints,
+	EnvVarSerialVolAccessEtcdAutoSyncInterval,
+	EnvVarSerialVolAccessEtcdDialTimeout,
+	EnvVarSerialVolAccessEtcdDialKeepAliveTime,
+	EnvVarSerialVolAccessEtcdDialKeepAliveTimeout,
+	EnvVarSerialVolAccessEtcdMaxCallSendMsgSz,
+	EnvVarSerialVolAccessEtcdMaxCallRecvMsgSz,
+	EnvVarSerialVolAccessEtcdUsername,
+	EnvVarSerialVolAccessEtcdPassword,
+	EnvVarSerialVolAccessEtcdRejectOldCluster,
+	EnvVarSerialVolAccessEtcdTLS,
+	EnvVarSerialVolAccessEtcdTLSInsecure,
+}
+
+// configOutput is where the effective configuration is printed.
+var configOutput io.Writer = os.Stderr
+
+// readConfigFile reads the YAML or JSON configuration file at path. The
+// file must contain a single object whose values are scalars. The keys
+// are returned in upper-case.
+func readConfigFile(path string) (map[string]string, error) {
+	buf, err := os.ReadFile(path) // #nosec G304
+	if err != nil {
+		return nil, err
+	}
+	buf, err = yaml.YAMLToJSON(buf)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	var obj map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	if err := dec.Decode(&obj); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	config := map[string]string{}
+	for k, v := range obj {
+		key := strings.ToUpper(k)
+		switch tv := v.(type) {
+		case nil:
+			config[key] = ""
+		case string:
+			config[key] = tv
+		case bool, json.Number:
+			config[key] = fmt.Sprint(tv)
+		default:
+			return nil, fmt.Errorf(
+				"invalid config file %s: %s: value must be a scalar", path, k)
+		}
+	}
+	return config, nil
+}
+
+// withConfigFile returns a context whose environment falls back to the
+// file named by X_CSI_CONFIG_FILE for keys that are not otherwise set.
+// The file's keys are not validated since the keys declared by the
+// storage plug-in are not yet known.
+func withConfigFile(ctx context.Context) (context.Context, error) {
+	path := csictx.Getenv(ctx, EnvVarConfigFile)
+	if path == "" {
+		return ctx, nil
+	}
+	config, err := readConfigFile(path)
+	if err != nil {
+		return ctx, err
+	}
+	parent := ctx
+	return csictx.WithLookupEnv(ctx, func(key string) (string, bool) {
+		if v, ok := csictx.LookupEnv(parent, key); ok {
+			return v, true
+		}
+		v, ok := config[key]
+		return v, ok
+	}), nil
+}
+
+// initConfig loads the file named by X_CSI_CONFIG_FILE beneath the
+// storage plug-in's environment variables and prints the effective
+// configuration if X_CSI_CONFIG_PRINT is enabled. This must be called
+// after initEnvVars.
+func (sp *StoragePlugin) initConfig(ctx context.Context) error {
+	if path := csictx.Getenv(ctx, EnvVarConfigFile); path != "" {
+		config, err := readConfigFile(path)
+		if err != nil {
+			return err
+		}
+
+		known := map[string]bool{}
+		for _, k := range sp.configKeys() {
+			known[k] = true
+		}
+		var unknown []string
+		for k := range config {
+			if !known[k] {
+				unknown = append(unknown, k)
+			}
+		}
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			return fmt.Errorf("unknown keys in config file %s: %s",
+				path, strings.Join(unknown, ", "))
+		}
+
+		// Values set by the StoragePlugin.EnvVars defaults or the
+		// environment take precedence over the file.
+		configVars := map[string]string{}
+		for k, v := range config {
+			if _, ok := csictx.LookupEnv(ctx, k); !ok {
+				configVars[k] = v
+			}
+		}
+		sp.configVars = configVars
+
+		// The file may enable debug mode.
+		sp.initDebug(ctx)
+	}
+
+	if sp.getEnvBool(ctx, EnvVarConfigPrint) {
+		buf, err := yaml.Marshal(sp.effectiveConfig(ctx))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(configOutput, "effective configuration:\n%s", buf)
+	}
+	return nil
+}
+
+// configKeys returns the keys that may be specified in the config file:
+// the environment variables defined by this package and the ones
+// declared by the StoragePlugin.EnvVars defaults.
+func (sp *StoragePlugin) configKeys() []string {
+	keys := append([]string{}, configKeys...)
+	sp.envVarsL.RLock()
+	defer sp.envVarsL.RUnlock()
+	for k := range sp.envVars {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// sensitiveConfigSegments are the underscore-separated segments of the
+// names of the environment variables whose values are masked in the
+// effective configuration.
+var sensitiveConfigSegments = map[string]bool{
+	"PASSWORD":    true,
+	"SECRET":      true,
+	"TOKEN":       true,
+	"KEY":         true,
+	"CREDENTIAL":  true,
+	"CREDENTIALS": true,
+}
+
+// pathConfigSegments are the last segments of the names of the
+// environment variables whose values are file paths, which are never
+// masked, ex. X_CSI_PLUGIN_KEY_FILE.
+var pathConfigSegments = map[string]bool{
+	"FILE": true,
+	"PATH": true,
+	"DIR":  true,
+}
+
+// pathConfigKeys are the other environment variables with sensitive
+// segments whose values are file paths.
+var pathConfigKeys = map[string]bool{
+	EnvVarTLSKey: true,
+}
+
+// effectiveConfig returns the resolved values of the known environment
+// variables that are set. The values of sensitive variables, such as
+// passwords, secrets, tokens, keys, and credentials, are masked.
+func (sp *StoragePlugin) effectiveConfig(ctx context.Context) map[string]string {
+	config := map[string]string{}
+	for _, k := range sp.configKeys() {
+		v, ok := csictx.LookupEnv(ctx, k)
+		if !ok {
+			continue
+		}
+		if isSensitiveConfigKey(k) && v != "" {
+			v = "******"
+		}
+		config[k] = v
+	}
+	return config
+}
+
+// isSensitiveConfigKey returns whether or not the value of the provided
+// environment variable is masked in the effective configuration.
+func isSensitiveConfigKey(k string) bool {
+	if pathConfigKeys[k] {
+		return false
+	}
+	segments := strings.Split(k, "_")
+	if pathConfigSegments[segments[len(segments)-1]] {
+		return false
+	}
+	for _, s := range segments {
+		if sensitiveConfigSegments[s] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,259 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package gocsi
+
+import (
+	"bytes"
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	csictx "github.com/dell/gocsi/context"
+)
+
+func writeConfigFile(t *testing.T, name, data string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+	return path
+}
+
+func TestReadConfigFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		data      string
+		expected  map[string]string
+		expectErr string
+	}{
+		{
+			name: "yaml",
+			file: "config.yaml",
+			data: `
+X_CSI_DEBUG: true
+x_csi_log_level: debug
+X_CSI_SERIAL_VOL_ACCESS_ETCD_TTL: 10
+X_CSI_PLUGIN_INFO:
+`,
+			expected: map[string]string{
+				EnvVarDebug:                  "true",
+				EnvVarLogLevel:               "debug",
+				EnvVarSerialVolAccessEtcdTTL: "10",
+				EnvVarPluginInfo:             "",
+			},
+		},
+		{
+			name: "json",
+			file: "config.json",
+			data: `{"CSI_ENDPOINT": "unix:///csi.sock", "X_CSI_METRICS": false}`,
+			expected: map[string]string{
+				EnvVarEndpoint: "unix:///csi.sock",
+				EnvVarMetrics:  "false",
+			},
+		},
+		{
+			name:      "list value",
+			file:      "config.yaml",
+			data:      "X_CSI_MODE: [node]",
+			expectErr: "value must be a scalar",
+		},
+		{
+			name:      "not an object",
+			file:      "config.yaml",
+			data:      "- X_CSI_MODE",
+			expectErr: "invalid config file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := readConfigFile(writeConfigFile(t, tt.file, tt.data))
+			if tt.expectErr != "" {
+				assert.ErrorContains(t, err, tt.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, config)
+		})
+	}
+
+	_, err := readConfigFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestInitConfig(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+X_CSI_MODE: node
+X_CSI_LOG_FORMAT: json
+X_CSI_METRICS_ADDR: ":9090"
+X_CSI_PLUGIN_KEY: from-file
+X_CSI_SERIAL_VOL_ACCESS_ETCD_PASSWORD: secret
+`)
+
+	os.Setenv(EnvVarMetricsAddr, ":9091")
+	os.Setenv(EnvVarConfigFile, path)
+	os.Setenv(EnvVarConfigPrint, "true")
+	defer func() {
+		os.Unsetenv(EnvVarMetricsAddr)
+		os.Unsetenv(EnvVarConfigFile)
+		os.Unsetenv(EnvVarConfigPrint)
+	}()
+
+	var out bytes.Buffer
+	configOutput = &out
+	defer func() { configOutput = os.Stderr }()
+
+	sp := &StoragePlugin{
+		EnvVars: []string{
+			EnvVarLogFormat + "=text",
+			"X_CSI_PLUGIN_KEY=",
+		},
+	}
+	ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+	sp.initEnvVars(ctx)
+	assert.NoError(t, sp.initConfig(ctx))
+
+	// The file < the StoragePlugin.EnvVars defaults < the environment.
+	assert.Equal(t, "node", csictx.Getenv(ctx, EnvVarMode))
+	assert.Equal(t, "text", csictx.Getenv(ctx, EnvVarLogFormat))
+	assert.Equal(t, ":9091", csictx.Getenv(ctx, EnvVarMetricsAddr))
+	assert.Equal(t, "", csictx.Getenv(ctx, "X_CSI_PLUGIN_KEY"))
+
+	// The effective configuration is printed with passwords masked.
+	assert.Contains(t, out.String(), "X_CSI_MODE: node\n")
+	assert.Contains(t, out.String(), "X_CSI_METRICS_ADDR: :9091\n")
+	assert.Contains(t, out.String(), "X_CSI_SERIAL_VOL_ACCESS_ETCD_PASSWORD: '******'\n")
+	assert.NotContains(t, out.String(), "secret")
+}
+
+func TestInitConfigUnknownKeys(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+X_CSI_MODE: node
+X_CSI_MOD: node
+X_CSI_PLUGIN_KEY: value
+`)
+	sp := &StoragePlugin{
+		EnvVars: []string{EnvVarConfigFile + "=" + path},
+	}
+	ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+	sp.initEnvVars(ctx)
+	assert.EqualError(t, sp.initConfig(ctx),
+		"unknown keys in config file "+path+": X_CSI_MOD, X_CSI_PLUGIN_KEY")
+}
+
+func TestWithConfigFile(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+CSI_ENDPOINT: unix:///csi.sock
+X_CSI_LOG_LEVEL: debug
+`)
+	os.Setenv(EnvVarConfigFile, path)
+	os.Setenv(EnvVarLogLevel, "info")
+	defer func() {
+		os.Unsetenv(EnvVarConfigFile)
+		os.Unsetenv(EnvVarLogLevel)
+	}()
+
+	ctx, err := withConfigFile(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "unix:///csi.sock", csictx.Getenv(ctx, EnvVarEndpoint))
+	assert.Equal(t, "info", csictx.Getenv(ctx, EnvVarLogLevel))
+}
+
+// TestConfigKeys ensures every environment variable defined by the
+// package may be set in the config file.
+func TestConfigKeys(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "envvars.go", nil, 0)
+	assert.NoError(t, err)
+
+	keys := map[string]bool{}
+	for _, k := range configKeys {
+		keys[k] = true
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		vs, ok := n.(*ast.ValueSpec)
+		if !ok || !strings.HasPrefix(vs.Names[0].Name, "EnvVar") {
+			return true
+		}
+		v := strings.Trim(vs.Values[0].(*ast.BasicLit).Value, `"`)
+		if v != EnvVarConfigFile {
+			assert.True(t, keys[v], "%s is not a config key", v)
+		}
+		return true
+	})
+}
+
+func TestIsSensitiveConfigKey(t *testing.T) {
+	tests := []struct {
+		key       string
+		sensitive bool
+	}{
+		{EnvVarTLSKey, false},
+		{EnvVarTLSCert, false},
+		{EnvVarSerialVolAccessEtcdPassword, true},
+		{EnvVarLeaderElectionForwardToken, true},
+		{EnvVarCreds, false},
+		{"X_CSI_PLUGIN_API_KEY", true},
+		{"X_CSI_PLUGIN_KEY_FILE", false},
+		{"X_CSI_PLUGIN_CREDENTIALS", true},
+		{"X_CSI_PLUGIN_KEYRING", false},
+		{"X_CSI_PLUGIN_MONKEY", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.sensitive, isSensitiveConfigKey(tt.key))
+		})
+	}
+}
+
+func TestEffectiveConfig(t *testing.T) {
+	sp := &StoragePlugin{
+		EnvVars: []string{
+			EnvVarMode + "=node",
+			EnvVarTLSKey + "=/etc/tls/tls.key",
+			EnvVarSerialVolAccessEtcdPassword + "=secret",
+			"X_CSI_PLUGIN_CLIENT_SECRET=secret",
+			"X_CSI_PLUGIN_API_TOKEN=secret",
+			"X_CSI_PLUGIN_CREDENTIALS=secret",
+			"X_CSI_PLUGIN_EMPTY_TOKEN=",
+		},
+	}
+	ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+	sp.initEnvVars(ctx)
+
+	config := sp.effectiveConfig(ctx)
+	assert.Equal(t, "node", config[EnvVarMode])
+	assert.Equal(t, "", config["X_CSI_PLUGIN_EMPTY_TOKEN"])
+
+	// The path of the TLS key is shown.
+	assert.Equal(t, "/etc/tls/tls.key", config[EnvVarTLSKey])
+
+	for _, k := range []string{
+		EnvVarSerialVolAccessEtcdPassword,
+		"X_CSI_PLUGIN_CLIENT_SECRET",
+		"X_CSI_PLUGIN_API_TOKEN",
+		"X_CSI_PLUGIN_CREDENTIALS",
+	} {
+		assert.Equal(t, "******", config[k], k)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 
 	"google.golang.org/grpc/metadata"
 )
@@ -46,6 +47,10 @@ var (
 	// with the signature func(string, string) that can be used to set the
 	// value of an environment variable
 	ctxOSSetenvKey = interface{}("os.Setenev")
+
+	// ctxRequestIDHolderKey is an interface-wrapped key used to access a
+	// *requestIDHolder that records the request ID assigned to an RPC.
+	ctxRequestIDHolderKey = interface{}("csi.requestid.holder")
 )
 
 type (
@@ -53,8 +58,34 @@ type (
 	setenvFunc    func(string, string) error
 )
 
+// requestIDHolder records the request ID assigned to an RPC.
+type requestIDHolder struct {
+	sync.Mutex
+	id uint64
+	ok bool
+}
+
+// WithRequestIDHolder returns a new Context that records the request ID
+// assigned to the RPC with SetRequestID. This allows GetRequestID to
+// return a request ID generated by an interceptor that runs after the
+// one that created the Context.
+func WithRequestIDHolder(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxRequestIDHolderKey, &requestIDHolder{})
+}
+
+// SetRequestID records the request ID assigned to the RPC if the context
+// was created with WithRequestIDHolder.
+func SetRequestID(ctx context.Context, id uint64) {
+	if h, ok := ctx.Value(ctxRequestIDHolderKey).(*requestIDHolder); ok {
+		h.Lock()
+		defer h.Unlock()
+		h.id, h.ok = id, true
+	}
+}
+
 // GetRequestID inspects the context for gRPC metadata and returns
-// its request ID if available.
+// its request ID if available. Otherwise the request ID recorded with
+// SetRequestID is returned if available.
 func GetRequestID(ctx context.Context) (uint64, bool) {
 	var (
 		szID   []string
@@ -74,6 +105,12 @@ func GetRequestID(ctx context.Context) (uint64, bool) {
 		}
 	}
 
+	if h, ok := ctx.Value(ctxRequestIDHolderKey).(*requestIDHolder); ok {
+		h.Lock()
+		defer h.Unlock()
+		return h.id, h.ok
+	}
+
 	return 0, false
 }
 
@@ -56,6 +56,22 @@ func TestGetRequestID(t *testing.T) {
 			wantID:        102,
 			wantAvailable: true,
 		},
+		{
+			name:          "Negative test: no ID in holder",
+			ctx:           WithRequestIDHolder(context.Background()),
+			wantID:        0,
+			wantAvailable: false,
+		},
+		{
+			name: "Get request ID from holder",
+			ctx: func() context.Context {
+				ctx := WithRequestIDHolder(context.Background())
+				SetRequestID(ctx, 7)
+				return ctx
+			}(),
+			wantID:        7,
+			wantAvailable: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -43,6 +43,39 @@ func flagEndpoint(fs *flag.FlagSet, addr *string, def string) {
         absolute or relative filesystem path to a UNIX socket file`)
 }
 
+// flagCert adds the --cert flag to the specified flagset.
+func flagCert(fs *flag.FlagSet, addr *string) {
+	fs.StringVar(
+		addr,
+		"cert",
+		"",
+		`The path to the PEM-encoded certificate the client presents to the
+        server for mutual TLS. Specifying this flag enables TLS and
+        requires --key`)
+}
+
+// flagKey adds the --key flag to the specified flagset.
+func flagKey(fs *flag.FlagSet, addr *string) {
+	fs.StringVar(
+		addr,
+		"key",
+		"",
+		`The path to the PEM-encoded private key of the certificate
+        specified by --cert`)
+}
+
+// flagCACert adds the --cacert flag to the specified flagset.
+func flagCACert(fs *flag.FlagSet, addr *string) {
+	fs.StringVar(
+		addr,
+		"cacert",
+		"",
+		`The path to a PEM-encoded bundle of CA certificates used to verify
+        the server's certificate. Specifying this flag enables TLS. The
+        host's root CAs are used if TLS is enabled and this flag is not
+        specified`)
+}
+
 // flagLogLevel adds the -l,--log-level flag to the specified flagset.
 func flagLogLevel(fs *flag.FlagSet, addr *logLevelArg, def string) {
 	if def != "" {
@@ -91,6 +124,20 @@ func flagWithResponseLogging(fs *flag.FlagSet, addr *bool, def string) {
         logged at the INFO log level; so please adjust the log level accordingly.`)
 }
 
+// flagLogRedact adds the --log-redact flag to the specified flagset.
+func flagLogRedact(fs *flag.FlagSet, addr *[]string) {
+	fs.StringArrayVar(
+		addr,
+		"log-redact",
+		nil,
+		`A redaction rule that describes a value to mask in the logged gRPC
+        requests and responses. This flag may be specified multiple times.
+        A rule is a dot-separated field path, ex. "Parameters.chap_password",
+        a regular expression that matches map keys, ex. "~(?i)password", or
+        a field path followed by a regular expression, ex.
+        "PublishContext~token".`)
+}
+
 // flagWithSpecValidation adds the --with-spec-validation flag to the
 // specified flagset.
 func flagWithSpecValidation(fs *flag.FlagSet, addr *bool, def string) {
@@ -102,6 +149,18 @@ func flagWithSpecValidation(fs *flag.FlagSet, addr *bool, def string) {
         against the CSI specification.`)
 }
 
+// flagWithTracing adds the --with-tracing flag to the specified flagset.
+func flagWithTracing(fs *flag.FlagSet, addr *bool, def string) {
+	fs.BoolVar(
+		addr,
+		"with-tracing",
+		defBool(def),
+		`Enables OpenTelemetry tracing. A client span is created for each
+        gRPC request and its W3C trace context is sent to the server as
+        the "traceparent" gRPC metadata. The trace ID is logged at the
+        DEBUG log level.`)
+}
+
 // flagWithRequiresCreds adds the flag --with-requires-creds
 // to the provided flagset.
 func flagWithRequiresCreds(fs *flag.FlagSet, addr *bool, def string) {
@@ -43,3 +43,37 @@ func Test_flagWithSuccessNotFound(t *testing.T) {
 	// ensure the flag was added
 	assert.NotEqual(t, child.Flags().Lookup("with-success-not-found"), nil)
 }
+
+func Test_flagWithTracing(t *testing.T) {
+	child := createVolumeCmd
+	var withTracing bool
+
+	flagWithTracing(child.Flags(), &withTracing, "false")
+
+	// ensure the flag was added
+	assert.NotEqual(t, child.Flags().Lookup("with-tracing"), nil)
+}
+
+func Test_flagLogRedact(t *testing.T) {
+	child := createVolumeCmd
+	var logRedact []string
+
+	flagLogRedact(child.Flags(), &logRedact)
+
+	// ensure the flag was added
+	assert.NotEqual(t, child.Flags().Lookup("log-redact"), nil)
+}
+
+func Test_flagTLS(t *testing.T) {
+	child := createVolumeCmd
+	var cert, key, caCert string
+
+	flagCert(child.Flags(), &cert)
+	flagKey(child.Flags(), &key)
+	flagCACert(child.Flags(), &caCert)
+
+	// ensure the flags were added
+	assert.NotEqual(t, child.Flags().Lookup("cert"), nil)
+	assert.NotEqual(t, child.Flags().Lookup("key"), nil)
+	assert.NotEqual(t, child.Flags().Lookup("cacert"), nil)
+}
@@ -19,18 +19,32 @@
 package cmd
 
 import (
+	"context"
+
 	log "github.com/sirupsen/logrus"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"google.golang.org/grpc"
 
 	"github.com/dell/gocsi/middleware/logging"
 	"github.com/dell/gocsi/middleware/requestid"
 	"github.com/dell/gocsi/middleware/specvalidator"
+	"github.com/dell/gocsi/middleware/tracing"
 	"github.com/dell/gocsi/utils/middleware"
 )
 
 func getClientInterceptorsDialOpt() grpc.DialOption {
 	var iceptors []grpc.UnaryClientInterceptor
 
+	// Configure tracing. The SDK's tracer provider is used so that valid
+	// trace IDs are generated even though no spans are exported.
+	if root.withTracing {
+		iceptors = append(iceptors,
+			tracing.NewClientTracer(tracing.WithTracerProvider(
+				sdktrace.NewTracerProvider())),
+			logTraceID)
+		log.Debug("enabled tracing")
+	}
+
 	// Configure logging.
 	if root.withReqLogging || root.withRepLogging {
 
@@ -45,6 +59,12 @@ func getClientInterceptorsDialOpt() grpc.DialOption {
 			w           = newLogger(log.Infof)
 		)
 
+		if len(root.redactRules) > 0 {
+			loggingOpts = append(loggingOpts,
+				logging.WithRedactionRules(root.redactRules...))
+			log.Debug("enabled log redaction rules")
+		}
+
 		if root.withReqLogging {
 			loggingOpts = append(loggingOpts, logging.WithRequestLogging(w))
 			log.Debug("enabled request logging")
@@ -94,3 +114,19 @@ func getClientInterceptorsDialOpt() grpc.DialOption {
 
 	return nil
 }
+
+// logTraceID logs the trace ID of the outgoing request's span.
+func logTraceID(
+	ctx context.Context,
+	method string,
+	req, rep interface{},
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	log.WithFields(map[string]interface{}{
+		"method":  method,
+		"traceID": tracing.TraceID(ctx),
+	}).Debug("tracing request")
+	return invoker(ctx, method, req, rep, cc, opts...)
+}
@@ -21,6 +21,9 @@ package cmd
 import (
 	"bufio"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -29,10 +32,12 @@ import (
 	"text/template"
 	"time"
 
+	"github.com/dell/gocsi/middleware/logging"
 	utils "github.com/dell/gocsi/utils/csi"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
 )
@@ -50,11 +55,17 @@ var root struct {
 	format      string
 	endpoint    string
 	insecure    bool
+	certFile    string
+	keyFile     string
+	caCertFile  string
 	timeout     time.Duration
 	metadata    mapOfStringArg
 
 	withReqLogging bool
 	withRepLogging bool
+	withTracing    bool
+	logRedact      []string
+	redactRules    []logging.RedactionRule
 
 	withSpecValidator      bool
 	withRequiresCreds      bool
@@ -138,6 +149,16 @@ var RootCmd = &cobra.Command{
 		// Parse the credentials if they exist.
 		root.secrets = utils.ParseMap(os.Getenv("X_CSI_SECRETS"))
 
+		// Parse the log redaction rules.
+		root.redactRules = nil
+		for _, v := range root.logRedact {
+			r, err := logging.ParseRedactionRule(v)
+			if err != nil {
+				return err
+			}
+			root.redactRules = append(root.redactRules, r)
+		}
+
 		// Create the gRPC client connection.
 		opts := []grpc.DialOption{
 			grpc.WithContextDialer(
@@ -155,8 +176,16 @@ var RootCmd = &cobra.Command{
 				}),
 		}
 
-		// Disable TLS if specified.
-		if root.insecure {
+		// Enable TLS if a certificate or CA is specified, otherwise
+		// disable TLS if specified.
+		if root.certFile != "" || root.keyFile != "" || root.caCertFile != "" {
+			config, err := newClientTLSConfig()
+			if err != nil {
+				return err
+			}
+			opts = append(opts, grpc.WithTransportCredentials(
+				credentials.NewTLS(config)))
+		} else if root.insecure {
 			opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 		}
 
@@ -219,6 +248,15 @@ func init() {
 		&root.withRepLogging,
 		"false")
 
+	flagLogRedact(
+		RootCmd.PersistentFlags(),
+		&root.logRedact)
+
+	flagWithTracing(
+		RootCmd.PersistentFlags(),
+		&root.withTracing,
+		"false")
+
 	flagWithSpecValidation(
 		RootCmd.PersistentFlags(),
 		&root.withSpecValidator,
@@ -230,7 +268,20 @@ func init() {
 		"i",
 		true,
 		`Disables transport security for the client via the gRPC dial option
-        WithInsecure (https://goo.gl/Y95SfW)`)
+        WithInsecure (https://goo.gl/Y95SfW). This flag is ignored if
+        --cert, --key, or --cacert is specified`)
+
+	flagCert(
+		RootCmd.PersistentFlags(),
+		&root.certFile)
+
+	flagKey(
+		RootCmd.PersistentFlags(),
+		&root.keyFile)
+
+	flagCACert(
+		RootCmd.PersistentFlags(),
+		&root.caCertFile)
 
 	RootCmd.PersistentFlags().VarP(
 		&root.metadata,
@@ -267,3 +318,47 @@ func newLogger(f func(msg string, args ...interface{})) *logger {
 func (l *logger) Write(data []byte) (int, error) {
 	return l.w.Write(data)
 }
+
+// newClientTLSConfig returns the client's TLS configuration. The server's
+// certificate is verified with the CA specified by --cacert, or with the
+// host's root CAs if no CA is specified. The client's certificate is
+// specified by --cert and --key.
+func newClientTLSConfig() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if root.certFile != "" || root.keyFile != "" {
+		if root.certFile == "" || root.keyFile == "" {
+			return nil, errors.New("--cert and --key must be specified together")
+		}
+		cert, err := tls.LoadX509KeyPair(root.certFile, root.keyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if root.caCertFile != "" {
+		pem, err := os.ReadFile(root.caCertFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", root.caCertFile)
+		}
+	}
+
+	// The server's name is the endpoint's host.
+	proto, addr, err := utils.ParseProtoAddr(root.endpoint)
+	if err != nil {
+		return nil, err
+	}
+	config.ServerName = "localhost"
+	if proto == "tcp" {
+		if host, _, err := net.SplitHostPort(addr); err == nil && host != "" {
+			config.ServerName = host
+		}
+	}
+
+	return config, nil
+}
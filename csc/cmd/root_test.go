@@ -19,7 +19,16 @@
 package cmd
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -84,3 +93,54 @@ func TestSettingLogLevel(t *testing.T) {
 	// revert back to default
 	debug = false
 }
+
+func TestNewClientTLSConfig(t *testing.T) {
+	defer func() {
+		root.endpoint, root.certFile, root.keyFile, root.caCertFile = "", "", "", ""
+	}()
+
+	// Create a self-signed certificate to use as both the client's
+	// certificate and the CA.
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(certFile,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	assert.NoError(t, os.WriteFile(keyFile,
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	root.endpoint = "tcp://127.0.0.1:10000"
+	root.certFile, root.keyFile, root.caCertFile = certFile, keyFile, certFile
+	config, err := newClientTLSConfig()
+	assert.NoError(t, err)
+	assert.Len(t, config.Certificates, 1)
+	assert.NotNil(t, config.RootCAs)
+	assert.Equal(t, "127.0.0.1", config.ServerName)
+
+	root.endpoint = "unix:///tmp/csi.sock"
+	root.certFile, root.keyFile = "", ""
+	config, err = newClientTLSConfig()
+	assert.NoError(t, err)
+	assert.Empty(t, config.Certificates)
+	assert.Equal(t, "localhost", config.ServerName)
+
+	root.certFile = certFile
+	_, err = newClientTLSConfig()
+	assert.ErrorContains(t, err, "must be specified together")
+
+	root.certFile, root.caCertFile = "", keyFile
+	_, err = newClientTLSConfig()
+	assert.ErrorContains(t, err, "no certificates found")
+}
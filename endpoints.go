@@ -0,0 +1,237 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package gocsi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	csictx "github.com/dell/gocsi/context"
+)
+
+// serviceNames maps the names of the CSI services that may be specified
+// in X_CSI_ADDITIONAL_ENDPOINTS to the gRPC service names.
+var serviceNames = map[string]string{
+	"identity":        csi.Identity_ServiceDesc.ServiceName,
+	"controller":      csi.Controller_ServiceDesc.ServiceName,
+	"groupcontroller": csi.GroupController_ServiceDesc.ServiceName,
+	"node":            csi.Node_ServiceDesc.ServiceName,
+}
+
+// endpoint is an additional endpoint on which the storage plug-in is
+// served.
+type endpoint struct {
+	lis net.Listener
+
+	// services are the gRPC names of the services exposed by the
+	// endpoint. All the registered services are exposed if empty.
+	services []string
+
+	server *grpc.Server
+}
+
+// parseEndpoints parses a comma-separated list of endpoints. Each endpoint
+// may be followed by a semicolon-separated list of the services it
+// exposes, for example "tcp://127.0.0.1:10000;identity;node".
+func parseEndpoints(v string) ([]string, [][]string, error) {
+	var (
+		addrs    []string
+		services [][]string
+	)
+	for _, e := range strings.Split(v, ",") {
+		parts := strings.Split(strings.TrimSpace(e), ";")
+		if parts[0] == "" {
+			continue
+		}
+		var names []string
+		for _, s := range parts[1:] {
+			s = strings.ToLower(strings.TrimSpace(s))
+			if s == "" {
+				continue
+			}
+			name, ok := serviceNames[s]
+			if !ok {
+				return nil, nil, fmt.Errorf(
+					"invalid %s: %s: unknown service: %s",
+					EnvVarAdditionalEndpoints, parts[0], s)
+			}
+			names = append(names, name)
+		}
+		// The Identity service is always exposed.
+		if len(names) > 0 && !containsString(names, serviceNames["identity"]) {
+			names = append([]string{serviceNames["identity"]}, names...)
+		}
+		addrs = append(addrs, parts[0])
+		services = append(services, names)
+	}
+	return addrs, services, nil
+}
+
+// initEndpoints listens on the additional endpoints and adjusts their
+// file permissions and ownership.
+func (sp *StoragePlugin) initEndpoints(ctx context.Context) error {
+	v, ok := csictx.LookupEnv(ctx, EnvVarAdditionalEndpoints)
+	if !ok || v == "" {
+		return nil
+	}
+	addrs, services, err := parseEndpoints(v)
+	if err != nil {
+		return err
+	}
+
+	for i, addr := range addrs {
+		lis, _, err := listenCSIEndpoint(ctx, addr)
+		if err != nil {
+			sp.closeEndpoints()
+			return fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+		sp.endpoints = append(sp.endpoints, &endpoint{
+			lis:      lis,
+			services: services[i],
+		})
+		if err := sp.initEndpointPerms(ctx, lis); err != nil {
+			sp.closeEndpoints()
+			return err
+		}
+		if err := sp.initEndpointOwner(ctx, lis); err != nil {
+			sp.closeEndpoints()
+			return err
+		}
+	}
+	return nil
+}
+
+// closeEndpoints closes the listeners of the additional endpoints.
+func (sp *StoragePlugin) closeEndpoints() {
+	for _, e := range sp.endpoints {
+		_ = e.lis.Close()
+	}
+}
+
+// newServer returns a gRPC server for lis with the provided services
+// registered, along with the servers registered by
+// RegisterAdditionalServers. The services must be a subset of the
+// registered services.
+func (sp *StoragePlugin) newServer(
+	lis net.Listener, services []string,
+) *grpc.Server {
+	server := grpc.NewServer(sp.serverOpts(lis)...)
+	for _, name := range services {
+		switch name {
+		case csi.Identity_ServiceDesc.ServiceName:
+			csi.RegisterIdentityServer(server, sp.Identity)
+		case csi.Controller_ServiceDesc.ServiceName:
+			csi.RegisterControllerServer(server, sp.Controller)
+		case csi.GroupController_ServiceDesc.ServiceName:
+			csi.RegisterGroupControllerServer(server, sp.GroupController)
+		case csi.Node_ServiceDesc.ServiceName:
+			csi.RegisterNodeServer(server, sp.Node)
+		}
+	}
+	if sp.RegisterAdditionalServers != nil {
+		sp.RegisterAdditionalServers(server)
+	}
+	sp.registerHealth(server)
+	return server
+}
+
+// serveEndpoints serves the storage plug-in on the additional endpoints.
+// The endpoints that expose a subset of the services registered with the
+// primary server, or that differ from the primary listener lis in the
+// use of TLS, are served by their own gRPC server.
+func (sp *StoragePlugin) serveEndpoints(
+	lis net.Listener, registered []string,
+) error {
+	for _, e := range sp.endpoints {
+		if len(e.services) == 0 {
+			if sp.useTLS(e.lis) == sp.useTLS(lis) {
+				e.server = sp.server
+			} else {
+				e.server = sp.newServer(e.lis, registered)
+			}
+		} else {
+			for _, name := range e.services {
+				if !containsString(registered, name) {
+					return fmt.Errorf(
+						"invalid %s: %s: service not registered: %s",
+						EnvVarAdditionalEndpoints, e.lis.Addr(), name)
+				}
+			}
+			e.server = sp.newServer(e.lis, e.services)
+		}
+
+		log.WithFields(map[string]interface{}{
+			"endpoint": fmt.Sprintf(
+				"%s://%s", e.lis.Addr().Network(), e.lis.Addr().String()),
+			"services": e.services,
+		}).Info("serving")
+
+		go func(e *endpoint) {
+			if err := e.server.Serve(e.lis); err != nil {
+				log.WithError(err).WithField(
+					"endpoint", e.lis.Addr().String()).Error("grpc failed")
+			}
+		}(e)
+	}
+	return nil
+}
+
+// servers returns the gRPC servers of the storage plug-in.
+func (sp *StoragePlugin) servers() []*grpc.Server {
+	var servers []*grpc.Server
+	if sp.server != nil {
+		servers = append(servers, sp.server)
+	}
+	for _, e := range sp.endpoints {
+		if e.server != nil && e.server != sp.server {
+			servers = append(servers, e.server)
+		}
+	}
+	return servers
+}
+
+// gracefulStopServers stops the gRPC servers gracefully and returns when
+// all of them are stopped.
+func gracefulStopServers(servers []*grpc.Server) {
+	var wg sync.WaitGroup
+	for _, s := range servers {
+		wg.Add(1)
+		go func(s *grpc.Server) {
+			defer wg.Done()
+			s.GracefulStop()
+		}(s)
+	}
+	wg.Wait()
+}
+
+func containsString(a []string, s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
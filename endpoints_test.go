@@ -0,0 +1,183 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package gocsi
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/dell/gocsi/mock/service"
+)
+
+func TestParseEndpoints(t *testing.T) {
+	var (
+		identity = csi.Identity_ServiceDesc.ServiceName
+		node     = csi.Node_ServiceDesc.ServiceName
+	)
+	tests := []struct {
+		name         string
+		value        string
+		wantAddrs    []string
+		wantServices [][]string
+		wantErr      bool
+	}{
+		{
+			name:         "single",
+			value:        "tcp://127.0.0.1:10000",
+			wantAddrs:    []string{"tcp://127.0.0.1:10000"},
+			wantServices: [][]string{nil},
+		},
+		{
+			name:      "services",
+			value:     "unix:///tmp/csi.sock, tcp://127.0.0.1:10000;Node;",
+			wantAddrs: []string{"unix:///tmp/csi.sock", "tcp://127.0.0.1:10000"},
+			wantServices: [][]string{
+				nil,
+				{identity, node},
+			},
+		},
+		{
+			name:         "identity",
+			value:        "tcp://127.0.0.1:10000;node;identity",
+			wantAddrs:    []string{"tcp://127.0.0.1:10000"},
+			wantServices: [][]string{{node, identity}},
+		},
+		{
+			name:    "unknown service",
+			value:   "tcp://127.0.0.1:10000;admin",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addrs, services, err := parseEndpoints(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantAddrs, addrs)
+			assert.Equal(t, tt.wantServices, services)
+		})
+	}
+}
+
+func TestServeAdditionalEndpoints(t *testing.T) {
+	sockFile := filepath.Join(t.TempDir(), "csi.sock")
+	tcpLis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	tcpAddr := tcpLis.Addr().String()
+	assert.NoError(t, tcpLis.Close())
+
+	svc := service.NewServer()
+	sp := &StoragePlugin{
+		Controller: svc,
+		Identity:   svc,
+		Node:       svc,
+		EnvVars: []string{
+			EnvVarEndpointPerms + "=0700",
+			EnvVarAdditionalEndpoints + "=unix://" + sockFile +
+				",tcp://" + tcpAddr + ";node",
+		},
+		RegisterAdditionalServers: func(s *grpc.Server) {
+			h := health.NewServer()
+			h.SetServingStatus("driver", healthpb.HealthCheckResponse_SERVING)
+			healthpb.RegisterHealthServer(s, h)
+		},
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() {
+		_ = sp.Serve(context.Background(), lis)
+	}()
+
+	dial := func(addr string) *grpc.ClientConn {
+		conn, err := grpc.NewClient(addr,
+			grpc.WithTransportCredentials(insecure.NewCredentials()))
+		assert.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+		return conn
+	}
+	listVolumes := func(conn *grpc.ClientConn) error {
+		_, err := csi.NewControllerClient(conn).ListVolumes(
+			context.Background(), &csi.ListVolumesRequest{})
+		return err
+	}
+
+	// The UNIX socket exposes all the services.
+	sock := dial("unix://" + sockFile)
+	assert.Eventually(t, func() bool {
+		return listVolumes(sock) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	fi, err := os.Stat(sockFile)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o700), fi.Mode().Perm())
+
+	// The TCP endpoint exposes the Identity and Node services.
+	tcp := dial(tcpAddr)
+	_, err = csi.NewIdentityClient(tcp).GetPluginInfo(
+		context.Background(), &csi.GetPluginInfoRequest{})
+	assert.NoError(t, err)
+	_, err = csi.NewNodeClient(tcp).NodeGetInfo(
+		context.Background(), &csi.NodeGetInfoRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, codes.Unimplemented, status.Code(listVolumes(tcp)))
+
+	// The TCP endpoint exposes the additional servers too.
+	rep, err := healthpb.NewHealthClient(tcp).Check(context.Background(),
+		&healthpb.HealthCheckRequest{Service: "driver"})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, rep.GetStatus())
+
+	sp.GracefulStop(context.Background())
+	_, err = os.Stat(sockFile)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestServeAdditionalEndpointsInvalid(t *testing.T) {
+	svc := service.NewServer()
+	sp := &StoragePlugin{
+		Identity: svc,
+		Node:     svc,
+		EnvVars: []string{
+			EnvVarMode + "=node",
+			EnvVarAdditionalEndpoints + "=tcp://127.0.0.1:0;controller",
+		},
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer lis.Close()
+	assert.Error(t, sp.Serve(context.Background(), lis))
+	assert.Len(t, sp.endpoints, 1)
+}
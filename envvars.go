@@ -55,6 +55,73 @@ const (
 	// the process.
 	EnvVarEndpointGroup = "X_CSI_ENDPOINT_GROUP"
 
+	// EnvVarEndpointRemoveStale is the name of the environment variable
+	// used to determine whether or not a UNIX socket file left at the
+	// endpoint's path by a process that exited is removed at startup.
+	// The file is never removed if a process is listening on it. The
+	// default value is true.
+	EnvVarEndpointRemoveStale = "X_CSI_ENDPOINT_REMOVE_STALE"
+
+	// EnvVarAdditionalEndpoints is the name of the environment variable
+	// used to specify a comma-separated list of endpoints on which the
+	// SP is served in addition to CSI_ENDPOINT. Each endpoint may be
+	// followed by a semicolon-separated list of the services it exposes,
+	// for example "tcp://127.0.0.1:10000;identity;node". The permissions
+	// and ownership of UNIX socket files are set as they are for
+	// CSI_ENDPOINT.
+	EnvVarAdditionalEndpoints = "X_CSI_ADDITIONAL_ENDPOINTS"
+
+	// EnvVarTLSCert is the name of the environment variable used to
+	// specify the path to the PEM-encoded certificate the server uses
+	// to serve the CSI endpoint with TLS. The endpoint is served with
+	// TLS only if both this value and X_CSI_TLS_KEY are set.
+	EnvVarTLSCert = "X_CSI_TLS_CERT"
+
+	// EnvVarTLSKey is the name of the environment variable used to
+	// specify the path to the PEM-encoded private key of the server's
+	// TLS certificate.
+	EnvVarTLSKey = "X_CSI_TLS_KEY"
+
+	// EnvVarTLSClientCA is the name of the environment variable used to
+	// specify the path to a PEM-encoded bundle of CA certificates. When
+	// set, clients must present a certificate signed by one of the CAs.
+	EnvVarTLSClientCA = "X_CSI_TLS_CLIENT_CA"
+
+	// EnvVarHealthDisabled is the name of the environment variable used
+	// to disable the gRPC health service registered by the server.
+	EnvVarHealthDisabled = "X_CSI_HEALTH_DISABLED"
+
+	// EnvVarHealthProbeInterval is the name of the environment variable
+	// used to specify how often the gRPC health service calls the
+	// Identity service's Probe RPC to determine the serving status of
+	// the CSI services. The default value is 10s.
+	EnvVarHealthProbeInterval = "X_CSI_HEALTH_PROBE_INTERVAL"
+
+	// EnvVarHealthProbeFailureThreshold is the name of the environment
+	// variable used to specify the number of consecutive failed probes
+	// after which the gRPC health service reports the CSI services as
+	// not serving. The default value is 3.
+	EnvVarHealthProbeFailureThreshold = "X_CSI_HEALTH_PROBE_FAILURE_THRESHOLD"
+
+	// EnvVarShutdownTimeout is the name of the environment variable used
+	// to specify the time allowed for the RPCs in flight to finish when
+	// the server is stopped gracefully. The server is stopped forcibly
+	// when the timeout expires. By default there is no timeout.
+	EnvVarShutdownTimeout = "X_CSI_SHUTDOWN_TIMEOUT"
+
+	// EnvVarConfigFile is the name of the environment variable used to
+	// specify the path to a YAML or JSON file whose keys are the names
+	// of the environment variables defined by this package or declared
+	// in StoragePlugin.EnvVars. The values in the file have the lowest
+	// precedence; they are overridden by the StoragePlugin.EnvVars
+	// defaults, which are in turn overridden by the environment.
+	EnvVarConfigFile = "X_CSI_CONFIG_FILE"
+
+	// EnvVarConfigPrint is the name of the environment variable used to
+	// determine whether or not to print the effective configuration, the
+	// resolved values of all the known environment variables, at startup.
+	EnvVarConfigPrint = "X_CSI_CONFIG_PRINT"
+
 	// EnvVarDebug is the name of the environment variable used to
 	// determine whether or not debug mode is enabled.
 	//
@@ -119,6 +186,46 @@ const (
 	// of the VolumeContext field
 	EnvVarLoggingDisableVolCtx = "X_CSI_LOG_DISABLE_VOL_CTX"
 
+	// EnvVarLogFormat is the name of the environment variable used to
+	// specify the format of the request and response logs. Valid
+	// values are "text", the default, and "json".
+	EnvVarLogFormat = "X_CSI_LOG_FORMAT"
+
+	// EnvVarLogRedact is the name of the environment variable used to
+	// specify a whitespace-separated list of redaction rules. The values
+	// described by the rules are masked in the request and response logs.
+	EnvVarLogRedact = "X_CSI_LOG_REDACT"
+
+	// EnvVarMetrics is the name of the environment variable used to
+	// determine whether or not to enable the metrics interceptor, which
+	// records Prometheus metrics for every RPC.
+	EnvVarMetrics = "X_CSI_METRICS"
+
+	// EnvVarMetricsAddr is the name of the environment variable used to
+	// specify the TCP address, ex. ":9090", on which the Prometheus
+	// metrics are served over HTTP at the path /metrics. The metrics are
+	// not served if this value is unset.
+	EnvVarMetricsAddr = "X_CSI_METRICS_ADDR"
+
+	// EnvVarAdminAddr is the name of the environment variable used to
+	// specify the TCP address, ex. "127.0.0.1:9091", on which the admin
+	// and debug endpoints are served over HTTP. The endpoints are not
+	// served if this value is unset.
+	EnvVarAdminAddr = "X_CSI_ADMIN_ADDR"
+
+	// EnvVarTracing is the name of the environment variable used to
+	// determine whether or not to enable the tracing interceptor, which
+	// creates an OpenTelemetry span for every RPC using the global
+	// tracer provider.
+	EnvVarTracing = "X_CSI_TRACING"
+
+	// EnvVarTracingPropagators is the name of the environment variable
+	// used to specify a comma-separated list of the propagators used to
+	// extract trace context from incoming gRPC metadata. Valid values
+	// include "tracecontext" and "baggage". The default value is
+	// "tracecontext".
+	EnvVarTracingPropagators = "X_CSI_TRACING_PROPAGATORS"
+
 	// EnvVarReqIDInjection is the name of the environment variable
 	// used to determine whether or not to enable request ID injection.
 	EnvVarReqIDInjection = "X_CSI_REQ_ID_INJECTION"
@@ -205,6 +312,126 @@ const (
 	/* #nosec G101 */
 	EnvVarCredsNodePubVol = "X_CSI_REQUIRE_CREDS_NODE_PUB_VOL"
 
+	// EnvVarIdempotency is the name of the environment variable used to
+	// determine whether or not to enable the idempotency middleware, which
+	// deduplicates RPCs that are in flight or recently completed.
+	EnvVarIdempotency = "X_CSI_IDEMPOTENCY"
+
+	// EnvVarIdempotencyTTL is the name of the environment variable that
+	// defines the length of time for which the response of a completed
+	// RPC is cached by the idempotency middleware.
+	EnvVarIdempotencyTTL = "X_CSI_IDEMPOTENCY_TTL"
+
+	// EnvVarIdempotencyEtcd is the name of the environment variable used
+	// to determine whether or not the idempotency middleware records RPCs
+	// in etcd. The etcd client is configured by the serial volume access
+	// etcd environment variables, ex. X_CSI_SERIAL_VOL_ACCESS_ETCD_ENDPOINTS.
+	EnvVarIdempotencyEtcd = "X_CSI_IDEMPOTENCY_ETCD"
+
+	// EnvVarIdempotencyEtcdDomain is the name of the environment variable
+	// that defines the etcd key prefix used by the idempotency middleware.
+	EnvVarIdempotencyEtcdDomain = "X_CSI_IDEMPOTENCY_ETCD_DOMAIN"
+
+	// EnvVarIdempotencyEtcdTTL is the name of the environment variable
+	// that defines the length of time etcd will wait before releasing an
+	// in-flight RPC if the lease of the RPC's owner has not been renewed.
+	EnvVarIdempotencyEtcdTTL = "X_CSI_IDEMPOTENCY_ETCD_TTL"
+
+	// EnvVarLeaderElection is the name of the environment variable used
+	// to determine whether or not the replicas of a controller elect a
+	// leader that alone handles the mutating Controller RPCs.
+	EnvVarLeaderElection = "X_CSI_LEADER_ELECTION"
+
+	// EnvVarLeaderElectionIdentity is the name of the environment
+	// variable that defines the identity with which the storage plug-in
+	// campaigns for leadership: the gRPC target at which the other
+	// replicas reach it. The default value is the host name.
+	EnvVarLeaderElectionIdentity = "X_CSI_LEADER_ELECTION_IDENTITY"
+
+	// EnvVarLeaderElectionForward is the name of the environment variable
+	// used to determine whether or not a replica that is not the leader
+	// forwards the mutating Controller RPCs to the leader instead of
+	// rejecting them with codes.Unavailable.
+	EnvVarLeaderElectionForward = "X_CSI_LEADER_ELECTION_FORWARD"
+
+	// EnvVarLeaderElectionForwardToken is the name of the environment
+	// variable that defines the token shared by the replicas to mark the
+	// RPCs they forward to the leader. It is required to forward RPCs.
+	/* #nosec G101 */
+	EnvVarLeaderElectionForwardToken = "X_CSI_LEADER_ELECTION_FORWARD_TOKEN"
+
+	// EnvVarLeaderElectionEtcdDomain is the name of the environment
+	// variable that defines the etcd key prefix under which the leader is
+	// elected.
+	EnvVarLeaderElectionEtcdDomain = "X_CSI_LEADER_ELECTION_ETCD_DOMAIN"
+
+	// EnvVarLeaderElectionEtcdTTL is the name of the environment variable
+	// that defines the length of time etcd will wait before electing a new
+	// leader if the lease of the leader has not been renewed.
+	EnvVarLeaderElectionEtcdTTL = "X_CSI_LEADER_ELECTION_ETCD_TTL"
+
+	// EnvVarRPCTimeout is the name of the environment variable used to
+	// specify the timeout of the RPCs that have no deadline.
+	EnvVarRPCTimeout = "X_CSI_RPC_TIMEOUT"
+
+	// EnvVarRPCTimeoutMax is the name of the environment variable used
+	// to specify the maximum timeout of the RPCs. The deadline of an RPC
+	// that expires later is shortened.
+	EnvVarRPCTimeoutMax = "X_CSI_RPC_TIMEOUT_MAX"
+
+	// EnvVarRPCTimeoutPerMethod is the name of the environment variable
+	// used to specify the timeouts of the RPCs of a method that have no
+	// deadline, ex. "CreateVolume=5m,DeleteVolume=2m".
+	EnvVarRPCTimeoutPerMethod = "X_CSI_RPC_TIMEOUT_PER_METHOD"
+
+	// EnvVarRPCTimeoutMaxPerMethod is the name of the environment variable
+	// used to specify the maximum timeouts of the RPCs of a method, ex.
+	// "CreateVolume=10m,DeleteVolume=5m".
+	EnvVarRPCTimeoutMaxPerMethod = "X_CSI_RPC_TIMEOUT_MAX_PER_METHOD"
+
+	// EnvVarDisableRecovery is the name of the environment variable used
+	// to determine whether or not to disable the recovery middleware,
+	// which converts the panics that occur while handling RPCs into
+	// gRPC errors with codes.Internal.
+	EnvVarDisableRecovery = "X_CSI_DISABLE_RECOVERY"
+
+	// EnvVarConcurrencyMaxInFlight is the name of the environment
+	// variable used to specify the maximum number of Controller,
+	// GroupController, and Node RPCs handled at the same time. The
+	// concurrency middleware is enabled if this or
+	// X_CSI_CONCURRENCY_MAX_IN_FLIGHT_PER_METHOD is set.
+	EnvVarConcurrencyMaxInFlight = "X_CSI_CONCURRENCY_MAX_IN_FLIGHT"
+
+	// EnvVarConcurrencyMaxInFlightPerMethod is the name of the environment
+	// variable used to specify the maximum number of RPCs of a method
+	// handled at the same time, ex. "CreateVolume=10,DeleteVolume=10".
+	EnvVarConcurrencyMaxInFlightPerMethod = "X_CSI_CONCURRENCY_MAX_IN_FLIGHT_PER_METHOD"
+
+	// EnvVarConcurrencyMaxQueue is the name of the environment variable
+	// used to specify the maximum number of RPCs that wait for the RPCs
+	// in flight to complete. The default value, 0, rejects the RPCs that
+	// exceed a limit immediately.
+	EnvVarConcurrencyMaxQueue = "X_CSI_CONCURRENCY_MAX_QUEUE"
+
+	// EnvVarConcurrencyRejectCode is the name of the environment variable
+	// used to specify the gRPC status code of rejected RPCs, either
+	// ResourceExhausted or Aborted. The default value is ResourceExhausted.
+	EnvVarConcurrencyRejectCode = "X_CSI_CONCURRENCY_REJECT_CODE"
+
+	// EnvVarCapabilityEnforcement is the name of the environment variable
+	// used to specify how the RPCs that require a capability the storage
+	// plug-in does not advertise are handled: "reject" rejects them with
+	// codes.Unimplemented and "warn" logs them. They are not checked by
+	// default.
+	EnvVarCapabilityEnforcement = "X_CSI_CAPABILITY_ENFORCEMENT"
+
+	// EnvVarSelfCheck is the name of the environment variable used to
+	// enable the self-check of the registered services at startup. A
+	// value of "fail" refuses to start when a problem is found and a
+	// value of "warn" logs the problems. The self-check is disabled by
+	// default.
+	EnvVarSelfCheck = "X_CSI_SELF_CHECK"
+
 	// EnvVarSerialVolAccess is the name of the environment variable
 	// used to determine whether or not to enable serial volume access.
 	EnvVarSerialVolAccess = "X_CSI_SERIAL_VOL_ACCESS"
@@ -213,6 +440,11 @@ const (
 	// used to specify the timeout for obtaining a volume lock.
 	EnvVarSerialVolAccessTimeout = "X_CSI_SERIAL_VOL_ACCESS_TIMEOUT"
 
+	// EnvVarSerialVolAccessRPCs is the name of the environment variable
+	// that defines a comma-separated list of the names of the RPCs that
+	// are serialized by the serial volume access middleware.
+	EnvVarSerialVolAccessRPCs = "X_CSI_SERIAL_VOL_ACCESS_RPCS"
+
 	// EnvVarSerialVolAccessEtcdDomain is the name of the environment
 	// variable that defines the lock provider's concurrency domain.
 	EnvVarSerialVolAccessEtcdDomain = "X_CSI_SERIAL_VOL_ACCESS_ETCD_DOMAIN"
@@ -320,6 +552,40 @@ func (sp *StoragePlugin) initEnvVars(ctx context.Context) {
 		sp.envVars[key] = val
 	}
 
+	sp.initDebug(ctx)
+}
+
+// withEnvVarDefaults returns a context in which the default values of
+// the environment variables in the EnvVars of sp are used for the keys
+// not otherwise set, so the settings read before Serve initializes the
+// storage plug-in's environment honor the same defaults.
+func withEnvVarDefaults(
+	ctx context.Context, sp StoragePluginProvider,
+) context.Context {
+	p, ok := sp.(*StoragePlugin)
+	if !ok || len(p.EnvVars) == 0 {
+		return ctx
+	}
+	defaults := map[string]string{}
+	for _, v := range p.EnvVars {
+		pair := strings.SplitN(v, "=", 2)
+		if len(pair) > 1 {
+			defaults[strings.ToUpper(pair[0])] = pair[1]
+		}
+	}
+	parent := ctx
+	return csictx.WithLookupEnv(ctx, func(key string) (string, bool) {
+		if v, ok := csictx.LookupEnv(parent, key); ok {
+			return v, true
+		}
+		v, ok := defaults[key]
+		return v, ok
+	})
+}
+
+// initDebug enables request and response logging if debug mode is
+// enabled.
+func (sp *StoragePlugin) initDebug(ctx context.Context) {
 	// Check for the debug value.
 	if v, ok := csictx.LookupEnv(ctx, EnvVarDebug); ok {
 		/* #nosec G104 */
@@ -25,6 +25,7 @@ package gocsi
 import (
 	"bufio"
 	"context"
+	"crypto/tls"
 	"errors"
 	"flag"
 	"fmt"
@@ -39,12 +40,14 @@ import (
 	"sync"
 	"syscall"
 	"text/template"
+	"time"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 
 	csictx "github.com/dell/gocsi/context"
+	"github.com/dell/gocsi/middleware/leaderelection/election"
 	utils "github.com/dell/gocsi/utils/csi"
 	"github.com/dell/gocsi/utils/middleware"
 )
@@ -59,6 +62,14 @@ func Run(
 	appName, appDescription, appUsage string,
 	sp StoragePluginProvider,
 ) {
+	// Read the settings below from the config file if they are not
+	// otherwise set.
+	ctx, err := withConfigFile(ctx)
+	if err != nil {
+		log.WithError(err).Info("failed to read config file")
+		osExit(1)
+	}
+
 	// Check for the debug value.
 	if v, ok := csictx.LookupEnv(ctx, EnvVarDebug); ok {
 		/* #nosec G104 */
@@ -70,14 +81,7 @@ func Run(
 	}
 
 	// Adjust the log level.
-	lvl := log.InfoLevel
-	if v, ok := csictx.LookupEnv(ctx, EnvVarLogLevel); ok {
-		var err error
-		if lvl, err = log.ParseLevel(v); err != nil {
-			lvl = log.InfoLevel
-		}
-	}
-	log.SetLevel(lvl)
+	setLogLevel(ctx)
 
 	printUsage := func() {
 		// app is the information passed to the printUsage function
@@ -107,30 +111,32 @@ func Run(
 	fs.Usage = printUsage
 	var help bool
 	fs.BoolVar(&help, "?", false, "")
-	err := fs.Parse(os.Args)
+	err = fs.Parse(os.Args)
 	if err == flag.ErrHelp || help {
 		printUsage()
 		osExit(1)
 	}
 
 	// If no endpoint is set then print the usage.
-	if os.Getenv(EnvVarEndpoint) == "" {
+	endpoint := csictx.Getenv(ctx, EnvVarEndpoint)
+	if endpoint == "" {
 		printUsage()
 		osExit(1)
 	}
 
-	l, err := utils.GetCSIEndpointListener()
+	l, inherited, err := listenCSIEndpoint(ctx, endpoint)
 	if err != nil {
 		log.WithError(err).Info("failed to listen")
 		osExit(1)
 	}
 
 	// Define a lambda that can be used in the exit handler
-	// to remove a potential UNIX sock file.
+	// to remove a potential UNIX sock file. An inherited sock file
+	// is kept so it may be passed to the next instance of the process.
 	var rmSockFileOnce sync.Once
 	rmSockFile := func() {
 		rmSockFileOnce.Do(func() {
-			if l == nil || l.Addr() == nil {
+			if inherited || l == nil || l.Addr() == nil {
 				return
 			}
 			/* #nosec G104 */
@@ -142,19 +148,73 @@ func Run(
 		})
 	}
 
+	// Serve the Prometheus metrics and the admin endpoints if their
+	// addresses are configured. The addresses may be among the storage
+	// plug-in's default EnvVars.
+	envCtx := withEnvVarDefaults(ctx, sp)
+	metricsSrv, err := serveMetrics(envCtx)
+	if err != nil {
+		rmSockFile()
+		log.WithError(err).Info("failed to serve metrics")
+		osExit(1)
+	}
+
+	adminSrv, err := serveAdmin(envCtx, sp)
+	if err != nil {
+		if metricsSrv != nil {
+			_ = metricsSrv.Close()
+		}
+		rmSockFile()
+		log.WithError(err).Info("failed to serve admin endpoints")
+		osExit(1)
+	}
+	closeMetricsSrv := func() {
+		if metricsSrv != nil {
+			_ = metricsSrv.Close()
+		}
+		if adminSrv != nil {
+			_ = adminSrv.Close()
+		}
+	}
+
 	trapSignals(func() {
 		sp.GracefulStop(ctx)
+		closeMetricsSrv()
 		rmSockFile()
 		log.Info("server stopped gracefully")
-	})
+	}, reloadFunc(ctx, sp))
 
 	if err := sp.Serve(ctx, l); err != nil {
+		closeMetricsSrv()
 		rmSockFile()
 		log.WithError(err).Info("grpc failed")
 		osExit(1)
 	}
 }
 
+// setLogLevel sets the log level specified by X_CSI_LOG_LEVEL. The
+// default log level is INFO.
+func setLogLevel(ctx context.Context) {
+	lvl := log.InfoLevel
+	if v, ok := csictx.LookupEnv(ctx, EnvVarLogLevel); ok {
+		var err error
+		if lvl, err = log.ParseLevel(v); err != nil {
+			lvl = log.InfoLevel
+		}
+	}
+	log.SetLevel(lvl)
+}
+
+// listenCSIEndpoint returns the net.Listener for the provided endpoint,
+// and whether or not the listener is inherited. A stale UNIX socket file
+// at the endpoint is removed unless X_CSI_ENDPOINT_REMOVE_STALE is false.
+func listenCSIEndpoint(
+	ctx context.Context, endpoint string,
+) (net.Listener, bool, error) {
+	v, _ := csictx.LookupEnv(ctx, EnvVarEndpointRemoveStale)
+	return utils.ListenCSIEndpointRemoveStale(endpoint, v)
+}
+
 // StoragePluginProvider is able to serve a gRPC endpoint that provides
 // the CSI services: Controller, Identity, Node.
 type StoragePluginProvider interface {
@@ -206,6 +266,14 @@ type StoragePlugin struct {
 	// based on runtime configuration settings.
 	Interceptors []grpc.UnaryServerInterceptor
 
+	// StreamInterceptors is a list of gRPC stream, server interceptors
+	// to use when serving the SP. Only the services registered with
+	// RegisterAdditionalServers may define streaming RPCs, as the CSI
+	// services are entirely unary. This list should not include the
+	// interceptors defined in the GoCSI package as those are configured
+	// by default based on runtime configuration settings.
+	StreamInterceptors []grpc.StreamServerInterceptor
+
 	// BeforeServe is an optional callback that is invoked after the
 	// StoragePlugin has been initialized, just prior to the creation
 	// of the gRPC server. This callback may be used to perform custom
@@ -213,19 +281,62 @@ type StoragePlugin struct {
 	// or prevent the server from starting by returning a non-nil error.
 	BeforeServe func(context.Context, *StoragePlugin, net.Listener) error
 
+	// BeforeStop is an optional callback that is invoked when the
+	// StoragePlugin begins to stop, after it is reported as not ready
+	// and before the RPCs in flight are drained. This callback may be
+	// used to flush state. The context is done when the shutdown
+	// timeout expires. A non-nil error is logged.
+	BeforeStop func(context.Context, *StoragePlugin) error
+
+	// IsPanicFatal is an optional callback that is invoked when a panic
+	// occurs while handling an RPC. The panic crashes the process if the
+	// callback returns true. Otherwise the RPC fails with codes.Internal.
+	// This callback is not invoked if X_CSI_DISABLE_RECOVERY is true.
+	IsPanicFatal func(ctx context.Context, method string, p interface{}) bool
+
+	// LeaderElector is an optional elector used when X_CSI_LEADER_ELECTION
+	// is true. The leader is elected in etcd if LeaderElector is nil.
+	LeaderElector election.Elector
+
+	// LeaderDialOpts is a list of gRPC dial options used to connect to the
+	// leader when X_CSI_LEADER_ELECTION_FORWARD is true. The connection is
+	// insecure if the list is empty.
+	LeaderDialOpts []grpc.DialOption
+
 	// EnvVars is a list of default environment variables and values.
 	EnvVars []string
 
 	// RegisterAdditionalServers allows the driver to register additional
 	// grpc servers on the same grpc connection. These can be used
-	// for proprietary extensions.
+	// for proprietary extensions. It is invoked for each gRPC server,
+	// including those of the additional endpoints that expose a subset
+	// of the CSI services.
 	RegisterAdditionalServers func(*grpc.Server)
 
 	serveOnce sync.Once
 	stopOnce  sync.Once
 	server    *grpc.Server
+	endpoints []*endpoint
+	health    *healthChecker
 
+	shutdownTimeout time.Duration
+	resignLeader    func()
+	tlsConfig       *tls.Config
+
+	// envVarsL guards envVars, configVars, and setVars, which may be
+	// replaced by Reload.
+	envVarsL   sync.RWMutex
 	envVars    map[string]string
+	configVars map[string]string
+
+	// setVars are the values set with csictx.Setenv after the storage
+	// plug-in's environment was initialized. They are kept by Reload.
+	setVars map[string]string
+	envInit bool
+
+	reloadL    sync.Mutex
+	chain      *interceptorChain
+	shared     sharedInterceptors
 	pluginInfo *csi.GetPluginInfoResponse
 }
 
@@ -253,6 +364,17 @@ func (sp *StoragePlugin) Serve(ctx context.Context, lis net.Listener) error {
 		// Initialize the storage plug-in's environment variables map.
 		sp.initEnvVars(ctx)
 
+		// Load the config file beneath the environment variables.
+		if err = sp.initConfig(ctx); err != nil {
+			return
+		}
+		sp.envInit = true
+
+		// Read the time allowed to stop gracefully.
+		if err = sp.initShutdown(ctx); err != nil {
+			return
+		}
+
 		// Adjust the endpoint's file permissions.
 		if err = sp.initEndpointPerms(ctx, lis); err != nil {
 			return
@@ -263,11 +385,32 @@ func (sp *StoragePlugin) Serve(ctx context.Context, lis net.Listener) error {
 			return
 		}
 
+		// Listen on the additional endpoints.
+		if err = sp.initEndpoints(ctx); err != nil {
+			return
+		}
+		defer func() {
+			if err != nil {
+				sp.closeEndpoints()
+				sp.resign()
+			}
+		}()
+
 		// Initialize the storage plug-in's info.
 		sp.initPluginInfo(ctx)
 
 		// Initialize the interceptors.
-		sp.initInterceptors(ctx)
+		sp.reloadL.Lock()
+		err = sp.initInterceptors(ctx)
+		sp.reloadL.Unlock()
+		if err != nil {
+			return
+		}
+
+		// Initialize the server's transport security.
+		if err = sp.initTLS(ctx); err != nil {
+			return
+		}
 
 		// Invoke the SP's BeforeServe function to give the SP a chance
 		// to perform any local initialization routines.
@@ -282,9 +425,13 @@ func (sp *StoragePlugin) Serve(ctx context.Context, lis net.Listener) error {
 			sp.ServerOpts = append(sp.ServerOpts,
 				grpc.UnaryInterceptor(middleware.ChainUnaryServer(i...)))
 		}
+		if i := sp.StreamInterceptors; len(i) > 0 {
+			sp.ServerOpts = append(sp.ServerOpts,
+				grpc.StreamInterceptor(middleware.ChainStreamServer(i...)))
+		}
 
 		// Initialize the gRPC server.
-		sp.server = grpc.NewServer(sp.ServerOpts...)
+		sp.server = grpc.NewServer(sp.serverOpts(lis)...)
 
 		// Register the CSI services.
 		// Always require the identity service.
@@ -302,6 +449,7 @@ func (sp *StoragePlugin) Serve(ctx context.Context, lis net.Listener) error {
 		// Always register the identity service.
 		csi.RegisterIdentityServer(sp.server, sp.Identity)
 		log.Info("identity service registered")
+		services := []string{csi.Identity_ServiceDesc.ServiceName}
 
 		// Determine which of the controller/node services to register
 		mode := csictx.Getenv(ctx, EnvVarMode)
@@ -320,10 +468,16 @@ func (sp *StoragePlugin) Serve(ctx context.Context, lis net.Listener) error {
 			}
 			csi.RegisterControllerServer(sp.server, sp.Controller)
 			log.Info("controller service registered")
+			services = append(services, csi.Controller_ServiceDesc.ServiceName)
+
+			// Campaign for the leadership of the controller replicas.
+			sp.campaign(ctx)
 
 			if sp.GroupController != nil {
 				csi.RegisterGroupControllerServer(sp.server, sp.GroupController)
 				log.Info("group controller service registered")
+				services = append(services,
+					csi.GroupController_ServiceDesc.ServiceName)
 			}
 		}
 		if mode == "" || mode == "node" {
@@ -333,6 +487,17 @@ func (sp *StoragePlugin) Serve(ctx context.Context, lis net.Listener) error {
 			}
 			csi.RegisterNodeServer(sp.server, sp.Node)
 			log.Info("node service registered")
+			services = append(services, csi.Node_ServiceDesc.ServiceName)
+		}
+
+		// Query the capabilities advertised by the registered services.
+		if err = sp.initCapabilities(ctx, services); err != nil {
+			return
+		}
+
+		// Check the registered services.
+		if err = sp.selfCheck(ctx, services); err != nil {
+			return
 		}
 
 		// Register any additional servers required.
@@ -340,6 +505,21 @@ func (sp *StoragePlugin) Serve(ctx context.Context, lis net.Listener) error {
 			sp.RegisterAdditionalServers(sp.server)
 		}
 
+		// Register the gRPC health service. The serving status of the
+		// CSI services is derived from the Identity service's Probe RPC.
+		if err = sp.initHealth(ctx, services); err != nil {
+			return
+		}
+		if sp.registerHealth(sp.server) {
+			go sp.health.run(ctx)
+			log.Info("health service registered")
+		}
+
+		// Serve the additional endpoints.
+		if err = sp.serveEndpoints(lis, services); err != nil {
+			return
+		}
+
 		endpoint := fmt.Sprintf(
 			"%s://%s",
 			lis.Addr().Network(), lis.Addr().String())
@@ -356,23 +536,29 @@ func (sp *StoragePlugin) Serve(ctx context.Context, lis net.Listener) error {
 // It cancels all active RPCs on the server side and the corresponding
 // pending RPCs on the client side will get notified by connection
 // errors.
-func (sp *StoragePlugin) Stop(_ context.Context) {
+func (sp *StoragePlugin) Stop(ctx context.Context) {
 	sp.stopOnce.Do(func() {
-		if sp.server != nil {
-			sp.server.Stop()
+		_, cancel := sp.beginShutdown(ctx)
+		defer cancel()
+		for _, s := range sp.servers() {
+			s.Stop()
 		}
+		sp.resign()
 		log.Info("stopped")
 	})
 }
 
 // GracefulStop stops the gRPC server gracefully. It stops the server
 // from accepting new connections and RPCs and blocks until all the
-// pending RPCs are finished.
-func (sp *StoragePlugin) GracefulStop(_ context.Context) {
+// pending RPCs are finished. If X_CSI_SHUTDOWN_TIMEOUT is set, or the
+// context is done, before the pending RPCs finish, the server is stopped
+// forcibly.
+func (sp *StoragePlugin) GracefulStop(ctx context.Context) {
 	sp.stopOnce.Do(func() {
-		if sp.server != nil {
-			sp.server.GracefulStop()
-		}
+		ctx, cancel := sp.beginShutdown(ctx)
+		defer cancel()
+		sp.drain(ctx)
+		sp.resign()
 		log.Info("gracefully stopped")
 	})
 }
@@ -497,12 +683,25 @@ func (sp *StoragePlugin) initEndpointOwner(
 }
 
 func (sp *StoragePlugin) lookupEnv(key string) (string, bool) {
-	val, ok := sp.envVars[key]
+	sp.envVarsL.RLock()
+	defer sp.envVarsL.RUnlock()
+	if val, ok := sp.envVars[key]; ok {
+		return val, true
+	}
+	val, ok := sp.configVars[key]
 	return val, ok
 }
 
 func (sp *StoragePlugin) setenv(key, val string) error {
+	sp.envVarsL.Lock()
+	defer sp.envVarsL.Unlock()
 	sp.envVars[key] = val
+	if sp.envInit {
+		if sp.setVars == nil {
+			sp.setVars = map[string]string{}
+		}
+		sp.setVars[key] = val
+	}
 	return nil
 }
 
@@ -517,7 +716,23 @@ func (sp *StoragePlugin) getEnvBool(ctx context.Context, key string) bool {
 	return false
 }
 
-func trapSignals(onExit func()) {
+// reloadFunc returns a function that reloads the configuration of sp if
+// sp is a Reloader, otherwise nil.
+func reloadFunc(ctx context.Context, sp StoragePluginProvider) func() {
+	r, ok := sp.(Reloader)
+	if !ok {
+		return nil
+	}
+	return func() {
+		if err := r.Reload(ctx); err != nil {
+			log.WithError(err).Error("failed to reload configuration")
+		}
+	}
+}
+
+// trapSignals invokes onExit and exits the process when a signal to stop
+// is received. SIGHUP invokes onReload instead if it is not nil.
+func trapSignals(onExit, onReload func()) {
 	sigc := make(chan os.Signal, 1)
 	sigs := []os.Signal{
 		syscall.SIGTERM,
@@ -528,6 +743,11 @@ func trapSignals(onExit func()) {
 	signal.Notify(sigc, sigs...)
 	go func() {
 		for s := range sigc {
+			if s == syscall.SIGHUP && onReload != nil {
+				log.WithField("signal", s).Info("received signal; reloading")
+				onReload()
+				continue
+			}
 			log.WithField("signal", s).Info("received signal; shutting down")
 			if onExit != nil {
 				onExit()
@@ -32,11 +32,16 @@ import (
 	"testing"
 	"time"
 
+	csictx "github.com/dell/gocsi/context"
 	"github.com/dell/gocsi/mock/service"
+	utils "github.com/dell/gocsi/utils/csi"
+	"github.com/dell/gocsi/utils/middleware"
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestRun(t *testing.T) {
@@ -680,3 +685,481 @@ func TestStoragePlugin_Serve(t *testing.T) {
 		})
 	}
 }
+
+func TestInitInterceptorsStream(t *testing.T) {
+	svc := service.NewServer()
+	sp := newMockStoragePlugin(svc, nil, svc, svc)
+	sp.EnvVars = []string{
+		EnvVarReqLogging + "=true",
+		EnvVarRepLogging + "=true",
+	}
+
+	ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+	sp.initEnvVars(ctx)
+	assert.NoError(t, sp.initInterceptors(ctx))
+
+	// the recovery interceptor and the reloadable chain of the context
+	// injector, request ID injector, logger, and in-flight RPC tracker
+	assert.Len(t, sp.StreamInterceptors, 2)
+	_, stream, _, err := sp.newInterceptors(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, stream, 4)
+
+	ss := &testServerStream{ctx: context.Background()}
+	err = middleware.ChainStreamServer(sp.StreamInterceptors...)(
+		nil, ss, &grpc.StreamServerInfo{FullMethod: "/ext.Service/Watch"},
+		func(_ interface{}, stream grpc.ServerStream) error {
+			_, ok := csictx.GetRequestID(stream.Context())
+			assert.True(t, ok)
+			v, ok := csictx.LookupEnv(stream.Context(), EnvVarReqLogging)
+			assert.True(t, ok)
+			assert.Equal(t, "true", v)
+			return nil
+		})
+	assert.NoError(t, err)
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestServeMetrics(t *testing.T) {
+	ctx := context.Background()
+
+	defer os.Unsetenv(EnvVarMetricsAddr)
+
+	// No address disables the metrics server.
+	srv, err := serveMetrics(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, srv)
+
+	os.Setenv(EnvVarMetricsAddr, "127.0.0.1:0")
+	srv, err = serveMetrics(ctx)
+	assert.NoError(t, err)
+	assert.NotNil(t, srv)
+	assert.NoError(t, srv.Close())
+
+	os.Setenv(EnvVarMetricsAddr, "invalid:address:")
+	_, err = serveMetrics(ctx)
+	assert.Error(t, err)
+}
+
+func TestServeMetricsEnvVarDefault(t *testing.T) {
+	sp := &StoragePlugin{EnvVars: []string{EnvVarMetricsAddr + "=127.0.0.1:0"}}
+	srv, err := serveMetrics(withEnvVarDefaults(context.Background(), sp))
+	assert.NoError(t, err)
+	if assert.NotNil(t, srv) {
+		assert.NoError(t, srv.Close())
+	}
+
+	// The environment takes precedence over the default.
+	t.Setenv(EnvVarMetricsAddr, "")
+	srv, err = serveMetrics(withEnvVarDefaults(context.Background(), sp))
+	assert.NoError(t, err)
+	assert.Nil(t, srv)
+}
+
+func TestNewTracingPropagator(t *testing.T) {
+	tests := []struct {
+		names  string
+		fields []string
+	}{
+		{"", []string{"traceparent", "tracestate"}},
+		{"tracecontext", []string{"traceparent", "tracestate"}},
+		{"baggage", []string{"baggage"}},
+		{"TraceContext, baggage", []string{"traceparent", "tracestate", "baggage"}},
+		{"unknown", []string{"traceparent", "tracestate"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.names, func(t *testing.T) {
+			assert.ElementsMatch(t, tt.fields, newTracingPropagator(tt.names).Fields())
+		})
+	}
+}
+
+func TestInitInterceptorsIdempotency(t *testing.T) {
+	svc := service.NewServer()
+	sp := newMockStoragePlugin(svc, nil, svc, svc)
+	sp.EnvVars = []string{
+		EnvVarIdempotency + "=true",
+		EnvVarIdempotencyTTL + "=10s",
+	}
+	t.Setenv(EnvVarReqLogging, "false")
+	t.Setenv(EnvVarRepLogging, "false")
+
+	ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+	sp.initEnvVars(ctx)
+	assert.NoError(t, sp.initInterceptors(ctx))
+
+	// the recovery interceptor and the reloadable chain of the context
+	// injector, in-flight RPC tracker, and idempotency
+	assert.Len(t, sp.Interceptors, 2)
+	unary, _, _, err := sp.newInterceptors(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, unary, 3)
+
+	calls := 0
+	handler := func(_ context.Context, _ interface{}) (interface{}, error) {
+		calls++
+		return &csi.DeleteVolumeResponse{}, nil
+	}
+	for i := 0; i < 2; i++ {
+		_, err := middleware.ChainUnaryServer(sp.Interceptors...)(
+			context.Background(),
+			&csi.DeleteVolumeRequest{VolumeId: "vol-1"},
+			&grpc.UnaryServerInfo{FullMethod: "/csi.v1.Controller/DeleteVolume"},
+			handler)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestListenCSIEndpointStale(t *testing.T) {
+	sockFile := t.TempDir() + "/csi.sock"
+	endpoint := "unix://" + sockFile
+	newStaleSockFile := func() {
+		lis, err := net.Listen("unix", sockFile)
+		assert.NoError(t, err)
+		lis.(*net.UnixListener).SetUnlinkOnClose(false)
+		assert.NoError(t, lis.Close())
+	}
+	ctx := context.Background()
+
+	// The stale sock file is not removed if disabled.
+	newStaleSockFile()
+	t.Setenv(EnvVarEndpointRemoveStale, "false")
+	_, _, err := listenCSIEndpoint(ctx, endpoint)
+	assert.Error(t, err)
+
+	// The stale sock file is removed by default.
+	t.Setenv(EnvVarEndpointRemoveStale, "")
+	lis, inherited, err := listenCSIEndpoint(ctx, endpoint)
+	assert.NoError(t, err)
+	assert.False(t, inherited)
+	defer lis.Close()
+
+	// The sock file of a live process is not removed.
+	_, _, err = listenCSIEndpoint(ctx, endpoint)
+	assert.ErrorIs(t, err, utils.ErrSocketInUse)
+}
+
+func TestNewConcurrencyLimiter(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     []string
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "disabled", wantNil: true},
+		{name: "queue only", env: []string{EnvVarConcurrencyMaxQueue + "=10"}, wantNil: true},
+		{name: "max in flight", env: []string{EnvVarConcurrencyMaxInFlight + "=10"}},
+		{
+			name: "per method",
+			env: []string{
+				EnvVarConcurrencyMaxInFlightPerMethod + "=CreateVolume=5, DeleteVolume=5",
+				EnvVarConcurrencyMaxQueue + "=10",
+				EnvVarConcurrencyRejectCode + "=aborted",
+			},
+		},
+		{name: "invalid max", env: []string{EnvVarConcurrencyMaxInFlight + "=x"}, wantErr: true},
+		{name: "invalid method", env: []string{EnvVarConcurrencyMaxInFlightPerMethod + "=CreateVolume=0"}, wantErr: true},
+		{
+			name: "invalid code",
+			env: []string{
+				EnvVarConcurrencyMaxInFlight + "=10",
+				EnvVarConcurrencyRejectCode + "=Internal",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sp := &StoragePlugin{EnvVars: tt.env}
+			ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+			sp.initEnvVars(ctx)
+			l, err := newConcurrencyLimiter(ctx, false)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantNil, l == nil)
+		})
+	}
+}
+
+func TestInitIdempotencyEtcd(t *testing.T) {
+	// The etcd store is only used if it is enabled, regardless of the
+	// serial volume access etcd configuration.
+	sp := &StoragePlugin{EnvVars: []string{
+		EnvVarIdempotency + "=true",
+		EnvVarSerialVolAccessEtcdEndpoints + "=127.0.0.1:2379",
+		EnvVarSerialVolAccessEtcdAutoSyncInterval + "=soon",
+	}}
+	ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+	sp.initEnvVars(ctx)
+	assert.NoError(t, sp.initSharedInterceptors(ctx))
+	assert.NotNil(t, sp.shared.idempotency)
+}
+
+func TestServeInvalidInterceptors(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       []string
+		expectErr string
+	}{
+		{
+			name:      "concurrency",
+			env:       []string{EnvVarConcurrencyMaxInFlight + "=x"},
+			expectErr: EnvVarConcurrencyMaxInFlight,
+		},
+		{
+			name:      "timeout",
+			env:       []string{EnvVarRPCTimeout + "=soon"},
+			expectErr: EnvVarRPCTimeout,
+		},
+		{
+			name:      "capabilities",
+			env:       []string{EnvVarCapabilityEnforcement + "=ignore"},
+			expectErr: EnvVarCapabilityEnforcement,
+		},
+		{
+			name: "leader election",
+			env:       []string{EnvVarLeaderElection + "=true"},
+			expectErr: EnvVarSerialVolAccessEtcdEndpoints,
+		},
+		{
+			name: "idempotency etcd",
+			env: []string{
+				EnvVarIdempotency + "=true",
+				EnvVarIdempotencyEtcd + "=true",
+				EnvVarSerialVolAccessEtcdAutoSyncInterval + "=soon",
+			},
+			expectErr: "invalid duration",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := service.NewServer()
+			sp := newMockStoragePlugin(svc, nil, svc, svc)
+			sp.EnvVars = tt.env
+
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			assert.NoError(t, err)
+			defer lis.Close()
+			assert.ErrorContains(t, sp.Serve(context.Background(), lis),
+				tt.expectErr)
+		})
+	}
+}
+
+func TestInitInterceptorsConcurrency(t *testing.T) {
+	t.Setenv(EnvVarReqLogging, "false")
+	t.Setenv(EnvVarRepLogging, "false")
+	sp := &StoragePlugin{EnvVars: []string{
+		EnvVarConcurrencyMaxInFlightPerMethod + "=CreateVolume=1",
+	}}
+	ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+	sp.initEnvVars(ctx)
+	assert.NoError(t, sp.initInterceptors(ctx))
+
+	// context injector, in-flight RPC tracker, concurrency limiter
+	unary, _, _, err := sp.newInterceptors(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, unary, 3)
+
+	_, err = middleware.ChainUnaryServer(sp.Interceptors...)(
+		context.Background(),
+		&csi.CreateVolumeRequest{Name: "vol"},
+		&grpc.UnaryServerInfo{FullMethod: "/csi.v1.Controller/CreateVolume"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			assert.Equal(t, 1, sp.shared.limiter.InFlight())
+			_, err := middleware.ChainUnaryServer(sp.Interceptors...)(
+				ctx, req,
+				&grpc.UnaryServerInfo{FullMethod: "/csi.v1.Controller/CreateVolume"},
+				func(context.Context, interface{}) (interface{}, error) {
+					return &csi.CreateVolumeResponse{}, nil
+				})
+			assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+			return &csi.CreateVolumeResponse{}, nil
+		})
+	assert.NoError(t, err)
+}
+
+func TestNewCapabilityEnforcer(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     []string
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "disabled", wantNil: true},
+		{name: "reject", env: []string{EnvVarCapabilityEnforcement + "=reject"}},
+		{name: "warn", env: []string{EnvVarCapabilityEnforcement + "=Warn"}},
+		{name: "invalid", env: []string{EnvVarCapabilityEnforcement + "=true"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sp := &StoragePlugin{EnvVars: tt.env}
+			ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+			sp.initEnvVars(ctx)
+			e, err := newCapabilityEnforcer(ctx)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantNil, e == nil)
+		})
+	}
+}
+
+func TestInitCapabilities(t *testing.T) {
+	t.Setenv(EnvVarReqLogging, "false")
+	t.Setenv(EnvVarRepLogging, "false")
+	svc := service.NewServer()
+	sp := &StoragePlugin{
+		Identity:   svc,
+		Controller: svc,
+		Node:       svc,
+		EnvVars:    []string{EnvVarCapabilityEnforcement + "=reject"},
+	}
+	ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+	sp.initEnvVars(ctx)
+	assert.NoError(t, sp.initInterceptors(ctx))
+
+	call := func(method string) error {
+		_, err := middleware.ChainUnaryServer(sp.Interceptors...)(
+			context.Background(), nil,
+			&grpc.UnaryServerInfo{FullMethod: method},
+			func(context.Context, interface{}) (interface{}, error) {
+				return nil, nil
+			})
+		return err
+	}
+
+	// The controller service does not advertise LIST_SNAPSHOTS, and the
+	// node service is not registered.
+	assert.NoError(t, sp.initCapabilities(ctx, []string{
+		csi.Identity_ServiceDesc.ServiceName,
+		csi.Controller_ServiceDesc.ServiceName,
+	}))
+	assert.NoError(t, call("/csi.v1.Controller/ListVolumes"))
+	assert.Equal(t, codes.Unimplemented,
+		status.Code(call("/csi.v1.Controller/ListSnapshots")))
+	assert.NoError(t, call("/csi.v1.Node/NodeGetVolumeStats"))
+}
+
+func TestInitInterceptorsRecovery(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     []string
+		fatal   bool
+		wantLen int
+	}{
+		{name: "default", wantLen: 3},
+		{name: "fatal", fatal: true, wantLen: 3},
+		{name: "disabled", env: []string{EnvVarDisableRecovery + "=true"}, wantLen: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The storage plug-in's interceptor panics.
+			sp := &StoragePlugin{
+				EnvVars: tt.env,
+				Interceptors: []grpc.UnaryServerInterceptor{
+					func(context.Context, interface{}, *grpc.UnaryServerInfo, grpc.UnaryHandler) (interface{}, error) {
+						panic("nil pointer")
+					},
+				},
+				IsPanicFatal: func(context.Context, string, interface{}) bool {
+					return tt.fatal
+				},
+			}
+			ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+			sp.initEnvVars(ctx)
+			assert.NoError(t, sp.initInterceptors(ctx))
+			assert.Len(t, sp.Interceptors, tt.wantLen)
+			assert.Len(t, sp.StreamInterceptors, tt.wantLen-1)
+			if tt.wantLen == 2 {
+				assert.Equal(t, []string{"custom", "context"}, sp.chain.names().Unary[:2])
+				return
+			}
+			assert.Equal(t, []string{"recovery", "custom", "context"}, sp.chain.names().Unary[:3])
+
+			call := func() error {
+				_, err := middleware.ChainUnaryServer(sp.Interceptors...)(
+					context.Background(),
+					&csi.DeleteVolumeRequest{VolumeId: "vol-1"},
+					&grpc.UnaryServerInfo{FullMethod: "/csi.v1.Controller/DeleteVolume"},
+					func(context.Context, interface{}) (interface{}, error) {
+						return nil, nil
+					})
+				return err
+			}
+			if tt.fatal {
+				assert.Panics(t, func() { _ = call() })
+				return
+			}
+			assert.Equal(t, codes.Internal, status.Code(call()))
+		})
+	}
+}
+
+func TestNewTimeoutInterceptor(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       []string
+		wantNil   bool
+		expectErr bool
+	}{
+		{name: "disabled", wantNil: true},
+		{name: "invalid", env: []string{EnvVarRPCTimeout + "=soon"}, expectErr: true},
+		{name: "negative max", env: []string{EnvVarRPCTimeoutMax + "=-1h"}, expectErr: true},
+		{name: "invalid per method", env: []string{EnvVarRPCTimeoutPerMethod + "=CreateVolume=0"}, expectErr: true},
+		{name: "invalid max per method", env: []string{EnvVarRPCTimeoutMaxPerMethod + "=CreateVolume=soon"}, expectErr: true},
+		{name: "default", env: []string{EnvVarRPCTimeout + "=1m"}},
+		{name: "max", env: []string{EnvVarRPCTimeoutMax + "=1h"}},
+		{name: "per method", env: []string{EnvVarRPCTimeoutPerMethod + "=CreateVolume=5m"}},
+		{name: "max per method", env: []string{EnvVarRPCTimeoutMaxPerMethod + "=CreateVolume=10m"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sp := &StoragePlugin{EnvVars: tt.env}
+			ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+			sp.initEnvVars(ctx)
+			i, err := newTimeoutInterceptor(ctx)
+			if tt.expectErr {
+				assert.ErrorContains(t, err, "invalid")
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantNil, i == nil)
+		})
+	}
+}
+
+func TestInitInterceptorsTimeout(t *testing.T) {
+	t.Setenv(EnvVarReqLogging, "false")
+	t.Setenv(EnvVarRepLogging, "false")
+	sp := &StoragePlugin{EnvVars: []string{
+		EnvVarRPCTimeoutPerMethod + "=DeleteVolume=10ms",
+	}}
+	ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+	sp.initEnvVars(ctx)
+	assert.NoError(t, sp.initInterceptors(ctx))
+
+	_, err := middleware.ChainUnaryServer(sp.Interceptors...)(
+		context.Background(),
+		&csi.DeleteVolumeRequest{VolumeId: "vol-1"},
+		&grpc.UnaryServerInfo{FullMethod: "/csi.v1.Controller/DeleteVolume"},
+		func(ctx context.Context, _ interface{}) (interface{}, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
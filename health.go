@@ -0,0 +1,184 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package gocsi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	csictx "github.com/dell/gocsi/context"
+)
+
+const (
+	defaultHealthProbeInterval         = 10 * time.Second
+	defaultHealthProbeFailureThreshold = 3
+)
+
+// initHealth creates the health checker that derives the serving status
+// of the provided services from the Identity service's Probe RPC. No
+// health checker is created if X_CSI_HEALTH_DISABLED is true.
+func (sp *StoragePlugin) initHealth(
+	ctx context.Context, services []string,
+) error {
+	if sp.getEnvBool(ctx, EnvVarHealthDisabled) {
+		log.Info("health service disabled")
+		return nil
+	}
+
+	interval := defaultHealthProbeInterval
+	if v, ok := csictx.LookupEnv(ctx, EnvVarHealthProbeInterval); ok && v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", EnvVarHealthProbeInterval, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("invalid %s: %v", EnvVarHealthProbeInterval, v)
+		}
+		interval = d
+	}
+
+	threshold := defaultHealthProbeFailureThreshold
+	if v, ok := csictx.LookupEnv(ctx, EnvVarHealthProbeFailureThreshold); ok && v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 1 {
+			return fmt.Errorf(
+				"invalid %s: %v", EnvVarHealthProbeFailureThreshold, v)
+		}
+		threshold = i
+	}
+
+	sp.health = &healthChecker{
+		server:    health.NewServer(),
+		identity:  sp.Identity,
+		services:  services,
+		interval:  interval,
+		threshold: threshold,
+		stop:      make(chan struct{}),
+	}
+	sp.health.setStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+	return nil
+}
+
+// registerHealth registers the health service with the server unless the
+// health service is disabled or the server already has a health service,
+// for example one registered by RegisterAdditionalServers. It returns
+// whether the health service was registered.
+func (sp *StoragePlugin) registerHealth(server *grpc.Server) bool {
+	if sp.health == nil {
+		return false
+	}
+	name := healthpb.Health_ServiceDesc.ServiceName
+	if _, ok := server.GetServiceInfo()[name]; ok {
+		log.WithField("service", name).Info(
+			"health service already registered; skipping")
+		return false
+	}
+	healthpb.RegisterHealthServer(server, sp.health.server)
+	return true
+}
+
+// healthChecker maintains the serving status reported by the gRPC health
+// service. The services are NOT_SERVING until the first successful probe
+// and after threshold consecutive failed probes.
+type healthChecker struct {
+	server    *health.Server
+	identity  csi.IdentityServer
+	services  []string
+	interval  time.Duration
+	threshold int
+
+	failures int
+	serving  bool
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// run probes the Identity service at the configured interval until the
+// health checker is shut down.
+func (h *healthChecker) run(ctx context.Context) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		h.probe(ctx)
+		select {
+		case <-ticker.C:
+		case <-h.stop:
+			return
+		}
+	}
+}
+
+// probe calls the Identity service's Probe RPC and updates the serving
+// status. A probe fails if it returns an error or reports that the
+// plug-in is not ready.
+func (h *healthChecker) probe(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, h.interval)
+	defer cancel()
+
+	rep, err := h.identity.Probe(ctx, &csi.ProbeRequest{})
+	if err == nil && rep.GetReady() != nil && !rep.GetReady().GetValue() {
+		err = errors.New("plug-in is not ready")
+	}
+
+	if err == nil {
+		h.failures = 0
+		if !h.serving {
+			log.Info("health: probe succeeded; serving")
+			h.serving = true
+			h.setStatus(healthpb.HealthCheckResponse_SERVING)
+		}
+		return
+	}
+
+	h.failures++
+	log.WithError(err).WithField("failures", h.failures).Warn("health: probe failed")
+	if h.serving && h.failures >= h.threshold {
+		log.Warn("health: failure threshold reached; not serving")
+		h.serving = false
+		h.setStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+}
+
+// setStatus sets the serving status of the services and of the server
+// as a whole, which is the empty service name.
+func (h *healthChecker) setStatus(status healthpb.HealthCheckResponse_ServingStatus) {
+	h.server.SetServingStatus("", status)
+	for _, svc := range h.services {
+		h.server.SetServingStatus(svc, status)
+	}
+}
+
+// shutdown stops the probes and reports all services as NOT_SERVING.
+func (h *healthChecker) shutdown() {
+	h.stopOnce.Do(func() {
+		close(h.stop)
+		h.server.Shutdown()
+	})
+}
@@ -0,0 +1,262 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package gocsi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	csictx "github.com/dell/gocsi/context"
+	"github.com/dell/gocsi/mock/service"
+)
+
+// probeIdentity is an Identity service whose Probe RPC returns the
+// configured result.
+type probeIdentity struct {
+	csi.UnimplementedIdentityServer
+	ready *wrapperspb.BoolValue
+	err   error
+}
+
+func (p *probeIdentity) Probe(
+	_ context.Context, _ *csi.ProbeRequest,
+) (*csi.ProbeResponse, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &csi.ProbeResponse{Ready: p.ready}, nil
+}
+
+func TestInitHealth(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       []string
+		interval  time.Duration
+		threshold int
+		expectErr string
+	}{
+		{
+			name:      "defaults",
+			interval:  defaultHealthProbeInterval,
+			threshold: defaultHealthProbeFailureThreshold,
+		},
+		{
+			name: "configured",
+			env: []string{
+				EnvVarHealthProbeInterval + "=1s",
+				EnvVarHealthProbeFailureThreshold + "=5",
+			},
+			interval:  time.Second,
+			threshold: 5,
+		},
+		{
+			name:      "invalid interval",
+			env:       []string{EnvVarHealthProbeInterval + "=soon"},
+			expectErr: EnvVarHealthProbeInterval,
+		},
+		{
+			name:      "zero interval",
+			env:       []string{EnvVarHealthProbeInterval + "=0s"},
+			expectErr: EnvVarHealthProbeInterval,
+		},
+		{
+			name:      "invalid threshold",
+			env:       []string{EnvVarHealthProbeFailureThreshold + "=0"},
+			expectErr: EnvVarHealthProbeFailureThreshold,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sp := &StoragePlugin{EnvVars: tt.env}
+			ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+			sp.initEnvVars(ctx)
+
+			err := sp.initHealth(ctx, nil)
+			if tt.expectErr != "" {
+				assert.ErrorContains(t, err, tt.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.interval, sp.health.interval)
+			assert.Equal(t, tt.threshold, sp.health.threshold)
+		})
+	}
+}
+
+func TestHealthCheckerProbe(t *testing.T) {
+	ctx := context.Background()
+	identity := &probeIdentity{}
+	sp := &StoragePlugin{
+		Identity: identity,
+		EnvVars:  []string{EnvVarHealthProbeFailureThreshold + "=2"},
+	}
+	lctx := csictx.WithLookupEnv(ctx, sp.lookupEnv)
+	sp.initEnvVars(lctx)
+	svc := csi.Node_ServiceDesc.ServiceName
+	assert.NoError(t, sp.initHealth(lctx, []string{svc}))
+	h := sp.health
+
+	check := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		rep, err := h.server.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		assert.NoError(t, err)
+		return rep.GetStatus()
+	}
+
+	// The services are not serving until the first successful probe.
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(svc))
+	h.probe(ctx)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(svc))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(""))
+
+	// Services not registered with the server are unknown.
+	_, err := h.server.Check(ctx, &healthpb.HealthCheckRequest{
+		Service: csi.Controller_ServiceDesc.ServiceName,
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// The services stop serving once the failure threshold is reached.
+	identity.err = errors.New("probe failed")
+	h.probe(ctx)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(svc))
+	identity.err = nil
+	identity.ready = wrapperspb.Bool(false)
+	h.probe(ctx)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(svc))
+
+	// A single successful probe restores the services.
+	identity.ready = wrapperspb.Bool(true)
+	h.probe(ctx)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(svc))
+
+	h.shutdown()
+	h.shutdown()
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(svc))
+}
+
+func TestServeHealth(t *testing.T) {
+	svc := service.NewServer()
+	sp := newMockStoragePlugin(svc, nil, svc, svc)
+	sp.EnvVars = []string{
+		EnvVarMode + "=node",
+		EnvVarHealthProbeInterval + "=10ms",
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() {
+		_ = sp.Serve(context.Background(), lis)
+	}()
+	defer sp.Stop(context.Background())
+
+	conn, err := grpc.NewClient(lis.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	check := func(service string) (healthpb.HealthCheckResponse_ServingStatus, error) {
+		rep, err := client.Check(context.Background(),
+			&healthpb.HealthCheckRequest{Service: service})
+		return rep.GetStatus(), err
+	}
+
+	assert.Eventually(t, func() bool {
+		s, err := check(csi.Node_ServiceDesc.ServiceName)
+		return err == nil && s == healthpb.HealthCheckResponse_SERVING
+	}, 5*time.Second, 10*time.Millisecond)
+
+	s, err := check(csi.Identity_ServiceDesc.ServiceName)
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, s)
+
+	// The controller service is not registered in node mode.
+	_, err = check(csi.Controller_ServiceDesc.ServiceName)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestServeHealthNotRegistered(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        []string
+		register   func(*grpc.Server)
+		wantStatus healthpb.HealthCheckResponse_ServingStatus
+		wantCode   codes.Code
+	}{
+		{
+			name:     "disabled",
+			env:      []string{EnvVarHealthDisabled + "=true"},
+			wantCode: codes.Unimplemented,
+		},
+		{
+			name: "registered by the driver",
+			register: func(s *grpc.Server) {
+				h := health.NewServer()
+				h.SetServingStatus("driver", healthpb.HealthCheckResponse_SERVING)
+				healthpb.RegisterHealthServer(s, h)
+			},
+			wantStatus: healthpb.HealthCheckResponse_SERVING,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := service.NewServer()
+			sp := newMockStoragePlugin(svc, nil, svc, svc)
+			sp.EnvVars = append([]string{EnvVarMode + "=node"}, tt.env...)
+			sp.RegisterAdditionalServers = tt.register
+
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			assert.NoError(t, err)
+			go func() {
+				_ = sp.Serve(context.Background(), lis)
+			}()
+			defer sp.Stop(context.Background())
+
+			conn, err := grpc.NewClient(lis.Addr().String(),
+				grpc.WithTransportCredentials(insecure.NewCredentials()))
+			assert.NoError(t, err)
+			defer conn.Close()
+
+			// Wait for the server to be ready.
+			assert.Eventually(t, func() bool {
+				_, err := csi.NewIdentityClient(conn).Probe(
+					context.Background(), &csi.ProbeRequest{})
+				return err == nil
+			}, 5*time.Second, 10*time.Millisecond)
+
+			rep, err := healthpb.NewHealthClient(conn).Check(
+				context.Background(),
+				&healthpb.HealthCheckRequest{Service: "driver"})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.wantStatus, rep.GetStatus())
+		})
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package gocsi
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	csictx "github.com/dell/gocsi/context"
+	"github.com/dell/gocsi/middleware/leaderelection"
+	"github.com/dell/gocsi/middleware/leaderelection/election"
+	leaderetcd "github.com/dell/gocsi/middleware/leaderelection/etcd"
+)
+
+// newLeaderElection returns the elector and the interceptor configured
+// by the environment. The storage plug-in's LeaderElector is used if it
+// is set, otherwise the leader is elected in etcd.
+func (sp *StoragePlugin) newLeaderElection(
+	ctx context.Context,
+) (election.Elector, grpc.UnaryServerInterceptor, error) {
+	e := sp.LeaderElector
+	if e == nil {
+		if csictx.Getenv(ctx, EnvVarSerialVolAccessEtcdEndpoints) == "" {
+			return nil, nil, fmt.Errorf(
+				"%s requires a LeaderElector or %s",
+				EnvVarLeaderElection, EnvVarSerialVolAccessEtcdEndpoints)
+		}
+		var err error
+		if e, err = leaderetcd.New(ctx, "", 0, nil); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var opts []leaderelection.Option
+	if sp.getEnvBool(ctx, EnvVarLeaderElectionForward) {
+		if csictx.Getenv(ctx, EnvVarLeaderElectionIdentity) == "" {
+			return nil, nil, fmt.Errorf("%s requires %s",
+				EnvVarLeaderElectionForward, EnvVarLeaderElectionIdentity)
+		}
+		token := csictx.Getenv(ctx, EnvVarLeaderElectionForwardToken)
+		if token == "" {
+			return nil, nil, fmt.Errorf("%s requires %s",
+				EnvVarLeaderElectionForward, EnvVarLeaderElectionForwardToken)
+		}
+		opts = append(opts,
+			leaderelection.WithForwarding(sp.LeaderDialOpts...),
+			leaderelection.WithForwardingToken(token))
+	}
+
+	log.WithField("forward", len(opts) > 0).Debug("enabled leader election")
+	return e, leaderelection.New(e, opts...), nil
+}
+
+// campaign campaigns for leadership in the background until resign is
+// called.
+func (sp *StoragePlugin) campaign(ctx context.Context) {
+	e := sp.shared.elector
+	if e == nil {
+		return
+	}
+	id := csictx.Getenv(ctx, EnvVarLeaderElectionIdentity)
+	if id == "" {
+		id, _ = os.Hostname()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	sp.resignLeader = func() {
+		cancel()
+		<-done
+	}
+	go func() {
+		defer close(done)
+		log.WithField("id", id).Info("campaigning for leadership")
+		if err := e.Campaign(ctx, id); err != nil {
+			log.WithError(err).Error("leader election failed")
+		}
+	}()
+}
+
+// resign stops campaigning and resigns the leadership.
+func (sp *StoragePlugin) resign() {
+	if f := sp.resignLeader; f != nil {
+		f()
+	}
+}
@@ -0,0 +1,174 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package gocsi
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	csictx "github.com/dell/gocsi/context"
+	"github.com/dell/gocsi/utils/middleware"
+)
+
+// testElector is an elector that never elects a leader.
+type testElector struct {
+	mu sync.Mutex
+	id string
+}
+
+func (e *testElector) Campaign(ctx context.Context, id string) error {
+	e.mu.Lock()
+	e.id = id
+	e.mu.Unlock()
+	<-ctx.Done()
+	return nil
+}
+
+func (e *testElector) Leader() (string, bool) {
+	return "", false
+}
+
+func TestNewLeaderElection(t *testing.T) {
+	tests := []struct {
+		name    string
+		elector bool
+		env     []string
+		wantErr string
+	}{
+		{
+			name:    "no elector",
+			wantErr: "X_CSI_LEADER_ELECTION requires a LeaderElector or X_CSI_SERIAL_VOL_ACCESS_ETCD_ENDPOINTS",
+		},
+		{name: "elector", elector: true},
+		{
+			name:    "forward without identity",
+			elector: true,
+			env:     []string{EnvVarLeaderElectionForward + "=true"},
+			wantErr: "X_CSI_LEADER_ELECTION_FORWARD requires X_CSI_LEADER_ELECTION_IDENTITY",
+		},
+		{
+			name:    "forward without token",
+			elector: true,
+			env: []string{
+				EnvVarLeaderElectionForward + "=true",
+				EnvVarLeaderElectionIdentity + "=10.0.0.1:5000",
+			},
+			wantErr: "X_CSI_LEADER_ELECTION_FORWARD requires X_CSI_LEADER_ELECTION_FORWARD_TOKEN",
+		},
+		{
+			name:    "forward",
+			elector: true,
+			env: []string{
+				EnvVarLeaderElectionForward + "=true",
+				EnvVarLeaderElectionIdentity + "=10.0.0.1:5000",
+				EnvVarLeaderElectionForwardToken + "=secret",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sp := &StoragePlugin{EnvVars: tt.env}
+			if tt.elector {
+				sp.LeaderElector = &testElector{}
+			}
+			ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+			sp.initEnvVars(ctx)
+			e, i, err := sp.newLeaderElection(ctx)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, sp.LeaderElector, e)
+			assert.NotNil(t, i)
+		})
+	}
+}
+
+func TestInitInterceptorsLeaderElection(t *testing.T) {
+	t.Setenv(EnvVarReqLogging, "false")
+	t.Setenv(EnvVarRepLogging, "false")
+
+	call := func(sp *StoragePlugin) error {
+		_, err := middleware.ChainUnaryServer(sp.Interceptors...)(
+			context.Background(),
+			&csi.CreateVolumeRequest{Name: "vol"},
+			&grpc.UnaryServerInfo{FullMethod: "/csi.v1.Controller/CreateVolume"},
+			func(context.Context, interface{}) (interface{}, error) {
+				return &csi.CreateVolumeResponse{}, nil
+			})
+		return err
+	}
+
+	sp := &StoragePlugin{
+		LeaderElector: &testElector{},
+		EnvVars:       []string{EnvVarLeaderElection + "=true"},
+	}
+	ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+	sp.initEnvVars(ctx)
+	assert.NoError(t, sp.initInterceptors(ctx))
+	assert.Equal(t, codes.Unavailable, status.Code(call(sp)))
+
+	// Leader election does not apply to the node service.
+	sp = &StoragePlugin{
+		LeaderElector: &testElector{},
+		EnvVars: []string{
+			EnvVarLeaderElection + "=true",
+			EnvVarMode + "=node",
+		},
+	}
+	ctx = csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+	sp.initEnvVars(ctx)
+	assert.NoError(t, sp.initInterceptors(ctx))
+	assert.Nil(t, sp.shared.elector)
+	assert.NoError(t, call(sp))
+}
+
+func TestCampaign(t *testing.T) {
+	e := &testElector{}
+	sp := &StoragePlugin{
+		LeaderElector: e,
+		EnvVars: []string{
+			EnvVarLeaderElection + "=true",
+			EnvVarLeaderElectionIdentity + "=10.0.0.1:5000",
+		},
+	}
+	ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+	sp.initEnvVars(ctx)
+	assert.NoError(t, sp.initInterceptors(ctx))
+
+	sp.campaign(ctx)
+	assert.Eventually(t, func() bool {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		return e.id == "10.0.0.1:5000"
+	}, time.Second, time.Millisecond)
+
+	// Resigning waits for the campaign to return.
+	sp.resign()
+	sp.resign()
+}
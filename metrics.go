@@ -0,0 +1,76 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package gocsi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+
+	csictx "github.com/dell/gocsi/context"
+)
+
+// serveMetrics serves the Prometheus metrics on the address specified
+// by X_CSI_METRICS_ADDR. A nil server is returned if no address is set.
+func serveMetrics(ctx context.Context) (*http.Server, error) {
+	addr, ok := csictx.LookupEnv(ctx, EnvVarMetricsAddr)
+	if !ok || addr == "" {
+		return nil, nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	return serveHTTP("metrics", addr, mux)
+}
+
+// serveHTTP starts an HTTP server for the handler h on the TCP address
+// addr. The server runs in the background until it is closed.
+func serveHTTP(
+	name, addr string, h http.Handler,
+) (*http.Server, error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	srv := &http.Server{
+		Handler:           h,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	fields := map[string]interface{}{
+		"name": name,
+		"addr": lis.Addr().String(),
+	}
+
+	go func() {
+		if err := srv.Serve(lis); !errors.Is(err, http.ErrServerClosed) {
+			log.WithFields(fields).WithError(err).Error("http server failed")
+		}
+	}()
+
+	log.WithFields(fields).Info("serving http")
+	return srv, nil
+}
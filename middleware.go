@@ -20,30 +20,115 @@ package gocsi
 
 import (
 	"context"
+	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/propagation"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 
 	csictx "github.com/dell/gocsi/context"
+	"github.com/dell/gocsi/middleware/capabilities"
+	"github.com/dell/gocsi/middleware/concurrency"
+	"github.com/dell/gocsi/middleware/idempotency"
+	idempotencyetcd "github.com/dell/gocsi/middleware/idempotency/etcd"
 	"github.com/dell/gocsi/middleware/logging"
+	"github.com/dell/gocsi/middleware/metrics"
+	"github.com/dell/gocsi/middleware/recovery"
 	"github.com/dell/gocsi/middleware/requestid"
 	"github.com/dell/gocsi/middleware/serialvolume"
 	"github.com/dell/gocsi/middleware/serialvolume/etcd"
 	"github.com/dell/gocsi/middleware/specvalidator"
+	"github.com/dell/gocsi/middleware/timeout"
+	"github.com/dell/gocsi/middleware/tracing"
+	utils "github.com/dell/gocsi/utils/csi"
+	"github.com/dell/gocsi/utils/middleware"
 	"github.com/dell/gocsi/utils/rpcs"
 )
 
-func (sp *StoragePlugin) initInterceptors(ctx context.Context) {
-	sp.Interceptors = append(sp.Interceptors, sp.injectContext)
+// initInterceptors installs the recovery interceptor before the storage
+// plug-in's interceptors and appends the chain of the GoCSI interceptors
+// after them. The chain may be rebuilt with Reload while the server is
+// running. An error is returned if the interceptors are misconfigured.
+func (sp *StoragePlugin) initInterceptors(ctx context.Context) error {
+	// The interceptors that precede the chain are the recovery interceptor
+	// and the storage plug-in's interceptors, which are reported as custom
+	// interceptors.
+	var (
+		outer  interceptorNames
+		unary  []grpc.UnaryServerInterceptor
+		stream []grpc.StreamServerInterceptor
+	)
+
+	// The recovery interceptor precedes the others so it recovers from
+	// panics in all of the interceptors and handlers, including the
+	// storage plug-in's interceptors.
+	if !sp.getEnvBool(ctx, EnvVarDisableRecovery) {
+		opts := []recovery.Option{recovery.WithFatal(sp.IsPanicFatal)}
+		if sp.getEnvBool(ctx, EnvVarMetrics) {
+			opts = append(opts,
+				recovery.WithRegisterer(prometheus.DefaultRegisterer))
+		}
+		unary = append(unary, recovery.NewServerRecovery(opts...))
+		stream = append(stream, recovery.NewStreamServerRecovery(opts...))
+		outer.add("recovery", true)
+		log.Debug("enabled panic recovery")
+	}
+
+	for _, i := range sp.Interceptors {
+		unary = append(unary, i)
+		outer.Unary = append(outer.Unary, "custom")
+	}
+	for _, i := range sp.StreamInterceptors {
+		stream = append(stream, i)
+		outer.Stream = append(outer.Stream, "custom")
+	}
+
+	chainUnary, chainStream, names, err := sp.newInterceptors(ctx)
+	if err != nil {
+		return err
+	}
+	sp.chain = &interceptorChain{outer: outer}
+	sp.chain.set(chainUnary, chainStream, names)
+	sp.Interceptors = append(unary, sp.chain.unary)
+	sp.StreamInterceptors = append(stream, sp.chain.stream)
+	return nil
+}
+
+// newInterceptors returns the GoCSI interceptors configured by the
+// environment and their names. The interceptors that keep state across
+// RPCs, and whether or not they are enabled, are determined the first
+// time the interceptors are created and are reused afterwards. An error
+// is returned if the interceptors are misconfigured.
+func (sp *StoragePlugin) newInterceptors(
+	ctx context.Context,
+) (
+	unary []grpc.UnaryServerInterceptor,
+	stream []grpc.StreamServerInterceptor,
+	names interceptorNames,
+	err error,
+) {
+	unary = append(unary, sp.injectContext)
+	stream = append(stream, sp.injectStreamContext)
+	names.add("context", true)
 	log.Debug("enabled context injector")
 
+	shared := &sp.shared
+	if !shared.initialized {
+		if err = sp.initSharedInterceptors(ctx); err != nil {
+			return nil, nil, interceptorNames{}, err
+		}
+	}
+
 	var (
 		withReqLogging         = sp.getEnvBool(ctx, EnvVarReqLogging)
 		withRepLogging         = sp.getEnvBool(ctx, EnvVarRepLogging)
 		withDisableLogVolCtx   = sp.getEnvBool(ctx, EnvVarLoggingDisableVolCtx)
-		withSerialVol          = sp.getEnvBool(ctx, EnvVarSerialVolAccess)
 		withSpec               = sp.getEnvBool(ctx, EnvVarSpecValidation)
 		withStgTgtPath         = sp.getEnvBool(ctx, EnvVarRequireStagingTargetPath)
 		withVolContext         = sp.getEnvBool(ctx, EnvVarRequireVolContext)
@@ -96,12 +181,28 @@ func (sp *StoragePlugin) initInterceptors(ctx context.Context) {
 		log.WithField("withSpecRep", withSpecRep).Debug("init rep validation")
 	}
 
+	// The metrics interceptor is installed ahead of the others so the
+	// recorded latency includes all of the middleware.
+	if shared.metrics != nil {
+		unary = append(unary, shared.metrics)
+		stream = append(stream, shared.streamMetrics)
+		names.add("metrics", true)
+	}
+	if shared.tracer != nil {
+		unary = append(unary, shared.tracer)
+		stream = append(stream, shared.streamTracer)
+		names.add("tracing", true)
+	}
+
 	// Configure logging.
 	if withReqLogging || withRepLogging {
 		// Automatically enable request ID injection if logging
 		// is enabled.
-		sp.Interceptors = append(sp.Interceptors,
+		unary = append(unary,
 			requestid.NewServerRequestIDInjector())
+		stream = append(stream,
+			requestid.NewStreamServerRequestIDInjector())
+		names.add("requestid", true)
 		log.Debug("enabled request ID injector")
 
 		var (
@@ -114,6 +215,25 @@ func (sp *StoragePlugin) initInterceptors(ctx context.Context) {
 			log.Debug("disabled logging of VolumeContext field")
 		}
 
+		if v, ok := csictx.LookupEnv(ctx, EnvVarLogFormat); ok {
+			format, err := logging.ParseFormat(v)
+			if err != nil {
+				log.WithError(err).Warn("using text log format")
+			}
+			loggingOpts = append(loggingOpts, logging.WithFormat(format))
+			log.WithField("format", format).Debug("set log format")
+		}
+
+		if v, ok := csictx.LookupEnv(ctx, EnvVarLogRedact); ok {
+			rules, err := logging.ParseRedactionRules(v)
+			if err != nil {
+				log.WithError(err).Warn("ignoring log redaction rules")
+			} else if len(rules) > 0 {
+				loggingOpts = append(loggingOpts, logging.WithRedactionRules(rules...))
+				log.WithField("rules", rules).Debug("enabled log redaction rules")
+			}
+		}
+
 		if withReqLogging {
 			loggingOpts = append(loggingOpts, logging.WithRequestLogging(w))
 			log.Debug("enabled request logging")
@@ -122,8 +242,49 @@ func (sp *StoragePlugin) initInterceptors(ctx context.Context) {
 			loggingOpts = append(loggingOpts, logging.WithResponseLogging(w))
 			log.Debug("enabled response logging")
 		}
-		sp.Interceptors = append(sp.Interceptors,
+		unary = append(unary,
 			logging.NewServerLogger(loggingOpts...))
+		stream = append(stream,
+			logging.NewStreamServerLogger(loggingOpts...))
+		names.add("logging", true)
+	}
+
+	// The timeout interceptor follows the logger so the RPCs that exceed
+	// their timeout are logged with their response.
+	timeoutInterceptor, err := newTimeoutInterceptor(ctx)
+	if err != nil {
+		return nil, nil, interceptorNames{}, err
+	}
+	if timeoutInterceptor != nil {
+		unary = append(unary, timeoutInterceptor)
+		names.add("timeout", false)
+	}
+
+	// The in-flight RPC tracker follows the request ID injector so the
+	// RPCs reported at shutdown include their request IDs.
+	unary = append(unary, shared.inflight.handle)
+	stream = append(stream, shared.inflight.handleStream)
+	names.add("inflight", true)
+
+	// The RPCs that only the leader handles are rejected or forwarded
+	// before they count against the concurrency limits.
+	if shared.leader != nil {
+		unary = append(unary, shared.leader)
+		names.add("leaderelection", false)
+	}
+
+	// RPCs that exceed the concurrency limits are rejected before they
+	// are validated.
+	if shared.limiter != nil {
+		unary = append(unary, shared.limiter.Handle)
+		names.add("concurrency", false)
+	}
+
+	// RPCs that require capabilities the storage plug-in does not
+	// advertise are rejected before they are validated.
+	if shared.capabilities != nil {
+		unary = append(unary, shared.capabilities.Handle)
+		names.add("capabilities", false)
 	}
 
 	if withSpecReq || withSpecRep {
@@ -199,18 +360,31 @@ func (sp *StoragePlugin) initInterceptors(ctx context.Context) {
 				specvalidator.WithDisableFieldLenCheck())
 			log.Debug("disabled spec validator opt: field length check")
 		}
-		sp.Interceptors = append(sp.Interceptors,
+		unary = append(unary,
 			specvalidator.NewServerSpecValidator(specOpts...))
+		names.add("specvalidator", false)
 	}
 
 	if _, ok := csictx.LookupEnv(ctx, EnvVarPluginInfo); ok {
 		log.Debug("enabled GetPluginInfo interceptor")
-		sp.Interceptors = append(sp.Interceptors, sp.getPluginInfo)
+		unary = append(unary, sp.getPluginInfo)
+		names.add("plugininfo", false)
+	}
+
+	// The idempotency middleware precedes the serial volume middleware so
+	// duplicate RPCs wait for the original RPC's response instead of
+	// failing to obtain the volume's lock.
+	if shared.idempotency != nil {
+		unary = append(unary, shared.idempotency)
+		names.add("idempotency", false)
 	}
 
-	if withSerialVol {
+	if shared.lockProvider != nil {
 		var (
-			opts   []serialvolume.Option
+			opts = []serialvolume.Option{
+				serialvolume.WithLockProvider(shared.lockProvider),
+				serialvolume.WithRegistry(shared.locks),
+			}
 			fields = map[string]interface{}{}
 		)
 
@@ -223,18 +397,140 @@ func (sp *StoragePlugin) initInterceptors(ctx context.Context) {
 			}
 		}
 
+		// Get the RPCs that participate in serial volume access.
+		if v, _ := csictx.LookupEnv(
+			ctx, EnvVarSerialVolAccessRPCs); v != "" {
+			var rpcs []string
+			for _, rpc := range strings.Split(v, ",") {
+				if rpc = strings.TrimSpace(rpc); rpc != "" {
+					rpcs = append(rpcs, rpc)
+				}
+			}
+			fields["serialVol.rpcs"] = rpcs
+			opts = append(opts, serialvolume.WithRPCs(rpcs...))
+		}
+
+		unary = append(unary, serialvolume.New(opts...))
+		names.add("serialvolume", false)
+		log.WithFields(fields).Debug("enabled serial volume access")
+	}
+
+	return unary, stream, names, nil
+}
+
+// initSharedInterceptors creates the interceptors that keep state across
+// RPCs: the in-flight RPC tracker, the metrics, tracing, concurrency, and
+// idempotency interceptors, and the serial volume access lock provider.
+// An error is returned if one of them is misconfigured.
+func (sp *StoragePlugin) initSharedInterceptors(ctx context.Context) error {
+	shared := &sp.shared
+	shared.initialized = true
+	shared.inflight = &inflightRPCs{}
+
+	// Configure metrics.
+	if sp.getEnvBool(ctx, EnvVarMetrics) {
+		shared.metrics = metrics.NewServerMetrics()
+		shared.streamMetrics = metrics.NewStreamServerMetrics()
+		log.Debug("enabled metrics")
+	}
+
+	// Configure tracing.
+	if sp.getEnvBool(ctx, EnvVarTracing) {
+		var tracingOpts []tracing.Option
+		if v, ok := csictx.LookupEnv(ctx, EnvVarTracingPropagators); ok {
+			tracingOpts = append(tracingOpts,
+				tracing.WithPropagator(newTracingPropagator(v)))
+		}
+		shared.tracer = tracing.NewServerTracer(tracingOpts...)
+		shared.streamTracer = tracing.NewStreamServerTracer(tracingOpts...)
+		log.Debug("enabled tracing")
+	}
+
+	// Configure the concurrency limits.
+	limiter, err := newConcurrencyLimiter(ctx, shared.metrics != nil)
+	if err != nil {
+		return err
+	}
+	shared.limiter = limiter
+
+	// Configure the capability enforcement.
+	enforcer, err := newCapabilityEnforcer(ctx)
+	if err != nil {
+		return err
+	}
+	shared.capabilities = enforcer
+
+	if sp.getEnvBool(ctx, EnvVarIdempotency) {
+		var (
+			opts   []idempotency.Option
+			fields = map[string]interface{}{}
+		)
+
+		if v, _ := csictx.LookupEnv(ctx, EnvVarIdempotencyTTL); v != "" {
+			if t, err := time.ParseDuration(v); err == nil {
+				fields["idempotency.ttl"] = t
+				opts = append(opts, idempotency.WithTTL(t))
+			}
+		}
+
+		if sp.getEnvBool(ctx, EnvVarIdempotencyEtcd) {
+			s, err := idempotencyetcd.New(ctx, "", 0, nil)
+			if err != nil {
+				return err
+			}
+			opts = append(opts, idempotency.WithStore(s))
+		}
+
+		shared.idempotency = idempotency.New(opts...)
+		log.WithFields(fields).Debug("enabled idempotency")
+	}
+
+	// Leader election only applies to the controller service.
+	if sp.getEnvBool(ctx, EnvVarLeaderElection) &&
+		!strings.EqualFold(csictx.Getenv(ctx, EnvVarMode), "node") {
+		e, i, err := sp.newLeaderElection(ctx)
+		if err != nil {
+			return err
+		}
+		shared.elector, shared.leader = e, i
+	}
+
+	if sp.getEnvBool(ctx, EnvVarSerialVolAccess) {
+		shared.lockProvider = serialvolume.NewDefaultLockProvider()
+		shared.locks = serialvolume.NewRegistry()
+
 		// Check for etcd
 		if csictx.Getenv(ctx, EnvVarSerialVolAccessEtcdEndpoints) != "" {
 			p, err := etcd.New(ctx, "", 0, nil)
 			if err != nil {
-				log.Fatal(err)
+				return err
 			}
-			opts = append(opts, serialvolume.WithLockProvider(p))
+			shared.lockProvider = p
 		}
+	}
+	return nil
+}
 
-		sp.Interceptors = append(sp.Interceptors, serialvolume.New(opts...))
-		log.WithFields(fields).Debug("enabled serial volume access")
+// newTracingPropagator returns a composite propagator for the provided
+// comma-separated list of propagator names.
+func newTracingPropagator(names string) propagation.TextMapPropagator {
+	var props []propagation.TextMapPropagator
+	for _, name := range strings.Split(names, ",") {
+		switch name = strings.ToLower(strings.TrimSpace(name)); name {
+		case "tracecontext":
+			props = append(props, propagation.TraceContext{})
+		case "baggage":
+			props = append(props, propagation.Baggage{})
+		case "":
+		default:
+			log.WithField("propagator", name).Warn(
+				"ignoring unknown tracing propagator")
+		}
 	}
+	if len(props) == 0 {
+		return propagation.TraceContext{}
+	}
+	return propagation.NewCompositeTextMapPropagator(props...)
 }
 
 func (sp *StoragePlugin) injectContext(
@@ -246,6 +542,191 @@ func (sp *StoragePlugin) injectContext(
 	return handler(csictx.WithLookupEnv(ctx, sp.lookupEnv), req)
 }
 
+func (sp *StoragePlugin) injectStreamContext(
+	srv interface{},
+	ss grpc.ServerStream,
+	_ *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	return handler(srv, middleware.NewServerStreamWithContext(
+		csictx.WithLookupEnv(ss.Context(), sp.lookupEnv), ss))
+}
+
+// newTimeoutInterceptor returns the timeout interceptor configured by the
+// environment, or nil if no timeout is configured. An error is returned
+// if a timeout is invalid.
+func newTimeoutInterceptor(
+	ctx context.Context,
+) (grpc.UnaryServerInterceptor, error) {
+	var (
+		opts   []timeout.Option
+		fields = map[string]interface{}{}
+	)
+
+	parse := func(key, v string) (time.Duration, error) {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return 0, fmt.Errorf("invalid %s: %s", key, v)
+		}
+		return d, nil
+	}
+
+	if v, _ := csictx.LookupEnv(ctx, EnvVarRPCTimeout); v != "" {
+		d, err := parse(EnvVarRPCTimeout, v)
+		if err != nil {
+			return nil, err
+		}
+		fields["timeout"] = d
+		opts = append(opts, timeout.WithDefaultTimeout(d))
+	}
+	if v, _ := csictx.LookupEnv(ctx, EnvVarRPCTimeoutMax); v != "" {
+		d, err := parse(EnvVarRPCTimeoutMax, v)
+		if err != nil {
+			return nil, err
+		}
+		fields["maxTimeout"] = d
+		opts = append(opts, timeout.WithMaxTimeout(d))
+	}
+	if v, _ := csictx.LookupEnv(ctx, EnvVarRPCTimeoutPerMethod); v != "" {
+		for method, sz := range utils.ParseMap(v) {
+			d, err := parse(EnvVarRPCTimeoutPerMethod, sz)
+			if err != nil {
+				return nil, err
+			}
+			fields["timeout."+method] = d
+			opts = append(opts, timeout.WithMethodDefaultTimeout(method, d))
+		}
+	}
+	if v, _ := csictx.LookupEnv(ctx, EnvVarRPCTimeoutMaxPerMethod); v != "" {
+		for method, sz := range utils.ParseMap(v) {
+			d, err := parse(EnvVarRPCTimeoutMaxPerMethod, sz)
+			if err != nil {
+				return nil, err
+			}
+			fields["maxTimeout."+method] = d
+			opts = append(opts, timeout.WithMethodMaxTimeout(method, d))
+		}
+	}
+
+	if len(opts) == 0 {
+		return nil, nil
+	}
+	log.WithFields(fields).Debug("enabled rpc timeouts")
+	return timeout.NewServerTimeout(opts...), nil
+}
+
+// newConcurrencyLimiter returns the concurrency limiter configured by
+// the environment, or nil if no limit is configured.
+func newConcurrencyLimiter(
+	ctx context.Context, withMetrics bool,
+) (*concurrency.Limiter, error) {
+	var (
+		opts   []concurrency.Option
+		fields = map[string]interface{}{}
+	)
+
+	if v, _ := csictx.LookupEnv(ctx, EnvVarConcurrencyMaxInFlight); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf(
+				"invalid %s: %s", EnvVarConcurrencyMaxInFlight, v)
+		}
+		fields["maxInFlight"] = n
+		opts = append(opts, concurrency.WithMaxInFlight(n))
+	}
+
+	if v, _ := csictx.LookupEnv(ctx, EnvVarConcurrencyMaxInFlightPerMethod); v != "" {
+		methods := map[string]int{}
+		for method, sz := range utils.ParseMap(v) {
+			n, err := strconv.Atoi(sz)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid %s: %s",
+					EnvVarConcurrencyMaxInFlightPerMethod, v)
+			}
+			methods[method] = n
+			opts = append(opts, concurrency.WithMethodMaxInFlight(method, n))
+		}
+		fields["methods"] = methods
+	}
+
+	if len(opts) == 0 {
+		return nil, nil
+	}
+
+	if v, _ := csictx.LookupEnv(ctx, EnvVarConcurrencyMaxQueue); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf(
+				"invalid %s: %s", EnvVarConcurrencyMaxQueue, v)
+		}
+		fields["maxQueue"] = n
+		opts = append(opts, concurrency.WithMaxQueue(n))
+	}
+
+	if v, _ := csictx.LookupEnv(ctx, EnvVarConcurrencyRejectCode); v != "" {
+		switch {
+		case strings.EqualFold(v, codes.ResourceExhausted.String()):
+			opts = append(opts, concurrency.WithRejectCode(codes.ResourceExhausted))
+		case strings.EqualFold(v, codes.Aborted.String()):
+			opts = append(opts, concurrency.WithRejectCode(codes.Aborted))
+		default:
+			return nil, fmt.Errorf(
+				"invalid %s: %s", EnvVarConcurrencyRejectCode, v)
+		}
+		fields["rejectCode"] = v
+	}
+
+	if withMetrics {
+		opts = append(opts,
+			concurrency.WithRegisterer(prometheus.DefaultRegisterer))
+	}
+
+	log.WithFields(fields).Debug("enabled concurrency limits")
+	return concurrency.New(opts...), nil
+}
+
+// newCapabilityEnforcer returns the capability enforcer configured by
+// the environment, or nil if capabilities are not enforced.
+func newCapabilityEnforcer(ctx context.Context) (*capabilities.Enforcer, error) {
+	v, _ := csictx.LookupEnv(ctx, EnvVarCapabilityEnforcement)
+	switch {
+	case v == "":
+		return nil, nil
+	case strings.EqualFold(v, "reject"):
+		log.Debug("enabled capability enforcement")
+		return capabilities.New(), nil
+	case strings.EqualFold(v, "warn"):
+		log.Debug("enabled capability enforcement: warn only")
+		return capabilities.New(capabilities.WithWarnOnly()), nil
+	}
+	return nil, fmt.Errorf("invalid %s: %s", EnvVarCapabilityEnforcement, v)
+}
+
+// initCapabilities queries the capabilities advertised by the registered
+// services if capabilities are enforced.
+func (sp *StoragePlugin) initCapabilities(
+	ctx context.Context, registered []string,
+) error {
+	e := sp.shared.capabilities
+	if e == nil {
+		return nil
+	}
+	var svcs capabilities.Services
+	for _, name := range registered {
+		switch name {
+		case csi.Identity_ServiceDesc.ServiceName:
+			svcs.Identity = sp.Identity
+		case csi.Controller_ServiceDesc.ServiceName:
+			svcs.Controller = sp.Controller
+		case csi.GroupController_ServiceDesc.ServiceName:
+			svcs.GroupController = sp.GroupController
+		case csi.Node_ServiceDesc.ServiceName:
+			svcs.Node = sp.Node
+		}
+	}
+	return e.Init(ctx, svcs)
+}
+
 func (sp *StoragePlugin) getPluginInfo(
 	ctx context.Context,
 	req interface{},
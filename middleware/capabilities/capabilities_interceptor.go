@@ -0,0 +1,260 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package capabilities
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dell/gocsi/utils/rpcs"
+)
+
+// Services are the CSI services whose advertised capabilities are
+// enforced. The services that are nil are not queried, and their RPCs
+// are not enforced.
+type Services struct {
+	Identity        csi.IdentityServer
+	Controller      csi.ControllerServer
+	GroupController csi.GroupControllerServer
+	Node            csi.NodeServer
+}
+
+// Option configures the interceptor.
+type Option func(*opts)
+
+type opts struct {
+	warnOnly bool
+}
+
+// WithWarnOnly is an Option that logs the RPCs that require capabilities
+// that are not advertised instead of rejecting them.
+func WithWarnOnly() Option {
+	return func(o *opts) {
+		o.warnOnly = true
+	}
+}
+
+// requirement is the capability an RPC requires. The zero value of each
+// capability type means the capability is not required.
+type requirement struct {
+	plugin     csi.PluginCapability_Service_Type
+	controller csi.ControllerServiceCapability_RPC_Type
+	group      csi.GroupControllerServiceCapability_RPC_Type
+	node       csi.NodeServiceCapability_RPC_Type
+}
+
+func (r requirement) String() string {
+	switch {
+	case r.controller != 0:
+		return r.controller.String()
+	case r.group != 0:
+		return r.group.String()
+	case r.node != 0:
+		return r.node.String()
+	}
+	return r.plugin.String()
+}
+
+func controllerRPC(c csi.ControllerServiceCapability_RPC_Type) requirement {
+	return requirement{
+		plugin:     csi.PluginCapability_Service_CONTROLLER_SERVICE,
+		controller: c,
+	}
+}
+
+func groupControllerRPC(c csi.GroupControllerServiceCapability_RPC_Type) requirement {
+	return requirement{
+		plugin: csi.PluginCapability_Service_GROUP_CONTROLLER_SERVICE,
+		group:  c,
+	}
+}
+
+func nodeRPC(c csi.NodeServiceCapability_RPC_Type) requirement {
+	return requirement{node: c}
+}
+
+// requirements are the capabilities required by the RPCs, keyed by their
+// service and method names. The RPCs that are not listed do not require
+// a capability.
+var requirements = map[string]requirement{
+	"Controller/CreateVolume":              controllerRPC(csi.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME),
+	"Controller/DeleteVolume":              controllerRPC(csi.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME),
+	"Controller/ControllerPublishVolume":   controllerRPC(csi.ControllerServiceCapability_RPC_PUBLISH_UNPUBLISH_VOLUME),
+	"Controller/ControllerUnpublishVolume": controllerRPC(csi.ControllerServiceCapability_RPC_PUBLISH_UNPUBLISH_VOLUME),
+	"Controller/ListVolumes":               controllerRPC(csi.ControllerServiceCapability_RPC_LIST_VOLUMES),
+	"Controller/GetCapacity":               controllerRPC(csi.ControllerServiceCapability_RPC_GET_CAPACITY),
+	"Controller/CreateSnapshot":            controllerRPC(csi.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT),
+	"Controller/DeleteSnapshot":            controllerRPC(csi.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT),
+	"Controller/ListSnapshots":             controllerRPC(csi.ControllerServiceCapability_RPC_LIST_SNAPSHOTS),
+	"Controller/ControllerExpandVolume":    controllerRPC(csi.ControllerServiceCapability_RPC_EXPAND_VOLUME),
+	"Controller/ControllerGetVolume":       controllerRPC(csi.ControllerServiceCapability_RPC_GET_VOLUME),
+	"Controller/ControllerModifyVolume":    controllerRPC(csi.ControllerServiceCapability_RPC_MODIFY_VOLUME),
+
+	"GroupController/CreateVolumeGroupSnapshot": groupControllerRPC(csi.GroupControllerServiceCapability_RPC_CREATE_DELETE_GET_VOLUME_GROUP_SNAPSHOT),
+	"GroupController/DeleteVolumeGroupSnapshot": groupControllerRPC(csi.GroupControllerServiceCapability_RPC_CREATE_DELETE_GET_VOLUME_GROUP_SNAPSHOT),
+	"GroupController/GetVolumeGroupSnapshot":    groupControllerRPC(csi.GroupControllerServiceCapability_RPC_CREATE_DELETE_GET_VOLUME_GROUP_SNAPSHOT),
+
+	"Node/NodeStageVolume":    nodeRPC(csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME),
+	"Node/NodeUnstageVolume":  nodeRPC(csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME),
+	"Node/NodeGetVolumeStats": nodeRPC(csi.NodeServiceCapability_RPC_GET_VOLUME_STATS),
+	"Node/NodeExpandVolume":   nodeRPC(csi.NodeServiceCapability_RPC_EXPAND_VOLUME),
+}
+
+// advertised are the capabilities advertised by the services. A nil map
+// means the service was not queried.
+type advertised struct {
+	plugin     map[csi.PluginCapability_Service_Type]bool
+	controller map[csi.ControllerServiceCapability_RPC_Type]bool
+	group      map[csi.GroupControllerServiceCapability_RPC_Type]bool
+	node       map[csi.NodeServiceCapability_RPC_Type]bool
+}
+
+// missing returns whether or not the requirement is not advertised.
+func (a *advertised) missing(r requirement) bool {
+	switch {
+	case r.controller != 0 && a.controller != nil && !a.controller[r.controller]:
+		return true
+	case r.group != 0 && a.group != nil && !a.group[r.group]:
+		return true
+	case r.node != 0 && a.node != nil && !a.node[r.node]:
+		return true
+	case r.plugin != 0 && a.plugin != nil && !a.plugin[r.plugin]:
+		return true
+	}
+	return false
+}
+
+// Enforcer is an interceptor that rejects the RPCs whose capabilities
+// are not advertised by the plug-in. The capabilities are queried once,
+// when Init is called; until then no RPC is rejected.
+//
+// The RPCs are rejected with codes.Unimplemented, which a CO would
+// receive if the plug-in did not implement the RPC.
+type Enforcer struct {
+	opts opts
+	caps atomic.Pointer[advertised]
+}
+
+// New returns a new Enforcer.
+func New(opts ...Option) *Enforcer {
+	e := &Enforcer{}
+	for _, withOpts := range opts {
+		withOpts(&e.opts)
+	}
+	return e
+}
+
+// Init queries the capabilities advertised by the provided services. A
+// service that does not implement its capabilities RPC advertises none.
+func (e *Enforcer) Init(ctx context.Context, svcs Services) error {
+	a := &advertised{}
+
+	if svcs.Identity != nil {
+		rep, err := svcs.Identity.GetPluginCapabilities(
+			ctx, &csi.GetPluginCapabilitiesRequest{})
+		if err != nil && status.Code(err) != codes.Unimplemented {
+			return fmt.Errorf("failed to get plugin capabilities: %w", err)
+		}
+		a.plugin = map[csi.PluginCapability_Service_Type]bool{}
+		for _, c := range rep.GetCapabilities() {
+			if t := c.GetService().GetType(); t != 0 {
+				a.plugin[t] = true
+			}
+		}
+	}
+
+	if svcs.Controller != nil {
+		rep, err := svcs.Controller.ControllerGetCapabilities(
+			ctx, &csi.ControllerGetCapabilitiesRequest{})
+		if err != nil && status.Code(err) != codes.Unimplemented {
+			return fmt.Errorf("failed to get controller capabilities: %w", err)
+		}
+		a.controller = map[csi.ControllerServiceCapability_RPC_Type]bool{}
+		for _, c := range rep.GetCapabilities() {
+			a.controller[c.GetRpc().GetType()] = true
+		}
+	}
+
+	if svcs.GroupController != nil {
+		rep, err := svcs.GroupController.GroupControllerGetCapabilities(
+			ctx, &csi.GroupControllerGetCapabilitiesRequest{})
+		if err != nil && status.Code(err) != codes.Unimplemented {
+			return fmt.Errorf(
+				"failed to get group controller capabilities: %w", err)
+		}
+		a.group = map[csi.GroupControllerServiceCapability_RPC_Type]bool{}
+		for _, c := range rep.GetCapabilities() {
+			a.group[c.GetRpc().GetType()] = true
+		}
+	}
+
+	if svcs.Node != nil {
+		rep, err := svcs.Node.NodeGetCapabilities(
+			ctx, &csi.NodeGetCapabilitiesRequest{})
+		if err != nil && status.Code(err) != codes.Unimplemented {
+			return fmt.Errorf("failed to get node capabilities: %w", err)
+		}
+		a.node = map[csi.NodeServiceCapability_RPC_Type]bool{}
+		for _, c := range rep.GetCapabilities() {
+			a.node[c.GetRpc().GetType()] = true
+		}
+	}
+
+	e.caps.Store(a)
+	return nil
+}
+
+// Handle is a UnaryServerInterceptor that enforces the capabilities.
+func (e *Enforcer) Handle(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	a := e.caps.Load()
+	if a == nil {
+		return handler(ctx, req)
+	}
+	_, service, method, err := rpcs.ParseMethod(info.FullMethod)
+	if err != nil {
+		return handler(ctx, req)
+	}
+	r, ok := requirements[service+"/"+method]
+	if !ok || !a.missing(r) {
+		return handler(ctx, req)
+	}
+
+	if e.opts.warnOnly {
+		log.WithFields(map[string]interface{}{
+			"method":     info.FullMethod,
+			"capability": r,
+		}).Warn("rpc requires a capability that is not advertised")
+		return handler(ctx, req)
+	}
+	return nil, status.Errorf(codes.Unimplemented,
+		"%s requires the capability %s, which is not advertised",
+		method, r)
+}
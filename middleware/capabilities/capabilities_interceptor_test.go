@@ -0,0 +1,137 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package capabilities
+
+import (
+	"context"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dell/gocsi/mock/service"
+)
+
+func call(e *Enforcer, method string) error {
+	_, err := e.Handle(context.Background(), nil,
+		&grpc.UnaryServerInfo{FullMethod: method},
+		func(context.Context, interface{}) (interface{}, error) {
+			return nil, nil
+		})
+	return err
+}
+
+// node is a Node service that advertises STAGE_UNSTAGE_VOLUME.
+type node struct {
+	csi.UnimplementedNodeServer
+}
+
+func (node) NodeGetCapabilities(
+	context.Context, *csi.NodeGetCapabilitiesRequest,
+) (*csi.NodeGetCapabilitiesResponse, error) {
+	return &csi.NodeGetCapabilitiesResponse{
+		Capabilities: []*csi.NodeServiceCapability{
+			{
+				Type: &csi.NodeServiceCapability_Rpc{
+					Rpc: &csi.NodeServiceCapability_RPC{
+						Type: csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME,
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func TestHandle(t *testing.T) {
+	svc := service.NewServer()
+	e := New()
+
+	// No RPC is rejected until the capabilities are queried.
+	assert.NoError(t, call(e, "/csi.v1.Controller/ListSnapshots"))
+
+	assert.NoError(t, e.Init(context.Background(), Services{
+		Identity:        svc,
+		Controller:      svc,
+		GroupController: svc,
+		Node:            node{},
+	}))
+
+	for method, code := range map[string]codes.Code{
+		"/csi.v1.Identity/Probe":                                 codes.OK,
+		"/csi.v1.Controller/CreateVolume":                        codes.OK,
+		"/csi.v1.Controller/ControllerGetCapabilities":           codes.OK,
+		"/csi.v1.Controller/ValidateVolumeCapabilities":          codes.OK,
+		"/csi.v1.Controller/ListSnapshots":                       codes.Unimplemented,
+		"/csi.v1.Controller/ControllerGetVolume":                 codes.Unimplemented,
+		"/csi.v1.GroupController/CreateVolumeGroupSnapshot":      codes.Unimplemented,
+		"/csi.v1.Node/NodeStageVolume":                           codes.OK,
+		"/csi.v1.Node/NodePublishVolume":                         codes.OK,
+		"/csi.v1.Node/NodeExpandVolume":                          codes.Unimplemented,
+		"/csi.v1.Node/NodeGetVolumeStats":                        codes.Unimplemented,
+		"/csi.v1.GroupController/GroupControllerGetCapabilities": codes.OK,
+		"/example.v1.Example/Method":                             codes.OK,
+	} {
+		assert.Equal(t, code, status.Code(call(e, method)), method)
+	}
+}
+
+func TestHandleServices(t *testing.T) {
+	svc := service.NewServer()
+	e := New()
+
+	// The RPCs of the services that are not queried are not enforced.
+	assert.NoError(t, e.Init(context.Background(), Services{Node: node{}}))
+	assert.NoError(t, call(e, "/csi.v1.Controller/ListSnapshots"))
+	assert.Equal(t, codes.Unimplemented,
+		status.Code(call(e, "/csi.v1.Node/NodeExpandVolume")))
+
+	// The GROUP_CONTROLLER_SERVICE plug-in capability is required.
+	assert.NoError(t, e.Init(context.Background(), Services{Identity: svc}))
+	assert.NoError(t, call(e, "/csi.v1.Controller/ListSnapshots"))
+	assert.Equal(t, codes.Unimplemented, status.Code(
+		call(e, "/csi.v1.GroupController/GetVolumeGroupSnapshot")))
+}
+
+func TestWarnOnly(t *testing.T) {
+	e := New(WithWarnOnly())
+	assert.NoError(t, e.Init(context.Background(), Services{Node: node{}}))
+	assert.NoError(t, call(e, "/csi.v1.Node/NodeExpandVolume"))
+}
+
+func TestInitError(t *testing.T) {
+	e := New()
+	err := e.Init(context.Background(), Services{
+		Node: failingNode{},
+	})
+	assert.ErrorContains(t, err, "failed to get node capabilities")
+	assert.NoError(t, call(e, "/csi.v1.Node/NodeExpandVolume"))
+}
+
+type failingNode struct {
+	csi.UnimplementedNodeServer
+}
+
+func (failingNode) NodeGetCapabilities(
+	context.Context, *csi.NodeGetCapabilitiesRequest,
+) (*csi.NodeGetCapabilitiesResponse, error) {
+	return nil, status.Error(codes.Internal, "failed")
+}
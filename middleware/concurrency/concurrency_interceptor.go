@@ -0,0 +1,282 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package concurrency
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dell/gocsi/middleware/metrics"
+	"github.com/dell/gocsi/utils/rpcs"
+)
+
+// Option configures the interceptor.
+type Option func(*opts)
+
+type opts struct {
+	maxInFlight int
+	methods     map[string]int
+	maxQueue    int
+	code        codes.Code
+	registerer  prometheus.Registerer
+}
+
+// WithMaxInFlight is an Option that sets the maximum number of RPCs that
+// may be handled at the same time. The default value, zero, does not
+// limit the number of RPCs.
+func WithMaxInFlight(n int) Option {
+	return func(o *opts) {
+		o.maxInFlight = n
+	}
+}
+
+// WithMethodMaxInFlight is an Option that sets the maximum number of RPCs
+// of the provided method, ex. "CreateVolume", that may be handled at the
+// same time.
+func WithMethodMaxInFlight(method string, n int) Option {
+	return func(o *opts) {
+		if o.methods == nil {
+			o.methods = map[string]int{}
+		}
+		o.methods[method] = n
+	}
+}
+
+// WithMaxQueue is an Option that sets the maximum number of RPCs that may
+// wait for the RPCs in flight to complete. The RPCs that exceed a limit
+// when the queue is full are rejected. The default value, zero, rejects
+// the RPCs that exceed a limit immediately.
+func WithMaxQueue(n int) Option {
+	return func(o *opts) {
+		o.maxQueue = n
+	}
+}
+
+// WithRejectCode is an Option that sets the gRPC status code returned
+// for rejected RPCs. The default value is codes.ResourceExhausted.
+func WithRejectCode(c codes.Code) Option {
+	return func(o *opts) {
+		o.code = c
+	}
+}
+
+// WithRegisterer is an Option that registers the gauges of the number of
+// RPCs in flight and waiting with the provided Prometheus registerer.
+func WithRegisterer(r prometheus.Registerer) Option {
+	return func(o *opts) {
+		o.registerer = r
+	}
+}
+
+// Limiter limits the number of RPCs handled at the same time, both in
+// total and per method. The RPCs that exceed a limit wait in a bounded
+// FIFO queue for the RPCs in flight to complete, and are rejected when
+// the queue is full.
+//
+// Only the RPCs of the Controller, GroupController, and Node services
+// are limited. The Identity service, which is used to probe the storage
+// plug-in's health, and any additional services are not limited.
+type Limiter struct {
+	opts opts
+
+	mu       sync.Mutex
+	inFlight int
+	methods  map[string]int
+	queue    list.List
+
+	inFlightGauge prometheus.Gauge
+	queuedGauge   prometheus.Gauge
+}
+
+type waiter struct {
+	method  string
+	granted bool
+	ready   chan struct{}
+}
+
+// New returns a new Limiter.
+func New(opts ...Option) *Limiter {
+	l := &Limiter{methods: map[string]int{}}
+	l.opts.code = codes.ResourceExhausted
+	for _, setOpt := range opts {
+		setOpt(&l.opts)
+	}
+
+	if r := l.opts.registerer; r != nil {
+		l.inFlightGauge = metrics.Register(r, prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: "csi",
+				Name:      "rpc_concurrency_in_flight",
+				Help:      "Number of limited RPCs currently being handled.",
+			}))
+		l.queuedGauge = metrics.Register(r, prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: "csi",
+				Name:      "rpc_concurrency_queued",
+				Help:      "Number of limited RPCs waiting to be handled.",
+			}))
+		l.observe()
+	}
+
+	return l
+}
+
+// observe sets the gauges, if any, to the number of limited RPCs in
+// flight and waiting. l.mu must be held, except when l is created.
+func (l *Limiter) observe() {
+	if l.inFlightGauge != nil {
+		l.inFlightGauge.Set(float64(l.inFlight))
+		l.queuedGauge.Set(float64(l.queue.Len()))
+	}
+}
+
+// InFlight returns the number of limited RPCs being handled.
+func (l *Limiter) InFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight
+}
+
+// QueueDepth returns the number of RPCs waiting to be handled.
+func (l *Limiter) QueueDepth() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.queue.Len()
+}
+
+// Handle is a UnaryServerInterceptor that limits the RPCs.
+func (l *Limiter) Handle(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	_, service, method, err := rpcs.ParseMethod(info.FullMethod)
+	if err != nil || service == "Identity" {
+		return handler(ctx, req)
+	}
+
+	if err := l.acquire(ctx, method); err != nil {
+		return nil, err
+	}
+	defer l.release(method)
+
+	return handler(ctx, req)
+}
+
+// available returns whether or not an RPC of the provided method may be
+// handled without exceeding a limit.
+func (l *Limiter) available(method string) bool {
+	if l.opts.maxInFlight > 0 && l.inFlight >= l.opts.maxInFlight {
+		return false
+	}
+	if n, ok := l.opts.methods[method]; ok && l.methods[method] >= n {
+		return false
+	}
+	return true
+}
+
+func (l *Limiter) take(method string) {
+	l.inFlight++
+	l.methods[method]++
+}
+
+func (l *Limiter) acquire(ctx context.Context, method string) error {
+	l.mu.Lock()
+
+	// Waiters are granted as soon as they are available, so an RPC
+	// that is available does not need to wait behind them.
+	if l.available(method) {
+		l.take(method)
+		l.observe()
+		l.mu.Unlock()
+		return nil
+	}
+
+	if l.queue.Len() >= l.opts.maxQueue {
+		depth := l.queue.Len()
+		l.mu.Unlock()
+		log.WithFields(map[string]interface{}{
+			"method":     method,
+			"queueDepth": depth,
+		}).Warn("rejected rpc: too many rpcs in flight")
+		return status.Errorf(l.opts.code,
+			"too many rpcs in flight: %s", method)
+	}
+
+	w := &waiter{method: method, ready: make(chan struct{})}
+	e := l.queue.PushBack(w)
+	l.observe()
+	log.WithFields(map[string]interface{}{
+		"method":     method,
+		"queueDepth": l.queue.Len(),
+	}).Debug("queued rpc")
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		granted := w.granted
+		if !granted {
+			l.queue.Remove(e)
+			l.observe()
+		}
+		l.mu.Unlock()
+		if granted {
+			l.release(method)
+		}
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+func (l *Limiter) release(method string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	defer l.observe()
+
+	l.inFlight--
+	if l.methods[method]--; l.methods[method] == 0 {
+		delete(l.methods, method)
+	}
+
+	// Grant the waiters, in order, that are now available.
+	for e := l.queue.Front(); e != nil; {
+		if l.opts.maxInFlight > 0 && l.inFlight >= l.opts.maxInFlight {
+			break
+		}
+		next := e.Next()
+		w := e.Value.(*waiter)
+		if l.available(w.method) {
+			l.take(w.method)
+			w.granted = true
+			l.queue.Remove(e)
+			close(w.ready)
+		}
+		e = next
+	}
+}
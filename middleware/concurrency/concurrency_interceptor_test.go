@@ -0,0 +1,205 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package concurrency
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	createVolume = "/csi.v1.Controller/CreateVolume"
+	deleteVolume = "/csi.v1.Controller/DeleteVolume"
+	probe        = "/csi.v1.Identity/Probe"
+)
+
+// blocker is a handler that blocks until it is released.
+type blocker struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlocker() *blocker {
+	return &blocker{
+		started: make(chan struct{}, 100),
+		release: make(chan struct{}),
+	}
+}
+
+func (b *blocker) handle(ctx context.Context, _ interface{}) (interface{}, error) {
+	b.started <- struct{}{}
+	select {
+	case <-b.release:
+		return &csi.CreateVolumeResponse{}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func call(
+	ctx context.Context, l *Limiter, method string, handler grpc.UnaryHandler,
+) error {
+	_, err := l.Handle(ctx, nil,
+		&grpc.UnaryServerInfo{FullMethod: method}, handler)
+	return err
+}
+
+func ok(context.Context, interface{}) (interface{}, error) {
+	return &csi.CreateVolumeResponse{}, nil
+}
+
+func TestMaxInFlight(t *testing.T) {
+	l := New(WithMaxInFlight(2))
+	b := newBlocker()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, call(context.Background(), l, createVolume, b.handle))
+		}()
+		<-b.started
+	}
+	assert.Equal(t, 2, l.InFlight())
+
+	// The queue is empty so RPCs that exceed the limit are rejected.
+	err := call(context.Background(), l, deleteVolume, ok)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// The Identity service is not limited.
+	assert.NoError(t, call(context.Background(), l, probe, ok))
+
+	close(b.release)
+	wg.Wait()
+	assert.Equal(t, 0, l.InFlight())
+	assert.NoError(t, call(context.Background(), l, deleteVolume, ok))
+}
+
+func TestMethodMaxInFlight(t *testing.T) {
+	l := New(
+		WithMethodMaxInFlight("CreateVolume", 1),
+		WithRejectCode(codes.Aborted))
+	b := newBlocker()
+
+	done := make(chan error)
+	go func() {
+		done <- call(context.Background(), l, createVolume, b.handle)
+	}()
+	<-b.started
+
+	err := call(context.Background(), l, createVolume, ok)
+	assert.Equal(t, codes.Aborted, status.Code(err))
+
+	// Other methods are not limited.
+	assert.NoError(t, call(context.Background(), l, deleteVolume, ok))
+
+	close(b.release)
+	assert.NoError(t, <-done)
+}
+
+func TestQueue(t *testing.T) {
+	l := New(WithMaxInFlight(1), WithMaxQueue(1))
+	b := newBlocker()
+
+	first := make(chan error)
+	go func() {
+		first <- call(context.Background(), l, createVolume, b.handle)
+	}()
+	<-b.started
+
+	// The second RPC waits in the queue.
+	second := make(chan error)
+	go func() {
+		second <- call(context.Background(), l, createVolume, b.handle)
+	}()
+	assert.Eventually(t, func() bool {
+		return l.QueueDepth() == 1
+	}, 5*time.Second, time.Millisecond)
+
+	// The queue is full.
+	err := call(context.Background(), l, createVolume, ok)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// The second RPC is handled when the first completes.
+	b.release <- struct{}{}
+	assert.NoError(t, <-first)
+	<-b.started
+	assert.Equal(t, 0, l.QueueDepth())
+	b.release <- struct{}{}
+	assert.NoError(t, <-second)
+	assert.Equal(t, 0, l.InFlight())
+}
+
+func TestQueueContext(t *testing.T) {
+	l := New(WithMaxInFlight(1), WithMaxQueue(1))
+	b := newBlocker()
+
+	done := make(chan error)
+	go func() {
+		done <- call(context.Background(), l, createVolume, b.handle)
+	}()
+	<-b.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := call(ctx, l, createVolume, ok)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	assert.Equal(t, 0, l.QueueDepth())
+
+	close(b.release)
+	assert.NoError(t, <-done)
+	assert.Equal(t, 0, l.InFlight())
+}
+
+func TestRegisterer(t *testing.T) {
+	r := prometheus.NewRegistry()
+	New(WithRegisterer(r))
+	l := New(WithRegisterer(r), WithMaxInFlight(1))
+	b := newBlocker()
+
+	done := make(chan error)
+	go func() {
+		done <- call(context.Background(), l, createVolume, b.handle)
+	}()
+	<-b.started
+
+	mfs, err := r.Gather()
+	assert.NoError(t, err)
+	values := map[string]float64{}
+	for _, mf := range mfs {
+		values[mf.GetName()] = mf.GetMetric()[0].GetGauge().GetValue()
+	}
+	assert.Equal(t, map[string]float64{
+		"csi_rpc_concurrency_in_flight": 1,
+		"csi_rpc_concurrency_queued":    0,
+	}, values)
+
+	close(b.release)
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,117 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package idempotency
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+type defaultStore struct {
+	entriesL sync.Mutex
+	entries  map[string]*entry
+}
+
+// entry is an in-flight or completed RPC. The done channel is closed
+// when the RPC is completed or abandoned.
+type entry struct {
+	done chan struct{}
+	rep  []byte
+}
+
+func newDefaultStore() *defaultStore {
+	return &defaultStore{entries: map[string]*entry{}}
+}
+
+func (s *defaultStore) Begin(
+	ctx context.Context, key string,
+) ([]byte, bool, error) {
+	for {
+		s.entriesL.Lock()
+		e := s.entries[key]
+		if e == nil {
+			s.entries[key] = &entry{done: make(chan struct{})}
+			s.entriesL.Unlock()
+			return nil, true, nil
+		}
+		if e.rep != nil {
+			s.entriesL.Unlock()
+			return e.rep, false, nil
+		}
+		s.entriesL.Unlock()
+
+		// Wait for the owner of the key to complete or abandon it and
+		// then try again.
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+}
+
+func (s *defaultStore) Complete(
+	_ context.Context, key string, rep []byte, ttl time.Duration,
+) error {
+	s.entriesL.Lock()
+	defer s.entriesL.Unlock()
+	e := s.entries[key]
+	if e == nil || e.rep != nil {
+		return nil
+	}
+	e.rep = rep
+	close(e.done)
+
+	// Remove the cached response once it expires.
+	time.AfterFunc(ttl, func() {
+		s.entriesL.Lock()
+		defer s.entriesL.Unlock()
+		if s.entries[key] == e {
+			delete(s.entries, key)
+		}
+	})
+	return nil
+}
+
+func (s *defaultStore) Abandon(_ context.Context, key string) error {
+	s.entriesL.Lock()
+	defer s.entriesL.Unlock()
+	e := s.entries[key]
+	if e == nil || e.rep != nil {
+		return nil
+	}
+	delete(s.entries, key)
+	close(e.done)
+	return nil
+}
+
+func (s *defaultStore) Invalidate(
+	_ context.Context, prefix, except string,
+) error {
+	s.entriesL.Lock()
+	defer s.entriesL.Unlock()
+	for key, e := range s.entries {
+		if e.rep != nil && key != except && strings.HasPrefix(key, prefix) {
+			delete(s.entries, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultStore(t *testing.T) {
+	ctx := context.Background()
+	s := newDefaultStore()
+
+	_, owner, err := s.Begin(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, owner)
+
+	// A second caller waits for the owner.
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, _, err = s.Begin(waitCtx, "a")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Abandoning the key makes the next caller its owner.
+	assert.NoError(t, s.Abandon(ctx, "a"))
+	_, owner, err = s.Begin(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, owner)
+
+	assert.NoError(t, s.Complete(ctx, "a", []byte("rep"), 50*time.Millisecond))
+	rep, owner, err := s.Begin(ctx, "a")
+	assert.NoError(t, err)
+	assert.False(t, owner)
+	assert.Equal(t, []byte("rep"), rep)
+
+	// Completing or abandoning a completed or unknown key is a no-op.
+	assert.NoError(t, s.Complete(ctx, "a", []byte("other"), time.Minute))
+	assert.NoError(t, s.Abandon(ctx, "a"))
+	assert.NoError(t, s.Abandon(ctx, "b"))
+	rep, _, _ = s.Begin(ctx, "a")
+	assert.Equal(t, []byte("rep"), rep)
+
+	// The cached response is removed once it expires.
+	assert.Eventually(t, func() bool {
+		s.entriesL.Lock()
+		defer s.entriesL.Unlock()
+		return len(s.entries) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestDefaultStoreInvalidate(t *testing.T) {
+	ctx := context.Background()
+	s := newDefaultStore()
+
+	for _, key := range []string{"v/a", "v/b", "v/c", "vv/a"} {
+		_, owner, err := s.Begin(ctx, key)
+		assert.NoError(t, err)
+		assert.True(t, owner)
+	}
+	for _, key := range []string{"v/a", "v/b", "vv/a"} {
+		assert.NoError(t, s.Complete(ctx, key, []byte("rep"), time.Minute))
+	}
+
+	// The completed keys with the prefix are removed except for the
+	// excepted key. The in-flight key is not changed.
+	assert.NoError(t, s.Invalidate(ctx, "v/", "v/a"))
+	s.entriesL.Lock()
+	assert.Len(t, s.entries, 3)
+	assert.NotNil(t, s.entries["v/a"].rep)
+	assert.Nil(t, s.entries["v/b"])
+	assert.Nil(t, s.entries["v/c"].rep)
+	assert.NotNil(t, s.entries["vv/a"].rep)
+	s.entriesL.Unlock()
+	assert.NoError(t, s.Abandon(ctx, "v/c"))
+}
@@ -0,0 +1,310 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	etcd "go.etcd.io/etcd/client/v3"
+
+	csictx "github.com/dell/gocsi/context"
+	"github.com/dell/gocsi/middleware/idempotency/store"
+	serialvoletcd "github.com/dell/gocsi/middleware/serialvolume/etcd"
+)
+
+// DefaultTTL is the default length of time etcd will wait before
+// releasing an in-flight request if its owner's lease is not renewed.
+const DefaultTTL = time.Minute
+
+// New returns a new etcd idempotency store. If no configuration is
+// provided then the etcd client is configured from the serial volume
+// access etcd environment variables, ex.
+// X_CSI_SERIAL_VOL_ACCESS_ETCD_ENDPOINTS.
+func New(
+	ctx context.Context,
+	domain string,
+	ttl time.Duration,
+	config *etcd.Config,
+) (store.Store, error) {
+	fields := map[string]interface{}{}
+
+	if domain == "" {
+		domain = csictx.Getenv(ctx, EnvVarDomain)
+	}
+	domain = path.Join("/", domain)
+	fields["idempotency.etcd.domain"] = domain
+
+	if ttl == 0 {
+		ttl, _ = time.ParseDuration(csictx.Getenv(ctx, EnvVarTTL))
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	fields["idempotency.etcd.ttl"] = ttl
+
+	if config == nil {
+		cfg, err := serialvoletcd.NewConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		config = &cfg
+	}
+
+	log.WithFields(fields).Info("creating idempotency etcd store")
+
+	client, err := etcd.New(*config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &etcdStore{
+		client: client,
+		domain: domain,
+		ttl:    ttlSeconds(ttl),
+		owned:  map[string]*ownedKey{},
+	}, nil
+}
+
+type etcdStore struct {
+	client *etcd.Client
+	domain string
+	ttl    int64
+
+	ownedL sync.Mutex
+	owned  map[string]*ownedKey
+}
+
+// ownedKey is the lease of an in-flight request owned by this store.
+// The lease is kept alive until the request is completed or abandoned.
+type ownedKey struct {
+	lease  etcd.LeaseID
+	cancel context.CancelFunc
+}
+
+// ttlSeconds returns the duration in whole seconds, rounded up, as
+// required by etcd leases.
+func ttlSeconds(d time.Duration) int64 {
+	s := int64((d + time.Second - 1) / time.Second)
+	if s < 1 {
+		s = 1
+	}
+	return s
+}
+
+func (s *etcdStore) Close() error {
+	return s.client.Close()
+}
+
+// In-flight requests are recorded with an empty value. Completed
+// requests are recorded with their marshaled responses, which are never
+// empty.
+func (s *etcdStore) Begin(
+	ctx context.Context, key string,
+) ([]byte, bool, error) {
+	k := path.Join(s.domain, key)
+	for {
+		lease, err := s.client.Grant(ctx, s.ttl)
+		if err != nil {
+			return nil, false, err
+		}
+
+		txn, err := s.client.Txn(ctx).
+			If(etcd.Compare(etcd.CreateRevision(k), "=", 0)).
+			Then(etcd.OpPut(k, "", etcd.WithLease(lease.ID))).
+			Else(etcd.OpGet(k)).
+			Commit()
+		if err != nil {
+			s.revoke(lease.ID)
+			return nil, false, err
+		}
+
+		// The key was claimed.
+		if txn.Succeeded {
+			if err := s.keepAlive(key, lease.ID); err != nil {
+				s.revoke(lease.ID)
+				return nil, false, err
+			}
+			return nil, true, nil
+		}
+		s.revoke(lease.ID)
+
+		kvs := txn.Responses[0].GetResponseRange().Kvs
+		if len(kvs) == 0 {
+			continue
+		}
+		if len(kvs[0].Value) > 0 {
+			return kvs[0].Value, false, nil
+		}
+
+		// Wait for the owner of the key to complete or abandon it and
+		// then try again.
+		if err := s.wait(ctx, k, kvs[0].ModRevision); err != nil {
+			return nil, false, err
+		}
+	}
+}
+
+// wait blocks until the key is modified after the provided revision.
+func (s *etcdStore) wait(ctx context.Context, k string, rev int64) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for wrsp := range s.client.Watch(ctx, k, etcd.WithRev(rev+1)) {
+		if err := wrsp.Err(); err != nil {
+			return err
+		}
+		if len(wrsp.Events) > 0 {
+			return nil
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("watch closed: %s", k)
+}
+
+func (s *etcdStore) keepAlive(key string, lease etcd.LeaseID) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := s.client.KeepAlive(ctx, lease)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	// The keep alive responses must be consumed.
+	go func() {
+		for range ch {
+		}
+	}()
+
+	s.ownedL.Lock()
+	defer s.ownedL.Unlock()
+	s.owned[key] = &ownedKey{lease: lease, cancel: cancel}
+	return nil
+}
+
+// release stops renewing and revokes the lease of the owned key.
+func (s *etcdStore) release(key string) {
+	s.ownedL.Lock()
+	o := s.owned[key]
+	delete(s.owned, key)
+	s.ownedL.Unlock()
+	if o == nil {
+		return
+	}
+	o.cancel()
+	s.revoke(o.lease)
+}
+
+func (s *etcdStore) revoke(lease etcd.LeaseID) {
+	if _, err := s.client.Revoke(context.Background(), lease); err != nil {
+		log.WithError(err).Debug("idempotency etcd store: revoke failed")
+	}
+}
+
+// ownerLease returns the in-flight lease of the owned key. Completing or
+// abandoning a key is conditional on the key still having this lease, so
+// a key claimed by another owner after the lease expired is not changed.
+func (s *etcdStore) ownerLease(key string) (etcd.LeaseID, bool) {
+	s.ownedL.Lock()
+	defer s.ownedL.Unlock()
+	if o := s.owned[key]; o != nil {
+		return o.lease, true
+	}
+	return etcd.NoLease, false
+}
+
+func (s *etcdStore) Complete(
+	ctx context.Context, key string, rep []byte, ttl time.Duration,
+) error {
+	owner, ok := s.ownerLease(key)
+	if !ok {
+		return nil
+	}
+	defer s.release(key)
+
+	// The response is stored with a new lease that expires with the TTL.
+	// Replacing the lease detaches the key from the in-flight lease, so
+	// revoking that lease does not delete the key.
+	lease, err := s.client.Grant(ctx, ttlSeconds(ttl))
+	if err != nil {
+		return err
+	}
+	k := path.Join(s.domain, key)
+	txn, err := s.client.Txn(ctx).
+		If(etcd.Compare(etcd.LeaseValue(k), "=", owner)).
+		Then(etcd.OpPut(k, string(rep), etcd.WithLease(lease.ID))).
+		Commit()
+	if err != nil || !txn.Succeeded {
+		s.revoke(lease.ID)
+	}
+	return err
+}
+
+func (s *etcdStore) Abandon(ctx context.Context, key string) error {
+	owner, ok := s.ownerLease(key)
+	if !ok {
+		return nil
+	}
+	defer s.release(key)
+
+	k := path.Join(s.domain, key)
+	_, err := s.client.Txn(ctx).
+		If(etcd.Compare(etcd.LeaseValue(k), "=", owner)).
+		Then(etcd.OpDelete(k)).
+		Commit()
+	return err
+}
+
+func (s *etcdStore) Invalidate(
+	ctx context.Context, prefix, except string,
+) error {
+	// Joining the prefix to the domain removes a trailing slash, which
+	// separates the prefix from the keys that merely start with it.
+	p := path.Join(s.domain, prefix)
+	if strings.HasSuffix(prefix, "/") {
+		p += "/"
+	}
+	rsp, err := s.client.Get(ctx, p, etcd.WithPrefix())
+	if err != nil {
+		return err
+	}
+	except = path.Join(s.domain, except)
+	for _, kv := range rsp.Kvs {
+		if len(kv.Value) == 0 || string(kv.Key) == except {
+			continue
+		}
+
+		// The key is only deleted if it was not modified since it was
+		// read, so a key that was claimed again is left to its owner.
+		k := string(kv.Key)
+		if _, err := s.client.Txn(ctx).
+			If(etcd.Compare(etcd.ModRevision(k), "=", kv.ModRevision)).
+			Then(etcd.OpDelete(k)).
+			Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
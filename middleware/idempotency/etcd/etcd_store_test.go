@@ -0,0 +1,249 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package etcd
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	etcd "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+
+	"github.com/dell/gocsi/middleware/idempotency/store"
+)
+
+// The embedded etcd server listens on ports that differ from those used
+// by the serial volume etcd tests so both packages may be tested at the
+// same time.
+const (
+	clientURL = "http://127.0.0.1:2479"
+	peerURL   = "http://127.0.0.1:2480"
+)
+
+var s store.Store
+
+func TestMain(m *testing.M) {
+	log.SetLevel(log.InfoLevel)
+
+	dir, err := os.MkdirTemp("", "idempotency-etcd")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	e, err := startEtcd(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	<-e.Server.ReadyNotify()
+
+	s, err = New(context.TODO(), "/gocsi/idempotency", 2*time.Second,
+		&etcd.Config{Endpoints: []string{clientURL}})
+	if err != nil {
+		log.Fatalln(err)
+	}
+	exitCode := m.Run()
+	s.(io.Closer).Close()
+	e.Close()
+	os.RemoveAll(dir)
+	os.Exit(exitCode)
+}
+
+func startEtcd(dir string) (*embed.Etcd, error) {
+	cu, _ := url.Parse(clientURL)
+	pu, _ := url.Parse(peerURL)
+
+	cfg := embed.NewConfig()
+	cfg.Dir = dir
+	cfg.ListenClientUrls = []url.URL{*cu}
+	cfg.AdvertiseClientUrls = []url.URL{*cu}
+	cfg.ListenPeerUrls = []url.URL{*pu}
+	cfg.AdvertisePeerUrls = []url.URL{*pu}
+	cfg.InitialCluster = cfg.Name + "=" + peerURL
+	cfg.LogLevel = "error"
+
+	return embed.StartEtcd(cfg)
+}
+
+func TestBeginComplete(t *testing.T) {
+	ctx := context.Background()
+	key := t.Name()
+
+	_, owner, err := s.Begin(ctx, key)
+	assert.NoError(t, err)
+	assert.True(t, owner)
+
+	// A second caller waits for the owner and receives its response.
+	type result struct {
+		rep   []byte
+		owner bool
+		err   error
+	}
+	ch := make(chan result)
+	go func() {
+		rep, owner, err := s.Begin(ctx, key)
+		ch <- result{rep, owner, err}
+	}()
+
+	select {
+	case <-ch:
+		t.Fatal("duplicate did not wait")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	assert.NoError(t, s.Complete(ctx, key, []byte("rep"), time.Minute))
+	res := <-ch
+	assert.NoError(t, res.err)
+	assert.False(t, res.owner)
+	assert.Equal(t, []byte("rep"), res.rep)
+
+	// The cached response is returned without waiting.
+	rep, owner, err := s.Begin(ctx, key)
+	assert.NoError(t, err)
+	assert.False(t, owner)
+	assert.Equal(t, []byte("rep"), rep)
+}
+
+func TestBeginAbandon(t *testing.T) {
+	ctx := context.Background()
+	key := t.Name()
+
+	_, owner, err := s.Begin(ctx, key)
+	assert.NoError(t, err)
+	assert.True(t, owner)
+
+	ch := make(chan bool)
+	go func() {
+		_, owner, err := s.Begin(ctx, key)
+		assert.NoError(t, err)
+		ch <- owner
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	assert.NoError(t, s.Abandon(ctx, key))
+
+	// The waiting caller becomes the owner.
+	assert.True(t, <-ch)
+	assert.NoError(t, s.Abandon(ctx, key))
+
+	// Abandoning a key that is not owned is a no-op.
+	assert.NoError(t, s.Abandon(ctx, "unowned"))
+	assert.NoError(t, s.Complete(ctx, "unowned", []byte("rep"), time.Minute))
+}
+
+func TestBeginCanceled(t *testing.T) {
+	ctx := context.Background()
+	key := t.Name()
+
+	_, owner, err := s.Begin(ctx, key)
+	assert.NoError(t, err)
+	assert.True(t, owner)
+	defer s.Abandon(ctx, key)
+
+	waitCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, _, err = s.Begin(waitCtx, key)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestCompleteExpires(t *testing.T) {
+	ctx := context.Background()
+	key := t.Name()
+
+	_, owner, err := s.Begin(ctx, key)
+	assert.NoError(t, err)
+	assert.True(t, owner)
+	assert.NoError(t, s.Complete(ctx, key, []byte("rep"), time.Second))
+
+	// Once the response expires the next caller owns the key.
+	assert.Eventually(t, func() bool {
+		_, owner, err := s.Begin(ctx, key)
+		assert.NoError(t, err)
+		return owner
+	}, 5*time.Second, 250*time.Millisecond)
+	assert.NoError(t, s.Abandon(ctx, key))
+}
+
+func TestInvalidate(t *testing.T) {
+	ctx := context.Background()
+	prefix := t.Name() + "/"
+
+	for _, key := range []string{"a", "b", "c"} {
+		_, owner, err := s.Begin(ctx, prefix+key)
+		assert.NoError(t, err)
+		assert.True(t, owner)
+	}
+	_, owner, err := s.Begin(ctx, t.Name()+"2/a")
+	assert.NoError(t, err)
+	assert.True(t, owner)
+	for _, key := range []string{prefix + "a", prefix + "b", t.Name() + "2/a"} {
+		assert.NoError(t, s.Complete(ctx, key, []byte("rep"), time.Minute))
+	}
+
+	assert.NoError(t, s.Invalidate(ctx, prefix, prefix+"a"))
+
+	// The excepted key and the key without the prefix are still cached.
+	for _, key := range []string{prefix + "a", t.Name() + "2/a"} {
+		rep, owner, err := s.Begin(ctx, key)
+		assert.NoError(t, err)
+		assert.False(t, owner)
+		assert.Equal(t, []byte("rep"), rep)
+	}
+
+	// The invalidated key is claimed by the next caller.
+	_, owner, err = s.Begin(ctx, prefix+"b")
+	assert.NoError(t, err)
+	assert.True(t, owner)
+	assert.NoError(t, s.Abandon(ctx, prefix+"b"))
+
+	// The in-flight key is still owned.
+	waitCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, _, err = s.Begin(waitCtx, prefix+"c")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NoError(t, s.Abandon(ctx, prefix+"c"))
+}
+
+func TestTTLSeconds(t *testing.T) {
+	assert.Equal(t, int64(1), ttlSeconds(0))
+	assert.Equal(t, int64(1), ttlSeconds(time.Millisecond))
+	assert.Equal(t, int64(2), ttlSeconds(1500*time.Millisecond))
+	assert.Equal(t, int64(60), ttlSeconds(time.Minute))
+}
+
+func TestNewFromEnv(t *testing.T) {
+	t.Setenv(EnvVarDomain, "env")
+	t.Setenv(EnvVarTTL, "invalid")
+	t.Setenv("X_CSI_SERIAL_VOL_ACCESS_ETCD_ENDPOINTS", clientURL)
+
+	es, err := New(context.Background(), "", 0, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "/env", es.(*etcdStore).domain)
+	assert.Equal(t, int64(60), es.(*etcdStore).ttl)
+	assert.NoError(t, es.(io.Closer).Close())
+
+	t.Setenv("X_CSI_SERIAL_VOL_ACCESS_ETCD_DIAL_TIMEOUT", "invalid")
+	_, err = New(context.Background(), "", 0, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,274 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/dell/gocsi/middleware/idempotency/store"
+)
+
+// DefaultTTL is the default length of time for which the response of a
+// completed RPC is cached.
+const DefaultTTL = 10 * time.Second
+
+// Option configures the interceptor.
+type Option func(*opts)
+
+type opts struct {
+	ttl   time.Duration
+	store store.Store
+}
+
+// WithTTL is an Option that sets the length of time for which the
+// response of a completed RPC is cached.
+func WithTTL(t time.Duration) Option {
+	return func(o *opts) {
+		o.ttl = t
+	}
+}
+
+// WithStore is an Option that sets the store used by the interceptor.
+func WithStore(s store.Store) Option {
+	return func(o *opts) {
+		o.store = s
+	}
+}
+
+// New returns a new server-side, gRPC interceptor that deduplicates
+// the following RPCs:
+//
+//   - CreateVolume
+//   - DeleteVolume
+//   - ControllerPublishVolume
+//   - ControllerUnpublishVolume
+//   - ControllerExpandVolume
+//   - CreateSnapshot
+//   - DeleteSnapshot
+//   - NodeStageVolume
+//   - NodeUnstageVolume
+//   - NodePublishVolume
+//   - NodeUnpublishVolume
+//   - NodeExpandVolume
+//
+// RPCs are keyed by the volume name or ID, the method, and a hash of
+// the request. A duplicate RPC that arrives while the original RPC is
+// in flight waits for the original RPC to complete and receives the
+// same response. A duplicate RPC that arrives after the original RPC
+// succeeded receives the cached response until the TTL expires or
+// another RPC for the same volume name or ID starts, ex. a
+// NodeUnpublishVolume RPC drops the cached NodePublishVolume response.
+// Failed RPCs are not cached, so a duplicate of a failed RPC is handled
+// again.
+//
+// Please note a cached response is still returned if the resource was
+// modified by an RPC keyed by a different value, for example a
+// CreateVolume RPC, keyed by name, repeated after the volume was
+// deleted by ID. The TTL should only be long enough to cover the
+// retries of the CO.
+func New(opts ...Option) grpc.UnaryServerInterceptor {
+	i := &interceptor{}
+
+	// Configure the interceptor's options.
+	for _, setOpt := range opts {
+		setOpt(&i.opts)
+	}
+
+	if i.opts.ttl <= 0 {
+		i.opts.ttl = DefaultTTL
+	}
+
+	// If no store is configured then set the default, in-memory store.
+	if i.opts.store == nil {
+		i.opts.store = newDefaultStore()
+	}
+
+	return i.handle
+}
+
+type interceptor struct {
+	opts opts
+}
+
+func (i *interceptor) handle(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	prefix, key, ok := requestKey(info.FullMethod, req)
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	buf, owner, err := i.opts.store.Begin(ctx, key)
+	if err != nil {
+		if errors.Is(err, context.Canceled) ||
+			errors.Is(err, context.DeadlineExceeded) {
+			return nil, status.FromContextError(err).Err()
+		}
+		return nil, err
+	}
+
+	// Return the response cached by the original RPC.
+	if !owner {
+		rep, err := unmarshalResponse(buf)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal,
+				"failed to unmarshal cached response: %v", err)
+		}
+		log.WithField("key", key).Debug("returned cached response")
+		return rep, nil
+	}
+
+	// Ensure the key is abandoned if the handler fails or panics. The
+	// store is updated even if the RPC's context is canceled so the
+	// duplicates do not wait for the key forever.
+	completed := false
+	defer func() {
+		if completed {
+			return
+		}
+		if err := i.opts.store.Abandon(
+			context.WithoutCancel(ctx), key); err != nil {
+			log.WithError(err).WithField("key", key).Error(
+				"failed to abandon request")
+		}
+	}()
+
+	// Drop the responses cached by the other RPCs for the volume so
+	// they are handled again if repeated after this RPC.
+	if err := i.opts.store.Invalidate(ctx, prefix, key); err != nil {
+		if errors.Is(err, context.Canceled) ||
+			errors.Is(err, context.DeadlineExceeded) {
+			return nil, status.FromContextError(err).Err()
+		}
+		return nil, err
+	}
+
+	rep, err := handler(ctx, req)
+	if err != nil {
+		return rep, err
+	}
+
+	buf, err = marshalResponse(rep)
+	if err != nil {
+		log.WithError(err).WithField("key", key).Warn(
+			"failed to marshal response")
+		return rep, nil
+	}
+	if err := i.opts.store.Complete(
+		context.WithoutCancel(ctx), key, buf, i.opts.ttl); err != nil {
+		log.WithError(err).WithField("key", key).Warn(
+			"failed to cache response")
+		return rep, nil
+	}
+	completed = true
+
+	return rep, nil
+}
+
+// requestKey returns the prefix shared by the keys of all requests for
+// the same volume name or ID and the key for the provided request. A
+// false value is returned if the request does not participate in
+// deduplication.
+func requestKey(method string, req interface{}) (string, string, bool) {
+	var id string
+	switch treq := req.(type) {
+	case *csi.CreateVolumeRequest:
+		id = treq.Name
+	case *csi.DeleteVolumeRequest:
+		id = treq.VolumeId
+	case *csi.ControllerPublishVolumeRequest:
+		id = treq.VolumeId
+	case *csi.ControllerUnpublishVolumeRequest:
+		id = treq.VolumeId
+	case *csi.ControllerExpandVolumeRequest:
+		id = treq.VolumeId
+	case *csi.CreateSnapshotRequest:
+		id = treq.Name
+	case *csi.DeleteSnapshotRequest:
+		id = treq.SnapshotId
+	case *csi.NodeStageVolumeRequest:
+		id = treq.VolumeId
+	case *csi.NodeUnstageVolumeRequest:
+		id = treq.VolumeId
+	case *csi.NodePublishVolumeRequest:
+		id = treq.VolumeId
+	case *csi.NodeUnpublishVolumeRequest:
+		id = treq.VolumeId
+	case *csi.NodeExpandVolumeRequest:
+		id = treq.VolumeId
+	default:
+		return "", "", false
+	}
+
+	// Requests without a name or ID are left to the spec validator.
+	if id == "" {
+		return "", "", false
+	}
+
+	// The request is marshaled deterministically so equal requests have
+	// equal hashes.
+	buf, err := proto.MarshalOptions{Deterministic: true}.Marshal(
+		req.(proto.Message))
+	if err != nil {
+		return "", "", false
+	}
+	sum := sha256.Sum256(buf)
+
+	// The name or ID is escaped so it is a single segment of the key.
+	prefix := url.PathEscape(id) + "/"
+	return prefix, path.Join(prefix, method, hex.EncodeToString(sum[:])), true
+}
+
+func marshalResponse(rep interface{}) ([]byte, error) {
+	m, ok := rep.(proto.Message)
+	if !ok {
+		return nil, status.Errorf(codes.Internal,
+			"invalid response type: %T", rep)
+	}
+	a, err := anypb.New(m)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(a)
+}
+
+func unmarshalResponse(buf []byte) (interface{}, error) {
+	a := &anypb.Any{}
+	if err := proto.Unmarshal(buf, a); err != nil {
+		return nil, err
+	}
+	return a.UnmarshalNew()
+}
@@ -0,0 +1,305 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+var createVolumeInfo = &grpc.UnaryServerInfo{
+	FullMethod: "/csi.v1.Controller/CreateVolume",
+}
+
+func TestDuplicateInFlight(t *testing.T) {
+	i := New()
+
+	var (
+		calls   int32
+		release = make(chan struct{})
+		started = make(chan struct{})
+	)
+	handler := func(_ context.Context, req interface{}) (interface{}, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+		}
+		<-release
+		return &csi.CreateVolumeResponse{Volume: &csi.Volume{
+			VolumeId: "vol-" + req.(*csi.CreateVolumeRequest).Name,
+		}}, nil
+	}
+
+	req := &csi.CreateVolumeRequest{Name: "pvc-1"}
+	reps := make([]interface{}, 3)
+	errs := make([]error, 3)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		reps[0], errs[0] = i(context.Background(), req, createVolumeInfo, handler)
+	}()
+	<-started
+
+	for j := 1; j < len(reps); j++ {
+		wg.Add(1)
+		go func(j int) {
+			defer wg.Done()
+			reps[j], errs[j] = i(context.Background(),
+				proto.Clone(req), createVolumeInfo, handler)
+		}(j)
+	}
+
+	// Give the duplicates time to start waiting for the original RPC.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for j := range reps {
+		assert.NoError(t, errs[j])
+		assert.True(t, proto.Equal(reps[0].(proto.Message),
+			reps[j].(proto.Message)))
+	}
+	assert.Equal(t, "vol-pvc-1",
+		reps[1].(*csi.CreateVolumeResponse).Volume.VolumeId)
+}
+
+func TestCachedResponse(t *testing.T) {
+	i := New(WithTTL(100 * time.Millisecond))
+
+	var calls int32
+	handler := func(_ context.Context, _ interface{}) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return &csi.DeleteVolumeResponse{}, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/csi.v1.Controller/DeleteVolume"}
+	req := &csi.DeleteVolumeRequest{VolumeId: "vol-1"}
+
+	for j := 0; j < 3; j++ {
+		rep, err := i(context.Background(), req, info, handler)
+		assert.NoError(t, err)
+		assert.IsType(t, &csi.DeleteVolumeResponse{}, rep)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// A different request for the same volume is not deduplicated.
+	_, err := i(context.Background(),
+		&csi.DeleteVolumeRequest{VolumeId: "vol-1",
+			Secrets: map[string]string{"user": "admin"}},
+		info, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// The response is handled again once the TTL expires.
+	assert.Eventually(t, func() bool {
+		_, err := i(context.Background(), req, info, handler)
+		assert.NoError(t, err)
+		return atomic.LoadInt32(&calls) == 3
+	}, time.Second, 50*time.Millisecond)
+}
+
+func TestOtherRPCInvalidates(t *testing.T) {
+	i := New()
+
+	var calls int32
+	handler := func(_ context.Context, _ interface{}) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return &csi.NodePublishVolumeResponse{}, nil
+	}
+	pubInfo := &grpc.UnaryServerInfo{FullMethod: "/csi.v1.Node/NodePublishVolume"}
+	unpubInfo := &grpc.UnaryServerInfo{FullMethod: "/csi.v1.Node/NodeUnpublishVolume"}
+	pub := &csi.NodePublishVolumeRequest{VolumeId: "vol-1", TargetPath: "/x"}
+	unpub := &csi.NodeUnpublishVolumeRequest{VolumeId: "vol-1", TargetPath: "/x"}
+
+	// Publish, unpublish, publish, unpublish are all handled.
+	for j := 0; j < 2; j++ {
+		_, err := i(context.Background(), pub, pubInfo, handler)
+		assert.NoError(t, err)
+		_, err = i(context.Background(), unpub, unpubInfo, handler)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+
+	// A repeated RPC still receives its own cached response.
+	_, err := i(context.Background(), unpub, unpubInfo, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+
+	// RPCs for other volumes do not drop the cached response.
+	_, err = i(context.Background(),
+		&csi.NodePublishVolumeRequest{VolumeId: "vol-10"}, pubInfo, handler)
+	assert.NoError(t, err)
+	_, err = i(context.Background(), unpub, unpubInfo, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(5), atomic.LoadInt32(&calls))
+}
+
+func TestFailedNotCached(t *testing.T) {
+	i := New()
+
+	var calls int32
+	handler := func(_ context.Context, _ interface{}) (interface{}, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return nil, status.Error(codes.Unavailable, "try again")
+		}
+		return &csi.CreateVolumeResponse{}, nil
+	}
+	req := &csi.CreateVolumeRequest{Name: "pvc-1"}
+
+	_, err := i(context.Background(), req, createVolumeInfo, handler)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	_, err = i(context.Background(), req, createVolumeInfo, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestPanicAbandons(t *testing.T) {
+	i := New()
+	req := &csi.CreateVolumeRequest{Name: "pvc-1"}
+
+	assert.Panics(t, func() {
+		_, _ = i(context.Background(), req, createVolumeInfo,
+			func(_ context.Context, _ interface{}) (interface{}, error) {
+				panic("boom")
+			})
+	})
+
+	// The key was abandoned so the next RPC is handled.
+	rep, err := i(context.Background(), req, createVolumeInfo,
+		func(_ context.Context, _ interface{}) (interface{}, error) {
+			return &csi.CreateVolumeResponse{}, nil
+		})
+	assert.NoError(t, err)
+	assert.NotNil(t, rep)
+}
+
+func TestWaitCanceled(t *testing.T) {
+	i := New()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	req := &csi.NodeStageVolumeRequest{VolumeId: "vol-1"}
+	info := &grpc.UnaryServerInfo{FullMethod: "/csi.v1.Node/NodeStageVolume"}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = i(context.Background(), req, info,
+			func(_ context.Context, _ interface{}) (interface{}, error) {
+				close(started)
+				<-release
+				return &csi.NodeStageVolumeResponse{}, nil
+			})
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := i(ctx, req, info,
+		func(_ context.Context, _ interface{}) (interface{}, error) {
+			return nil, errors.New("unexpected call")
+		})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	close(release)
+	<-done
+}
+
+func TestIgnoredRequests(t *testing.T) {
+	i := New()
+
+	var calls int32
+	handler := func(_ context.Context, _ interface{}) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return &csi.ProbeResponse{}, nil
+	}
+
+	for j := 0; j < 2; j++ {
+		_, err := i(context.Background(), &csi.ProbeRequest{},
+			&grpc.UnaryServerInfo{FullMethod: "/csi.v1.Identity/Probe"}, handler)
+		assert.NoError(t, err)
+		_, err = i(context.Background(), &csi.CreateVolumeRequest{},
+			createVolumeInfo, handler)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+}
+
+func TestRequestKey(t *testing.T) {
+	reqs := []interface{}{
+		&csi.CreateVolumeRequest{Name: "a"},
+		&csi.DeleteVolumeRequest{VolumeId: "a"},
+		&csi.ControllerPublishVolumeRequest{VolumeId: "a"},
+		&csi.ControllerUnpublishVolumeRequest{VolumeId: "a"},
+		&csi.ControllerExpandVolumeRequest{VolumeId: "a"},
+		&csi.CreateSnapshotRequest{Name: "a"},
+		&csi.DeleteSnapshotRequest{SnapshotId: "a"},
+		&csi.NodeStageVolumeRequest{VolumeId: "a"},
+		&csi.NodeUnstageVolumeRequest{VolumeId: "a"},
+		&csi.NodePublishVolumeRequest{VolumeId: "a"},
+		&csi.NodeUnpublishVolumeRequest{VolumeId: "a"},
+		&csi.NodeExpandVolumeRequest{VolumeId: "a"},
+	}
+	for _, req := range reqs {
+		prefix, key, ok := requestKey("/m", req)
+		assert.True(t, ok, "%T", req)
+		assert.Equal(t, "a/", prefix)
+		assert.Regexp(t, "^a/m/[0-9a-f]{64}$", key)
+	}
+
+	_, k1, _ := requestKey("/m", &csi.NodePublishVolumeRequest{
+		VolumeId: "a", TargetPath: "/x"})
+	_, k2, _ := requestKey("/m", &csi.NodePublishVolumeRequest{
+		VolumeId: "a", TargetPath: "/y"})
+	assert.NotEqual(t, k1, k2)
+
+	// A name or ID with a slash is a single segment of the key.
+	prefix, key, _ := requestKey("/m", &csi.DeleteVolumeRequest{
+		VolumeId: "a/b"})
+	assert.Equal(t, "a%2Fb/", prefix)
+	assert.Regexp(t, "^a%2Fb/m/[0-9a-f]{64}$", key)
+}
+
+func TestMarshalResponse(t *testing.T) {
+	_, err := marshalResponse("invalid")
+	assert.Error(t, err)
+
+	_, err = unmarshalResponse([]byte("invalid"))
+	assert.Error(t, err)
+
+	buf, err := marshalResponse(&csi.CreateVolumeResponse{
+		Volume: &csi.Volume{VolumeId: "vol-1"}})
+	assert.NoError(t, err)
+	rep, err := unmarshalResponse(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "vol-1", rep.(*csi.CreateVolumeResponse).Volume.VolumeId)
+}
@@ -0,0 +1,55 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// Store records the RPCs that are in flight and the responses of the
+// RPCs that recently completed.
+type Store interface {
+	// Begin claims the provided key for the calling RPC. If the key is
+	// not in flight and has no cached response then the key is marked as
+	// in flight and a true value is returned. The caller then owns the key
+	// and must call either Complete or Abandon.
+	//
+	// If another RPC with the same key is in flight then Begin waits for
+	// it to finish. If a response was cached for the key then the
+	// response is returned with a false value.
+	//
+	// An error is returned if the context is done before the key can be
+	// claimed.
+	Begin(ctx context.Context, key string) (rep []byte, owner bool, err error)
+
+	// Complete caches the response of the RPC that owns the provided key
+	// for the specified length of time. Any RPCs waiting for the key
+	// receive the cached response.
+	Complete(ctx context.Context, key string, rep []byte, ttl time.Duration) error
+
+	// Abandon releases the provided key without caching a response. The
+	// next RPC waiting for the key becomes its owner.
+	Abandon(ctx context.Context, key string) error
+
+	// Invalidate removes the cached responses of the keys that begin with
+	// the provided prefix, except for the excepted key. Keys that are in
+	// flight are not changed.
+	Invalidate(ctx context.Context, prefix, except string) error
+}
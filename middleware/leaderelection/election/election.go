@@ -0,0 +1,36 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package election
+
+import "context"
+
+// Elector elects the leader of a group of storage plug-in replicas.
+type Elector interface {
+	// Campaign campaigns for leadership with the provided identity until
+	// the context is done, at which point leadership is resigned. The
+	// identity is the address at which the other replicas may reach this
+	// replica. Campaign campaigns again if leadership is lost, and only
+	// returns an error if the election can no longer be held.
+	Campaign(ctx context.Context, id string) error
+
+	// Leader returns the identity of the current leader and whether or
+	// not this replica is the leader. The identity is empty if no leader
+	// is known.
+	Leader() (id string, leader bool)
+}
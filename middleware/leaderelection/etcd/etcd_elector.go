@@ -0,0 +1,191 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package etcd
+
+import (
+	"context"
+	"errors"
+	"path"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	etcd "go.etcd.io/etcd/client/v3"
+	etcdsync "go.etcd.io/etcd/client/v3/concurrency"
+
+	csictx "github.com/dell/gocsi/context"
+	"github.com/dell/gocsi/middleware/leaderelection/election"
+	serialvoletcd "github.com/dell/gocsi/middleware/serialvolume/etcd"
+)
+
+// DefaultTTL is the default length of time etcd will wait before
+// electing a new leader if the lease of the leader is not renewed.
+const DefaultTTL = 15 * time.Second
+
+// retryInterval is the length of time to wait before campaigning again
+// after the election failed.
+var retryInterval = time.Second
+
+// New returns a new etcd elector. If no configuration is provided then
+// the etcd client is configured from the serial volume access etcd
+// environment variables, ex. X_CSI_SERIAL_VOL_ACCESS_ETCD_ENDPOINTS.
+func New(
+	ctx context.Context,
+	domain string,
+	ttl time.Duration,
+	config *etcd.Config,
+) (election.Elector, error) {
+	fields := map[string]interface{}{}
+
+	if domain == "" {
+		domain = csictx.Getenv(ctx, EnvVarDomain)
+	}
+	domain = path.Join("/", domain)
+	fields["leaderelection.etcd.domain"] = domain
+
+	if ttl == 0 {
+		ttl, _ = time.ParseDuration(csictx.Getenv(ctx, EnvVarTTL))
+	}
+	if ttl < time.Second {
+		ttl = DefaultTTL
+	}
+	fields["leaderelection.etcd.ttl"] = ttl
+
+	if config == nil {
+		cfg, err := serialvoletcd.NewConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		config = &cfg
+	}
+
+	log.WithFields(fields).Info("creating leader election etcd elector")
+
+	client, err := etcd.New(*config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &elector{
+		client: client,
+		domain: domain,
+		ttl:    int(ttl.Seconds()),
+	}, nil
+}
+
+type elector struct {
+	client *etcd.Client
+	domain string
+	ttl    int
+
+	leaderL  sync.RWMutex
+	leaderID string
+	leader   bool
+}
+
+func (e *elector) Close() error {
+	return e.client.Close()
+}
+
+func (e *elector) Leader() (string, bool) {
+	e.leaderL.RLock()
+	defer e.leaderL.RUnlock()
+	return e.leaderID, e.leader
+}
+
+func (e *elector) setLeader(id string, leader bool) {
+	e.leaderL.Lock()
+	defer e.leaderL.Unlock()
+	e.leaderID, e.leader = id, leader
+}
+
+func (e *elector) Campaign(ctx context.Context, id string) error {
+	for {
+		err := e.campaign(ctx, id)
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.WithError(err).Warn("leader election failed; campaigning again")
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+// campaign campaigns for leadership with a new session. It returns when
+// the context is done or the session is lost. The leadership is resigned
+// when the session is closed.
+func (e *elector) campaign(ctx context.Context, id string) error {
+	// The session is not bound to the context so its lease may still be
+	// revoked once the context is done.
+	session, err := etcdsync.NewSession(e.client, etcdsync.WithTTL(e.ttl))
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-session.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	el := etcdsync.NewElection(session, e.domain)
+	observed := make(chan struct{})
+	go func() {
+		defer close(observed)
+		e.observe(ctx, el)
+	}()
+	defer func() {
+		cancel()
+		<-observed
+		e.setLeader("", false)
+	}()
+
+	if err := el.Campaign(ctx, id); err != nil {
+		return err
+	}
+	e.setLeader(id, true)
+	log.WithField("id", id).Info("elected leader")
+
+	<-ctx.Done()
+	log.WithField("id", id).Info("no longer leader")
+	return errors.New("session lost")
+}
+
+// observe records the identity of the leader until the context is done.
+func (e *elector) observe(ctx context.Context, el *etcdsync.Election) {
+	for rep := range el.Observe(ctx) {
+		if len(rep.Kvs) == 0 {
+			continue
+		}
+		id := string(rep.Kvs[0].Value)
+		e.leaderL.Lock()
+		if !e.leader {
+			e.leaderID = id
+		}
+		e.leaderL.Unlock()
+	}
+}
@@ -0,0 +1,139 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package etcd
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	etcd "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+
+	"github.com/dell/gocsi/middleware/leaderelection/election"
+)
+
+// The embedded etcd server listens on ports that differ from those used
+// by the other etcd tests so the packages may be tested at the same time.
+const (
+	clientURL = "http://127.0.0.1:2579"
+	peerURL   = "http://127.0.0.1:2580"
+)
+
+func TestMain(m *testing.M) {
+	log.SetLevel(log.InfoLevel)
+	retryInterval = 10 * time.Millisecond
+
+	dir, err := os.MkdirTemp("", "leaderelection-etcd")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	e, err := startEtcd(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	<-e.Server.ReadyNotify()
+
+	exitCode := m.Run()
+	e.Close()
+	os.RemoveAll(dir)
+	os.Exit(exitCode)
+}
+
+func startEtcd(dir string) (*embed.Etcd, error) {
+	cu, _ := url.Parse(clientURL)
+	pu, _ := url.Parse(peerURL)
+
+	cfg := embed.NewConfig()
+	cfg.Dir = dir
+	cfg.ListenClientUrls = []url.URL{*cu}
+	cfg.AdvertiseClientUrls = []url.URL{*cu}
+	cfg.ListenPeerUrls = []url.URL{*pu}
+	cfg.AdvertisePeerUrls = []url.URL{*pu}
+	cfg.InitialCluster = cfg.Name + "=" + peerURL
+	cfg.LogLevel = "error"
+
+	return embed.StartEtcd(cfg)
+}
+
+func newElector(t *testing.T) election.Elector {
+	e, err := New(context.TODO(), "/gocsi/"+t.Name(), 2*time.Second,
+		&etcd.Config{Endpoints: []string{clientURL}})
+	assert.NoError(t, err)
+	t.Cleanup(func() { e.(io.Closer).Close() })
+	return e
+}
+
+// campaign campaigns for leadership until the returned function is
+// called.
+func campaign(e election.Elector, id string) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = e.Campaign(ctx, id)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func TestCampaign(t *testing.T) {
+	e1, e2 := newElector(t), newElector(t)
+
+	stop1 := campaign(e1, "replica-1")
+	assert.Eventually(t, func() bool {
+		_, leader := e1.Leader()
+		return leader
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The other replica learns the identity of the leader.
+	stop2 := campaign(e2, "replica-2")
+	defer stop2()
+	assert.Eventually(t, func() bool {
+		id, leader := e2.Leader()
+		return id == "replica-1" && !leader
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The leadership is resigned when the campaign stops.
+	stop1()
+	id, leader := e1.Leader()
+	assert.Empty(t, id)
+	assert.False(t, leader)
+	assert.Eventually(t, func() bool {
+		id, leader := e2.Leader()
+		return id == "replica-2" && leader
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestNewDefaults(t *testing.T) {
+	e, err := New(context.TODO(), "", 0,
+		&etcd.Config{Endpoints: []string{clientURL}})
+	assert.NoError(t, err)
+	defer e.(io.Closer).Close()
+	assert.Equal(t, "/", e.(*elector).domain)
+	assert.Equal(t, int(DefaultTTL.Seconds()), e.(*elector).ttl)
+}
@@ -0,0 +1,30 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package etcd
+
+const (
+	// EnvVarDomain is the name of the environment variable that defines
+	// the etcd key prefix under which the leader is elected.
+	EnvVarDomain = "X_CSI_LEADER_ELECTION_ETCD_DOMAIN"
+
+	// EnvVarTTL is the name of the environment variable that defines the
+	// length of time etcd will wait before electing a new leader if the
+	// lease of the leader has not been renewed.
+	EnvVarTTL = "X_CSI_LEADER_ELECTION_ETCD_TTL"
+)
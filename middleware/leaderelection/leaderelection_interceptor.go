@@ -0,0 +1,269 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package leaderelection
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+	"sync"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/dell/gocsi/middleware/leaderelection/election"
+	"github.com/dell/gocsi/utils/rpcs"
+)
+
+// forwardedKey is the metadata key that marks the RPCs forwarded to the
+// leader. Its value is the forwarding token, and a forwarded RPC with a
+// matching token is never forwarded again. The key is removed from the
+// incoming metadata before the RPC is handled.
+const forwardedKey = "x-csi-leader-forwarded"
+
+// Option configures the interceptor.
+type Option func(*opts)
+
+type opts struct {
+	forward  bool
+	token    string
+	dialOpts []grpc.DialOption
+}
+
+// WithForwarding is an Option that forwards the RPCs received by a
+// replica that is not the leader to the leader instead of rejecting them.
+// The connection to the leader is created with the provided dial options,
+// or is insecure if none are provided.
+func WithForwarding(dialOpts ...grpc.DialOption) Option {
+	return func(o *opts) {
+		o.forward = true
+		o.dialOpts = dialOpts
+	}
+}
+
+// WithForwardingToken is an Option that sets the token shared by the
+// replicas to mark the RPCs they forward to the leader. Only an RPC
+// marked with this token is recognized as forwarded by another replica,
+// so a client cannot pass off its RPCs as forwarded. If no token is set
+// then no RPC is recognized as forwarded.
+func WithForwardingToken(token string) Option {
+	return func(o *opts) {
+		o.token = token
+	}
+}
+
+// rpcResponses are the constructors of the responses of the RPCs that
+// only the leader handles, keyed by their service and method names.
+var rpcResponses = map[string]func() interface{}{
+	"Controller/CreateVolume":              func() interface{} { return &csi.CreateVolumeResponse{} },
+	"Controller/DeleteVolume":              func() interface{} { return &csi.DeleteVolumeResponse{} },
+	"Controller/ControllerPublishVolume":   func() interface{} { return &csi.ControllerPublishVolumeResponse{} },
+	"Controller/ControllerUnpublishVolume": func() interface{} { return &csi.ControllerUnpublishVolumeResponse{} },
+	"Controller/ControllerExpandVolume":    func() interface{} { return &csi.ControllerExpandVolumeResponse{} },
+	"Controller/ControllerModifyVolume":    func() interface{} { return &csi.ControllerModifyVolumeResponse{} },
+	"Controller/CreateSnapshot":            func() interface{} { return &csi.CreateSnapshotResponse{} },
+	"Controller/DeleteSnapshot":            func() interface{} { return &csi.DeleteSnapshotResponse{} },
+
+	"GroupController/CreateVolumeGroupSnapshot": func() interface{} { return &csi.CreateVolumeGroupSnapshotResponse{} },
+	"GroupController/DeleteVolumeGroupSnapshot": func() interface{} { return &csi.DeleteVolumeGroupSnapshotResponse{} },
+}
+
+// New returns a new server-side, gRPC interceptor that only allows the
+// leader elected by the provided elector to handle the following RPCs:
+//
+//   - CreateVolume
+//   - DeleteVolume
+//   - ControllerPublishVolume
+//   - ControllerUnpublishVolume
+//   - ControllerExpandVolume
+//   - ControllerModifyVolume
+//   - CreateSnapshot
+//   - DeleteSnapshot
+//   - CreateVolumeGroupSnapshot
+//   - DeleteVolumeGroupSnapshot
+//
+// The other replicas reject these RPCs with codes.Unavailable, or
+// forward them to the leader if WithForwarding is set. The read-only
+// RPCs are handled by every replica.
+func New(e election.Elector, opts ...Option) grpc.UnaryServerInterceptor {
+	i := &interceptor{elector: e}
+
+	// Configure the interceptor's options.
+	for _, setOpt := range opts {
+		setOpt(&i.opts)
+	}
+
+	if len(i.opts.dialOpts) == 0 {
+		i.opts.dialOpts = []grpc.DialOption{
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		}
+	}
+
+	return i.handle
+}
+
+type interceptor struct {
+	opts    opts
+	elector election.Elector
+
+	// connL guards the connection to the leader, which is replaced
+	// when another leader is elected.
+	connL sync.Mutex
+	conn  *leaderConn
+}
+
+// leaderConn is a connection to a leader. The connection is closed once
+// it is replaced and no forwarded RPCs are using it.
+type leaderConn struct {
+	*grpc.ClientConn
+	id       string
+	refs     int
+	replaced bool
+}
+
+func (i *interceptor) handle(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	ctx, forwarded := i.removeForwarded(ctx)
+
+	_, service, method, err := rpcs.ParseMethod(info.FullMethod)
+	if err != nil {
+		return handler(ctx, req)
+	}
+	newRep, ok := rpcResponses[service+"/"+method]
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	id, leader := i.elector.Leader()
+	if leader {
+		return handler(ctx, req)
+	}
+	if id == "" {
+		return nil, status.Error(codes.Unavailable, "no leader elected")
+	}
+	if !i.opts.forward || forwarded {
+		return nil, status.Errorf(codes.Unavailable,
+			"not the leader: leader=%s", id)
+	}
+	return i.forward(ctx, id, info.FullMethod, req, newRep())
+}
+
+// forward invokes the RPC on the leader with the incoming metadata.
+func (i *interceptor) forward(
+	ctx context.Context,
+	id, method string,
+	req, rep interface{},
+) (interface{}, error) {
+	conn, err := i.dial(id)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable,
+			"failed to connect to leader: leader=%s: %v", id, err)
+	}
+	defer i.release(conn)
+
+	md := metadata.MD{}
+	if in, ok := metadata.FromIncomingContext(ctx); ok {
+		for k, v := range in {
+			if strings.HasPrefix(k, ":") || strings.HasPrefix(k, "grpc-") {
+				continue
+			}
+			md[k] = v
+		}
+	}
+	md.Set(forwardedKey, i.opts.token)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	log.WithFields(map[string]interface{}{
+		"method": method,
+		"leader": id,
+	}).Debug("forwarding rpc to leader")
+
+	if err := conn.Invoke(ctx, method, req, rep); err != nil {
+		return nil, err
+	}
+	return rep, nil
+}
+
+// dial returns the connection to the leader with the provided identity.
+// The connection to the previous leader is replaced. The caller must
+// release the returned connection.
+func (i *interceptor) dial(id string) (*leaderConn, error) {
+	i.connL.Lock()
+	defer i.connL.Unlock()
+
+	if i.conn == nil || i.conn.id != id {
+		cc, err := grpc.NewClient(id, i.opts.dialOpts...)
+		if err != nil {
+			return nil, err
+		}
+		if old := i.conn; old != nil {
+			old.replaced = true
+			if old.refs == 0 {
+				_ = old.Close()
+			}
+		}
+		i.conn = &leaderConn{ClientConn: cc, id: id}
+	}
+	i.conn.refs++
+	return i.conn, nil
+}
+
+// release releases the connection returned by dial. A replaced
+// connection is closed once it is released by its last user.
+func (i *interceptor) release(conn *leaderConn) {
+	i.connL.Lock()
+	defer i.connL.Unlock()
+
+	conn.refs--
+	if conn.replaced && conn.refs == 0 {
+		_ = conn.Close()
+	}
+}
+
+// removeForwarded removes the forwarded key from the incoming metadata
+// and returns whether the RPC was forwarded by another replica, which
+// is the case only if the key's value is the forwarding token.
+func (i *interceptor) removeForwarded(
+	ctx context.Context,
+) (context.Context, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx, false
+	}
+	v := md.Get(forwardedKey)
+	if len(v) == 0 {
+		return ctx, false
+	}
+	md = md.Copy()
+	md.Delete(forwardedKey)
+	ctx = metadata.NewIncomingContext(ctx, md)
+
+	forwarded := i.opts.token != "" && len(v) == 1 &&
+		subtle.ConstantTimeCompare([]byte(v[0]), []byte(i.opts.token)) == 1
+	return ctx, forwarded
+}
@@ -0,0 +1,200 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package leaderelection
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/dell/gocsi/mock/service"
+)
+
+const createVolume = "/csi.v1.Controller/CreateVolume"
+
+type fakeElector struct {
+	sync.Mutex
+	id     string
+	leader bool
+}
+
+func (e *fakeElector) Campaign(ctx context.Context, _ string) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (e *fakeElector) Leader() (string, bool) {
+	e.Lock()
+	defer e.Unlock()
+	return e.id, e.leader
+}
+
+func (e *fakeElector) set(id string, leader bool) {
+	e.Lock()
+	defer e.Unlock()
+	e.id, e.leader = id, leader
+}
+
+func call(
+	ctx context.Context, i grpc.UnaryServerInterceptor, method string,
+) (interface{}, error) {
+	return i(ctx, &csi.CreateVolumeRequest{Name: "vol"},
+		&grpc.UnaryServerInfo{FullMethod: method},
+		func(context.Context, interface{}) (interface{}, error) {
+			return &csi.CreateVolumeResponse{}, nil
+		})
+}
+
+func TestReject(t *testing.T) {
+	e := &fakeElector{}
+	i := New(e)
+	ctx := context.Background()
+
+	_, err := call(ctx, i, createVolume)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.ErrorContains(t, err, "no leader elected")
+
+	e.set("leader:5000", false)
+	_, err = call(ctx, i, createVolume)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.ErrorContains(t, err, "not the leader: leader=leader:5000")
+
+	// The read-only RPCs are handled by every replica.
+	_, err = call(ctx, i, "/csi.v1.Controller/ListVolumes")
+	assert.NoError(t, err)
+	_, err = call(ctx, i, "/csi.v1.Node/NodePublishVolume")
+	assert.NoError(t, err)
+
+	e.set("replica:5000", true)
+	_, err = call(ctx, i, createVolume)
+	assert.NoError(t, err)
+}
+
+func TestForward(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	// The leader records the metadata of the forwarded RPCs.
+	mds := make(chan metadata.MD, 1)
+	leader := grpc.NewServer(grpc.UnaryInterceptor(func(
+		ctx context.Context,
+		req interface{},
+		_ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		mds <- md
+		return handler(ctx, req)
+	}))
+	csi.RegisterControllerServer(leader, service.NewServer())
+	go func() { _ = leader.Serve(lis) }()
+	defer leader.Stop()
+
+	e := &fakeElector{id: lis.Addr().String()}
+	i := New(e, WithForwarding(), WithForwardingToken("secret"))
+
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs("csi.requestid", "1"))
+	rep, err := call(ctx, i, createVolume)
+	assert.NoError(t, err)
+	assert.Equal(t, "vol", rep.(*csi.CreateVolumeResponse).GetVolume().GetVolumeContext()["name"])
+	md := <-mds
+	assert.Equal(t, []string{"1"}, md.Get("csi.requestid"))
+	assert.Equal(t, []string{"secret"}, md.Get(forwardedKey))
+
+	// A forwarded RPC is not forwarded again.
+	ctx = metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(forwardedKey, "secret"))
+	_, err = call(ctx, i, createVolume)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// An RPC marked without the token is forwarded with the token.
+	ctx = metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(forwardedKey, "true"))
+	_, err = call(ctx, i, createVolume)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"secret"}, (<-mds).Get(forwardedKey))
+}
+
+func TestForwardedKeyRemoved(t *testing.T) {
+	handler := func(ctx context.Context, _ interface{}) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		assert.Empty(t, md.Get(forwardedKey))
+		assert.Equal(t, []string{"1"}, md.Get("csi.requestid"))
+		return &csi.CreateVolumeResponse{}, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: createVolume}
+
+	// The leader handles the forwarded RPC without the forwarded key.
+	e := &fakeElector{id: "replica:5000", leader: true}
+	i := New(e, WithForwarding(), WithForwardingToken("secret"))
+	for _, v := range []string{"secret", "true"} {
+		ctx := metadata.NewIncomingContext(context.Background(),
+			metadata.Pairs(forwardedKey, v, "csi.requestid", "1"))
+		_, err := i(ctx, &csi.CreateVolumeRequest{Name: "vol"}, info, handler)
+		assert.NoError(t, err)
+	}
+
+	// Without a token no RPC is recognized as forwarded.
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(forwardedKey, ""))
+	ctx, forwarded := (&interceptor{}).removeForwarded(ctx)
+	assert.False(t, forwarded)
+	md, _ := metadata.FromIncomingContext(ctx)
+	assert.Empty(t, md.Get(forwardedKey))
+}
+
+func TestDialReplacedConn(t *testing.T) {
+	ic := &interceptor{}
+	ic.opts.dialOpts = []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}
+
+	// The connection to the old leader is in use by a forwarded RPC.
+	old, err := ic.dial("leader-1:5000")
+	assert.NoError(t, err)
+	same, err := ic.dial("leader-1:5000")
+	assert.NoError(t, err)
+	assert.Same(t, old, same)
+	ic.release(same)
+
+	// A new leader replaces the connection, which stays open until the
+	// forwarded RPC releases it.
+	conn, err := ic.dial("leader-2:5000")
+	assert.NoError(t, err)
+	assert.NotSame(t, old, conn)
+	assert.NotEqual(t, connectivity.Shutdown, old.GetState())
+	ic.release(old)
+	assert.Equal(t, connectivity.Shutdown, old.GetState())
+
+	// The current connection is not closed when released.
+	ic.release(conn)
+	assert.NotEqual(t, connectivity.Shutdown, conn.GetState())
+	_ = conn.Close()
+}
@@ -27,8 +27,10 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
 
 	csictx "github.com/dell/gocsi/context"
 	"github.com/dell/gocsi/utils/middleware"
@@ -41,6 +43,8 @@ type opts struct {
 	reqw             io.Writer
 	repw             io.Writer
 	disableLogVolCtx bool
+	format           Format
+	redactionRules   []RedactionRule
 }
 
 // WithRequestLogging is a Option that enables request logging
@@ -138,28 +142,68 @@ func (s *interceptor) handle(
 		return next()
 	}
 
-	w := &bytes.Buffer{}
-	reqID, reqIDOK := csictx.GetRequestID(ctx)
-
 	// Print the request
-	if s.opts.reqw != nil {
-		fmt.Fprintf(w, "%s: ", method)
-		if reqIDOK {
-			fmt.Fprintf(w, "REQ %04d", reqID)
-		}
-		s.rprintReqOrRep(w, req)
-		fmt.Fprintln(s.opts.reqw, w.String())
-	}
-
-	w.Reset()
+	s.logRequest(ctx, method, req)
 
 	// Get the response.
+	start := time.Now()
 	rep, failed = next()
 
+	// Print the response
+	s.logResponse(ctx, method, rep, failed, time.Since(start))
+
+	return rep, failed
+}
+
+// logRequest writes the request to the request writer if request
+// logging is enabled.
+func (s *interceptor) logRequest(
+	ctx context.Context,
+	method string,
+	req interface{},
+) {
+	if s.opts.reqw == nil {
+		return
+	}
+
+	reqID, reqIDOK := csictx.GetRequestID(ctx)
+	if s.opts.format == JSON {
+		s.writeJSONRequest(s.opts.reqw, method, reqID, reqIDOK, req)
+		return
+	}
+
+	w := &bytes.Buffer{}
+	fmt.Fprintf(w, "%s: ", method)
+	if reqIDOK {
+		fmt.Fprintf(w, "REQ %04d", reqID)
+	}
+	s.rprintReqOrRep(w, req)
+	fmt.Fprintln(s.opts.reqw, w.String())
+}
+
+// logResponse writes the response and/or error to the response writer
+// if response logging is enabled. The duration is only written by the
+// JSON format, and is omitted when zero.
+func (s *interceptor) logResponse(
+	ctx context.Context,
+	method string,
+	rep interface{},
+	failed error,
+	duration time.Duration,
+) {
 	if s.opts.repw == nil {
-		return rep, failed
+		return
 	}
 
+	reqID, reqIDOK := csictx.GetRequestID(ctx)
+	if s.opts.format == JSON {
+		s.writeJSONResponse(
+			s.opts.repw, method, reqID, reqIDOK, rep, failed, duration)
+		return
+	}
+
+	w := &bytes.Buffer{}
+
 	// Print the response method name.
 	fmt.Fprintf(w, "%s: ", method)
 	if reqIDOK {
@@ -177,8 +221,6 @@ func (s *interceptor) handle(
 		s.rprintReqOrRep(w, rep)
 	}
 	fmt.Fprintln(s.opts.repw, w.String())
-
-	return rep, failed
 }
 
 var emptyValRX = regexp.MustCompile(
@@ -187,6 +229,9 @@ var emptyValRX = regexp.MustCompile(
 // rprintReqOrRep is used by the server-side interceptors that log
 // requests and responses.
 func (s *interceptor) rprintReqOrRep(w io.Writer, obj interface{}) {
+	if m, ok := obj.(proto.Message); ok && len(s.opts.redactionRules) > 0 {
+		obj = s.redactProto(m)
+	}
 	rv := reflect.ValueOf(obj).Elem()
 	tv := rv.Type()
 	nf := tv.NumField()
@@ -197,10 +242,7 @@ func (s *interceptor) rprintReqOrRep(w io.Writer, obj interface{}) {
 		if tv.Field(i).PkgPath != "" {
 			continue
 		}
-		if strings.Contains(name, "Secrets") {
-			continue
-		}
-		if s.opts.disableLogVolCtx && strings.Contains(name, "VolumeContext") {
+		if s.isRedacted(name) {
 			continue
 		}
 		sv := fmt.Sprintf("%v", rv.Field(i).Interface())
@@ -218,3 +260,15 @@ func (s *interceptor) rprintReqOrRep(w io.Writer, obj interface{}) {
 		fmt.Fprintf(w, "%s=%s", name, sv)
 	}
 }
+
+// isRedacted returns a flag indicating whether or not the field with
+// the provided name is omitted from the logs. The name may be either
+// a Go field name, ex. VolumeContext, or a protobuf field name, ex.
+// volume_context.
+func (s *interceptor) isRedacted(name string) bool {
+	name = normalizeFieldName(name)
+	if strings.Contains(name, "secrets") {
+		return true
+	}
+	return s.opts.disableLogVolCtx && strings.Contains(name, "volumecontext")
+}
@@ -0,0 +1,194 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/dell/gocsi/utils/middleware"
+)
+
+// Format is the format in which the logging interceptor writes
+// requests and responses.
+type Format int
+
+const (
+	// Text writes requests and responses as a single line of
+	// "Name=value" pairs. This is the default format.
+	Text Format = iota
+
+	// JSON writes each request and response as a single line JSON
+	// object. The message is marshaled with protojson and the method,
+	// request ID, duration, and gRPC status code are included as
+	// fields.
+	JSON
+)
+
+// String returns the name of the format.
+func (f Format) String() string {
+	switch f {
+	case Text:
+		return "text"
+	case JSON:
+		return "json"
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// ParseFormat returns the Format with the provided name. The name is
+// not case sensitive.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "text":
+		return Text, nil
+	case "json":
+		return JSON, nil
+	}
+	return Text, fmt.Errorf("invalid log format: %s", name)
+}
+
+// WithFormat is an Option that sets the format in which the logging
+// interceptor writes requests and responses.
+func WithFormat(f Format) Option {
+	return func(o *opts) {
+		o.format = f
+	}
+}
+
+// jsonRecord is a single request or response written by the logging
+// interceptor when the JSON format is used.
+type jsonRecord struct {
+	Method          string          `json:"method"`
+	Type            string          `json:"type"`
+	RequestID       *uint64         `json:"request_id,omitempty"`
+	DurationSeconds *float64        `json:"duration_seconds,omitempty"`
+	Code            string          `json:"code,omitempty"`
+	Error           string          `json:"error,omitempty"`
+	Request         json.RawMessage `json:"request,omitempty"`
+	Response        json.RawMessage `json:"response,omitempty"`
+}
+
+func (s *interceptor) writeJSONRequest(
+	w io.Writer,
+	method string,
+	reqID uint64, reqIDOK bool,
+	req interface{},
+) {
+	rec := &jsonRecord{Method: method, Type: "request"}
+	if reqIDOK {
+		rec.RequestID = &reqID
+	}
+	rec.Request = s.marshalJSON(req)
+	writeJSONRecord(w, rec)
+}
+
+func (s *interceptor) writeJSONResponse(
+	w io.Writer,
+	method string,
+	reqID uint64, reqIDOK bool,
+	rep interface{},
+	failed error,
+	duration time.Duration,
+) {
+	rec := &jsonRecord{
+		Method: method,
+		Type:   "response",
+		Code:   status.Code(failed).String(),
+	}
+	if reqIDOK {
+		rec.RequestID = &reqID
+	}
+	if duration > 0 {
+		d := duration.Seconds()
+		rec.DurationSeconds = &d
+	}
+	if failed != nil {
+		rec.Error = failed.Error()
+	}
+	if !middleware.IsNilResponse(rep) {
+		rec.Response = s.marshalJSON(rep)
+	}
+	writeJSONRecord(w, rec)
+}
+
+func writeJSONRecord(w io.Writer, rec *jsonRecord) {
+	buf, err := json.Marshal(rec)
+	if err != nil {
+		fmt.Fprintf(w, "{\"method\":%q,\"error\":%q}\n", rec.Method, err.Error())
+		return
+	}
+	fmt.Fprintln(w, string(buf))
+}
+
+// marshalJSON returns the JSON encoding of a request or response with
+// the redacted fields removed. Protobuf messages are marshaled with
+// protojson. Any other object is marshaled from its exported fields.
+func (s *interceptor) marshalJSON(obj interface{}) json.RawMessage {
+	var (
+		buf []byte
+		err error
+	)
+	if m, ok := obj.(proto.Message); ok {
+		buf, err = protojson.Marshal(s.redactProto(m))
+	} else {
+		buf, err = json.Marshal(s.redactFields(obj))
+	}
+	if err != nil {
+		buf, _ = json.Marshal(err.Error())
+		return buf
+	}
+
+	// The output of protojson is deliberately unstable and may contain
+	// extra whitespace, so it is compacted to keep each record on a
+	// single line.
+	w := &bytes.Buffer{}
+	if err := json.Compact(w, buf); err != nil {
+		return buf
+	}
+	return w.Bytes()
+}
+
+// redactFields returns a map of the object's exported fields, less the
+// redacted fields. Objects that are not structs are returned as is.
+func (s *interceptor) redactFields(obj interface{}) interface{} {
+	rv := reflect.Indirect(reflect.ValueOf(obj))
+	if rv.Kind() != reflect.Struct {
+		return obj
+	}
+	tv := rv.Type()
+	fields := map[string]interface{}{}
+	for i := 0; i < tv.NumField(); i++ {
+		name := tv.Field(i).Name
+		if tv.Field(i).PkgPath != "" || s.isRedacted(name) {
+			continue
+		}
+		fields[name] = rv.Field(i).Interface()
+	}
+	return fields
+}
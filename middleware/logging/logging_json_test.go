@@ -0,0 +1,176 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	csictx "github.com/dell/gocsi/context"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Format
+		wantErr bool
+	}{
+		{"", Text, false},
+		{"text", Text, false},
+		{"JSON", JSON, false},
+		{" json ", JSON, false},
+		{"xml", Text, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ParseFormat(tt.name)
+			assert.Equal(t, tt.want, f)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+	assert.Equal(t, "text", Text.String())
+	assert.Equal(t, "json", JSON.String())
+	assert.Equal(t, "Format(9)", Format(9).String())
+}
+
+func decodeRecords(t *testing.T, w *bytes.Buffer) []map[string]interface{} {
+	var recs []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(w.String()), "\n") {
+		rec := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &rec), line)
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+func TestServerLoggerJSON(t *testing.T) {
+	w := &bytes.Buffer{}
+	sLogger := NewServerLogger(
+		WithFormat(JSON),
+		WithRequestLogging(w),
+		WithResponseLogging(w),
+		WithDisableLogVolumeContext())
+
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(csictx.RequestIDKey, "42"))
+	req := &csi.NodePublishVolumeRequest{
+		VolumeId:   "vol-1",
+		TargetPath: "/mnt/vol-1",
+		VolumeCapability: &csi.VolumeCapability{
+			AccessType: &csi.VolumeCapability_Mount{
+				Mount: &csi.VolumeCapability_MountVolume{FsType: "ext4"},
+			},
+		},
+		Secrets:       map[string]string{"password": "hunter2"},
+		VolumeContext: map[string]string{"key": "value"},
+	}
+
+	_, err := sLogger(ctx, req,
+		&grpc.UnaryServerInfo{FullMethod: "/csi.v1.Node/NodePublishVolume"},
+		func(_ context.Context, _ interface{}) (interface{}, error) {
+			return &csi.NodePublishVolumeResponse{}, nil
+		})
+	assert.NoError(t, err)
+
+	assert.NotContains(t, w.String(), "hunter2")
+	assert.NotContains(t, w.String(), "volumeContext")
+
+	recs := decodeRecords(t, w)
+	assert.Len(t, recs, 2)
+
+	assert.Equal(t, "/csi.v1.Node/NodePublishVolume", recs[0]["method"])
+	assert.Equal(t, "request", recs[0]["type"])
+	assert.Equal(t, float64(42), recs[0]["request_id"])
+	assert.NotContains(t, recs[0], "code")
+	assert.Equal(t, map[string]interface{}{
+		"volumeId":   "vol-1",
+		"targetPath": "/mnt/vol-1",
+		"volumeCapability": map[string]interface{}{
+			"mount": map[string]interface{}{"fsType": "ext4"},
+		},
+	}, recs[0]["request"])
+
+	assert.Equal(t, "response", recs[1]["type"])
+	assert.Equal(t, float64(42), recs[1]["request_id"])
+	assert.Equal(t, "OK", recs[1]["code"])
+	assert.Contains(t, recs[1], "duration_seconds")
+	assert.Equal(t, map[string]interface{}{}, recs[1]["response"])
+
+	// The original request is not modified by redaction.
+	assert.Equal(t, "hunter2", req.Secrets["password"])
+}
+
+func TestServerLoggerJSONError(t *testing.T) {
+	w := &bytes.Buffer{}
+	sLogger := NewServerLogger(WithFormat(JSON), WithResponseLogging(w))
+
+	_, err := sLogger(context.Background(),
+		&csi.DeleteVolumeRequest{VolumeId: "vol-1"},
+		&grpc.UnaryServerInfo{FullMethod: "/csi.v1.Controller/DeleteVolume"},
+		func(_ context.Context, _ interface{}) (interface{}, error) {
+			return nil, status.Error(codes.NotFound, "no such volume")
+		})
+	assert.Error(t, err)
+
+	recs := decodeRecords(t, w)
+	assert.Len(t, recs, 1)
+	assert.Equal(t, "NotFound", recs[0]["code"])
+	assert.Equal(t, "rpc error: code = NotFound desc = no such volume",
+		recs[0]["error"])
+	assert.NotContains(t, recs[0], "request_id")
+	assert.NotContains(t, recs[0], "response")
+}
+
+func TestMarshalJSONNonProto(t *testing.T) {
+	i := newLoggingInterceptor(WithFormat(JSON))
+	buf := i.marshalJSON(&struct {
+		Name    string
+		Secrets string
+		hidden  string
+	}{Name: "name", Secrets: "secret", hidden: "hidden"})
+	assert.JSONEq(t, `{"Name":"name"}`, string(buf))
+
+	assert.JSONEq(t, `"value"`, string(i.marshalJSON("value")))
+}
+
+func TestIsRedacted(t *testing.T) {
+	i := newLoggingInterceptor()
+	assert.True(t, i.isRedacted("Secrets"))
+	assert.True(t, i.isRedacted("secrets"))
+	assert.False(t, i.isRedacted("VolumeContext"))
+	assert.False(t, i.isRedacted("volume_id"))
+
+	i = newLoggingInterceptor(WithDisableLogVolumeContext())
+	assert.True(t, i.isRedacted("VolumeContext"))
+	assert.True(t, i.isRedacted("volume_context"))
+}
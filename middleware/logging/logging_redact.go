@@ -0,0 +1,312 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package logging
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// redactedValue replaces the values masked by redaction rules.
+const redactedValue = "******"
+
+// RedactionRule describes the values of a request or response that are
+// masked by the logging interceptor. A rule has one of three forms:
+//
+//	path         masks the value of the field at the path
+//	~regexp      masks the values of all map entries with matching keys
+//	path~regexp  masks the values of the entries of the map at the path
+//	             with matching keys
+//
+// A path is a dot-separated list of field names, ex.
+// VolumeCapability.Mount.MountFlags. Field names are not case sensitive
+// and may be given as Go field names, ex. VolumeContext, or protobuf
+// field names, ex. volume_context. A path segment that follows a map
+// field is an exact map key, ex. Parameters.chap_password.
+//
+// String and bytes values are masked. Values of any other type are
+// omitted. Redaction rules only apply to protobuf messages.
+type RedactionRule struct {
+	path []string
+	key  *regexp.Regexp
+	text string
+}
+
+// String returns the text from which the rule was parsed.
+func (r RedactionRule) String() string {
+	return r.text
+}
+
+// ParseRedactionRule parses a single redaction rule.
+func ParseRedactionRule(text string) (RedactionRule, error) {
+	r := RedactionRule{text: text}
+	path := text
+	if i := strings.Index(text, "~"); i >= 0 {
+		key, err := regexp.Compile(text[i+1:])
+		if err != nil {
+			return r, fmt.Errorf("invalid redaction rule: %s: %w", text, err)
+		}
+		path, r.key = text[:i], key
+	}
+	if path != "" {
+		r.path = strings.Split(path, ".")
+		for _, s := range r.path {
+			if s == "" {
+				return r, fmt.Errorf("invalid redaction rule: %s", text)
+			}
+		}
+	}
+	if r.path == nil && r.key == nil {
+		return r, fmt.Errorf("invalid redaction rule: %s", text)
+	}
+	return r, nil
+}
+
+// ParseRedactionRules parses a whitespace-separated list of redaction
+// rules.
+func ParseRedactionRules(text string) ([]RedactionRule, error) {
+	var rules []RedactionRule
+	for _, s := range strings.Fields(text) {
+		r, err := ParseRedactionRule(s)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// WithRedactionRules is an Option that masks the values described by
+// the provided rules in both the logged requests and responses.
+func WithRedactionRules(rules ...RedactionRule) Option {
+	return func(o *opts) {
+		o.redactionRules = append(o.redactionRules, rules...)
+	}
+}
+
+// apply masks the values of the message described by the rule.
+func (r RedactionRule) apply(m protoreflect.Message) {
+	if len(r.path) == 0 {
+		redactKeys(m, r.key)
+		return
+	}
+	r.walk(m, r.path)
+}
+
+func (r RedactionRule) walk(m protoreflect.Message, path []string) {
+	fd := findField(m, path[0])
+	if fd == nil || !m.Has(fd) {
+		return
+	}
+
+	// The end of the path has been reached.
+	if len(path) == 1 {
+		switch {
+		case r.key == nil:
+			redactField(m, fd)
+		case fd.IsMap():
+			redactMapKeys(m.Mutable(fd).Map(), fd, r.key)
+		}
+		return
+	}
+
+	switch {
+	case fd.IsMap():
+		if fd.MapKey().Kind() != protoreflect.StringKind {
+			return
+		}
+		mp := m.Mutable(fd).Map()
+		k := protoreflect.ValueOfString(path[1]).MapKey()
+		if !mp.Has(k) {
+			return
+		}
+		if len(path) == 2 {
+			if r.key == nil {
+				redactMapValue(mp, k, fd.MapValue())
+			}
+			return
+		}
+		if fd.MapValue().Message() != nil {
+			r.walk(mp.Mutable(k).Message(), path[2:])
+		}
+	case fd.IsList():
+		if fd.Message() == nil {
+			return
+		}
+		l := m.Mutable(fd).List()
+		for i := 0; i < l.Len(); i++ {
+			r.walk(l.Get(i).Message(), path[1:])
+		}
+	case fd.Message() != nil:
+		r.walk(m.Mutable(fd).Message(), path[1:])
+	}
+}
+
+// findField returns the message's field with the provided name, or nil.
+func findField(
+	m protoreflect.Message,
+	name string,
+) protoreflect.FieldDescriptor {
+	name = normalizeFieldName(name)
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if normalizeFieldName(string(fields.Get(i).Name())) == name {
+			return fields.Get(i)
+		}
+	}
+	return nil
+}
+
+// normalizeFieldName returns the name in lower case without any
+// underscores so that Go and protobuf field names are equal.
+func normalizeFieldName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// redactField masks the value of the field. The elements of lists and
+// the values of maps are masked individually.
+func redactField(m protoreflect.Message, fd protoreflect.FieldDescriptor) {
+	switch {
+	case fd.IsMap():
+		mp := m.Mutable(fd).Map()
+		var keys []protoreflect.MapKey
+		mp.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+			keys = append(keys, k)
+			return true
+		})
+		for _, k := range keys {
+			redactMapValue(mp, k, fd.MapValue())
+		}
+	case fd.IsList():
+		v, ok := redactedScalar(fd)
+		if !ok {
+			m.Clear(fd)
+			return
+		}
+		l := m.Mutable(fd).List()
+		for i := 0; i < l.Len(); i++ {
+			l.Set(i, v)
+		}
+	default:
+		if v, ok := redactedScalar(fd); ok {
+			m.Set(fd, v)
+			return
+		}
+		m.Clear(fd)
+	}
+}
+
+// redactMapValue masks the value of the map entry with the provided key.
+func redactMapValue(
+	mp protoreflect.Map,
+	k protoreflect.MapKey,
+	fd protoreflect.FieldDescriptor,
+) {
+	if v, ok := redactedScalar(fd); ok {
+		mp.Set(k, v)
+		return
+	}
+	mp.Clear(k)
+}
+
+// redactMapKeys masks the values of the map's entries whose keys match
+// the provided expression.
+func redactMapKeys(
+	mp protoreflect.Map,
+	fd protoreflect.FieldDescriptor,
+	rx *regexp.Regexp,
+) {
+	if fd.MapKey().Kind() != protoreflect.StringKind {
+		return
+	}
+	var keys []protoreflect.MapKey
+	mp.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+		if rx.MatchString(k.String()) {
+			keys = append(keys, k)
+		}
+		return true
+	})
+	for _, k := range keys {
+		redactMapValue(mp, k, fd.MapValue())
+	}
+}
+
+// redactKeys masks the values of the entries with keys that match the
+// provided expression in all of the message's maps, including the maps
+// of nested messages.
+func redactKeys(m protoreflect.Message, rx *regexp.Regexp) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsMap():
+			redactMapKeys(v.Map(), fd, rx)
+			if fd.MapValue().Message() != nil {
+				v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+					redactKeys(mv.Message(), rx)
+					return true
+				})
+			}
+		case fd.IsList():
+			if fd.Message() != nil {
+				for i := 0; i < v.List().Len(); i++ {
+					redactKeys(v.List().Get(i).Message(), rx)
+				}
+			}
+		case fd.Message() != nil:
+			redactKeys(v.Message(), rx)
+		}
+		return true
+	})
+}
+
+// redactedScalar returns the masked value for fields of the string and
+// bytes kinds.
+func redactedScalar(fd protoreflect.FieldDescriptor) (protoreflect.Value, bool) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(redactedValue), true
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes([]byte(redactedValue)), true
+	}
+	return protoreflect.Value{}, false
+}
+
+// redactProto returns a copy of the message with the omitted fields
+// cleared and the values described by the redaction rules masked.
+func (s *interceptor) redactProto(m proto.Message) proto.Message {
+	m = proto.Clone(m)
+	rm := m.ProtoReflect()
+	var omitted []protoreflect.FieldDescriptor
+	rm.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if s.isRedacted(string(fd.Name())) {
+			omitted = append(omitted, fd)
+		}
+		return true
+	})
+	for _, fd := range omitted {
+		rm.Clear(fd)
+	}
+	for _, r := range s.opts.redactionRules {
+		r.apply(rm)
+	}
+	return m
+}
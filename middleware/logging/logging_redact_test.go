@@ -0,0 +1,222 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestParseRedactionRule(t *testing.T) {
+	tests := []struct {
+		text    string
+		path    []string
+		key     string
+		wantErr bool
+	}{
+		{text: "VolumeContext", path: []string{"VolumeContext"}},
+		{text: "Parameters.chap_password", path: []string{"Parameters", "chap_password"}},
+		{text: "~(?i)password", key: "(?i)password"},
+		{text: "PublishContext~token", path: []string{"PublishContext"}, key: "token"},
+		{text: "~", key: ""},
+		{text: "", wantErr: true},
+		{text: "Parameters..key", wantErr: true},
+		{text: "~[", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			r, err := ParseRedactionRule(tt.text)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.text, r.String())
+			assert.Equal(t, tt.path, r.path)
+			if r.key != nil {
+				assert.Equal(t, tt.key, r.key.String())
+			}
+		})
+	}
+}
+
+func TestParseRedactionRules(t *testing.T) {
+	rules, err := ParseRedactionRules(" Parameters.a \n~b\tc~d ")
+	assert.NoError(t, err)
+	assert.Len(t, rules, 3)
+
+	rules, err = ParseRedactionRules("")
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+
+	_, err = ParseRedactionRules("a ~[")
+	assert.Error(t, err)
+}
+
+func mustParseRules(t *testing.T, text string) []RedactionRule {
+	rules, err := ParseRedactionRules(text)
+	assert.NoError(t, err)
+	return rules
+}
+
+func TestRedactProto(t *testing.T) {
+	newReq := func() *csi.CreateVolumeRequest {
+		return &csi.CreateVolumeRequest{
+			Name: "pvc-1",
+			Parameters: map[string]string{
+				"chap_password": "hunter2",
+				"arrayToken":    "abc",
+				"pool":          "gold",
+			},
+			VolumeCapabilities: []*csi.VolumeCapability{
+				{
+					AccessType: &csi.VolumeCapability_Mount{
+						Mount: &csi.VolumeCapability_MountVolume{
+							FsType:     "ext4",
+							MountFlags: []string{"user=admin", "pass=secret"},
+						},
+					},
+				},
+			},
+			MutableParameters: map[string]string{"token": "xyz"},
+			Secrets:           map[string]string{"password": "hunter2"},
+			CapacityRange:     &csi.CapacityRange{RequiredBytes: 10},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		rules string
+		want  func(*csi.CreateVolumeRequest)
+	}{
+		{
+			name:  "no rules",
+			rules: "",
+			want:  func(*csi.CreateVolumeRequest) {},
+		},
+		{
+			name:  "map key path",
+			rules: "Parameters.chap_password",
+			want: func(r *csi.CreateVolumeRequest) {
+				r.Parameters["chap_password"] = redactedValue
+			},
+		},
+		{
+			name:  "missing map key path",
+			rules: "parameters.missing Missing.field",
+			want:  func(*csi.CreateVolumeRequest) {},
+		},
+		{
+			name:  "string field",
+			rules: "name",
+			want: func(r *csi.CreateVolumeRequest) {
+				r.Name = redactedValue
+			},
+		},
+		{
+			name:  "whole map",
+			rules: "mutable_parameters",
+			want: func(r *csi.CreateVolumeRequest) {
+				r.MutableParameters["token"] = redactedValue
+			},
+		},
+		{
+			name:  "nested list field",
+			rules: "VolumeCapabilities.Mount.MountFlags",
+			want: func(r *csi.CreateVolumeRequest) {
+				r.VolumeCapabilities[0].GetMount().MountFlags = []string{
+					redactedValue, redactedValue,
+				}
+			},
+		},
+		{
+			name:  "non-string field is omitted",
+			rules: "CapacityRange.RequiredBytes",
+			want: func(r *csi.CreateVolumeRequest) {
+				r.CapacityRange.RequiredBytes = 0
+			},
+		},
+		{
+			name:  "key regexp in all maps",
+			rules: "~(?i)(password|token)",
+			want: func(r *csi.CreateVolumeRequest) {
+				r.Parameters["chap_password"] = redactedValue
+				r.Parameters["arrayToken"] = redactedValue
+				r.MutableParameters["token"] = redactedValue
+			},
+		},
+		{
+			name:  "key regexp in map at path",
+			rules: "Parameters~(?i)token",
+			want: func(r *csi.CreateVolumeRequest) {
+				r.Parameters["arrayToken"] = redactedValue
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := newLoggingInterceptor(
+				WithRedactionRules(mustParseRules(t, tt.rules)...))
+
+			req := newReq()
+			got := i.redactProto(req)
+
+			// The secrets are always omitted.
+			want := newReq()
+			want.Secrets = nil
+			tt.want(want)
+			assert.True(t, proto.Equal(want, got), "%v", got)
+
+			// The original request is not modified.
+			assert.True(t, proto.Equal(newReq(), req))
+		})
+	}
+}
+
+func TestServerLoggerRedaction(t *testing.T) {
+	req := &csi.NodeStageVolumeRequest{
+		VolumeId:       "vol-1",
+		PublishContext: map[string]string{"chap_secret": "hunter2"},
+	}
+	handler := func(_ context.Context, _ interface{}) (interface{}, error) {
+		return &csi.NodeStageVolumeResponse{}, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/csi.v1.Node/NodeStageVolume"}
+
+	for _, f := range []Format{Text, JSON} {
+		t.Run(f.String(), func(t *testing.T) {
+			w := &bytes.Buffer{}
+			sLogger := NewServerLogger(
+				WithFormat(f),
+				WithRequestLogging(w),
+				WithRedactionRules(mustParseRules(t, "~secret")...))
+			_, err := sLogger(context.Background(), req, info, handler)
+			assert.NoError(t, err)
+			assert.NotContains(t, w.String(), "hunter2")
+			assert.Contains(t, w.String(), redactedValue)
+			assert.Contains(t, w.String(), "vol-1")
+		})
+	}
+}
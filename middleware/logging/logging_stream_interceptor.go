@@ -0,0 +1,136 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package logging
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// NewStreamServerLogger returns a new StreamServerInterceptor that can be
+// configured to log the messages received from and sent to a stream.
+func NewStreamServerLogger(
+	opts ...Option,
+) grpc.StreamServerInterceptor {
+	return newLoggingInterceptor(opts...).handleStreamServer
+}
+
+// NewStreamClientLogger provides a StreamClientInterceptor that can be
+// configured to log the messages sent to and received from a stream.
+func NewStreamClientLogger(
+	opts ...Option,
+) grpc.StreamClientInterceptor {
+	return newLoggingInterceptor(opts...).handleStreamClient
+}
+
+func (s *interceptor) handleStreamServer(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	start := time.Now()
+	err := handler(srv, &serverStream{
+		ServerStream: ss,
+		i:            s,
+		method:       info.FullMethod,
+		start:        start,
+	})
+
+	// A successful stream has already had each of its responses logged.
+	if err != nil {
+		s.logResponse(ss.Context(), info.FullMethod, nil, err, time.Since(start))
+	}
+	return err
+}
+
+func (s *interceptor) handleStreamClient(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	start := time.Now()
+	cs, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		s.logResponse(ctx, method, nil, err, time.Since(start))
+		return nil, err
+	}
+	return &clientStream{
+		ClientStream: cs,
+		i:            s,
+		method:       method,
+		start:        start,
+	}, nil
+}
+
+// serverStream logs each message received by the server as a request
+// and each message sent by the server as a response. The duration of
+// each response is measured from the start of the stream.
+type serverStream struct {
+	grpc.ServerStream
+	i      *interceptor
+	method string
+	start  time.Time
+}
+
+func (ss *serverStream) RecvMsg(m interface{}) error {
+	if err := ss.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	ss.i.logRequest(ss.Context(), ss.method, m)
+	return nil
+}
+
+func (ss *serverStream) SendMsg(m interface{}) error {
+	ss.i.logResponse(ss.Context(), ss.method, m, nil, time.Since(ss.start))
+	return ss.ServerStream.SendMsg(m)
+}
+
+// clientStream logs each message sent by the client as a request
+// and each message received by the client as a response. The duration
+// of each response is measured from the start of the stream.
+type clientStream struct {
+	grpc.ClientStream
+	i      *interceptor
+	method string
+	start  time.Time
+}
+
+func (cs *clientStream) SendMsg(m interface{}) error {
+	cs.i.logRequest(cs.Context(), cs.method, m)
+	return cs.ClientStream.SendMsg(m)
+}
+
+func (cs *clientStream) RecvMsg(m interface{}) error {
+	err := cs.ClientStream.RecvMsg(m)
+	switch {
+	case err == nil:
+		cs.i.logResponse(cs.Context(), cs.method, m, nil, time.Since(cs.start))
+	case !errors.Is(err, io.EOF):
+		cs.i.logResponse(cs.Context(), cs.method, nil, err, time.Since(cs.start))
+	}
+	return err
+}
@@ -0,0 +1,160 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	csictx "github.com/dell/gocsi/context"
+)
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	recv []interface{}
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testServerStream) RecvMsg(m interface{}) error {
+	if len(s.recv) == 0 {
+		return io.EOF
+	}
+	proto.Merge(m.(proto.Message), s.recv[0].(proto.Message))
+	s.recv = s.recv[1:]
+	return nil
+}
+
+func (s *testServerStream) SendMsg(_ interface{}) error {
+	return nil
+}
+
+type testClientStream struct {
+	grpc.ClientStream
+	ctx     context.Context
+	recvErr error
+}
+
+func (s *testClientStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testClientStream) SendMsg(_ interface{}) error {
+	return nil
+}
+
+func (s *testClientStream) RecvMsg(_ interface{}) error {
+	return s.recvErr
+}
+
+func TestStreamServerLogger(t *testing.T) {
+	reqw, repw := &bytes.Buffer{}, &bytes.Buffer{}
+	i := NewStreamServerLogger(
+		WithRequestLogging(reqw), WithResponseLogging(repw))
+
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(csictx.RequestIDKey, "7"))
+	ss := &testServerStream{
+		ctx: ctx,
+		recv: []interface{}{
+			&csi.NodeGetVolumeStatsRequest{VolumeId: "vol-1"},
+		},
+	}
+	info := &grpc.StreamServerInfo{FullMethod: "/ext.Service/Watch"}
+
+	err := i(nil, ss, info, func(_ interface{}, stream grpc.ServerStream) error {
+		req := &csi.NodeGetVolumeStatsRequest{}
+		if err := stream.RecvMsg(req); err != nil {
+			return err
+		}
+		return stream.SendMsg(&csi.NodeGetVolumeStatsResponse{
+			Usage: []*csi.VolumeUsage{{Total: 1}},
+		})
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, reqw.String(), "/ext.Service/Watch: REQ 0007: VolumeId=vol-1")
+	assert.Contains(t, repw.String(), "/ext.Service/Watch: REP 0007: Usage=")
+
+	// A failed stream logs its error as a response.
+	repw.Reset()
+	err = i(nil, ss, info, func(_ interface{}, _ grpc.ServerStream) error {
+		return errors.New("stream failed")
+	})
+	assert.Error(t, err)
+	assert.Contains(t, repw.String(), "REP 0007: stream failed")
+}
+
+func TestStreamClientLogger(t *testing.T) {
+	reqw, repw := &bytes.Buffer{}, &bytes.Buffer{}
+	i := NewStreamClientLogger(
+		WithRequestLogging(reqw), WithResponseLogging(repw))
+
+	ctx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs(csictx.RequestIDKey, "9"))
+
+	cs, err := i(ctx, &grpc.StreamDesc{}, nil, "/ext.Service/Watch",
+		func(
+			ctx context.Context,
+			_ *grpc.StreamDesc,
+			_ *grpc.ClientConn,
+			_ string,
+			_ ...grpc.CallOption,
+		) (grpc.ClientStream, error) {
+			return &testClientStream{ctx: ctx}, nil
+		})
+	assert.NoError(t, err)
+
+	assert.NoError(t, cs.SendMsg(&csi.NodeGetVolumeStatsRequest{VolumeId: "vol-2"}))
+	assert.Contains(t, reqw.String(), "REQ 0009: VolumeId=vol-2")
+
+	assert.NoError(t, cs.RecvMsg(&csi.NodeGetVolumeStatsResponse{}))
+	assert.Contains(t, repw.String(), "REP 0009")
+
+	// io.EOF marks the end of the stream and is not logged.
+	repw.Reset()
+	cs.(*clientStream).ClientStream.(*testClientStream).recvErr = io.EOF
+	assert.ErrorIs(t, cs.RecvMsg(&csi.NodeGetVolumeStatsResponse{}), io.EOF)
+	assert.Empty(t, repw.String())
+
+	// A failure to create the stream is logged as a response.
+	_, err = i(ctx, &grpc.StreamDesc{}, nil, "/ext.Service/Watch",
+		func(
+			_ context.Context,
+			_ *grpc.StreamDesc,
+			_ *grpc.ClientConn,
+			_ string,
+			_ ...grpc.CallOption,
+		) (grpc.ClientStream, error) {
+			return nil, errors.New("dial failed")
+		})
+	assert.Error(t, err)
+	assert.Contains(t, repw.String(), "REP 0009: dial failed")
+}
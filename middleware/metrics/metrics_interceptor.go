@@ -0,0 +1,171 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/dell/gocsi/utils/rpcs"
+)
+
+const namespace = "csi"
+
+// Option configures the metrics interceptor.
+type Option func(*opts)
+
+type opts struct {
+	registerer prometheus.Registerer
+	buckets    []float64
+}
+
+// WithRegisterer is an Option that sets the Prometheus registerer with
+// which the interceptor's collectors are registered. The default value
+// is prometheus.DefaultRegisterer.
+func WithRegisterer(r prometheus.Registerer) Option {
+	return func(o *opts) {
+		o.registerer = r
+	}
+}
+
+// WithBuckets is an Option that sets the buckets of the RPC latency
+// histogram. The default value is prometheus.DefBuckets.
+func WithBuckets(b []float64) Option {
+	return func(o *opts) {
+		o.buckets = b
+	}
+}
+
+type interceptor struct {
+	opts     opts
+	started  *prometheus.CounterVec
+	handled  *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight *prometheus.GaugeVec
+}
+
+// NewServerMetrics returns a new UnaryServerInterceptor that records
+// the number of started and handled RPCs, the gRPC status codes of the
+// handled RPCs, the RPCs' latency, and the number of RPCs in flight.
+// All of the collectors are labeled with the RPC's service and method.
+func NewServerMetrics(opts ...Option) grpc.UnaryServerInterceptor {
+	return newMetricsInterceptor(opts...).handleServer
+}
+
+// NewStreamServerMetrics returns a new StreamServerInterceptor that
+// records the same metrics as NewServerMetrics for streaming RPCs.
+func NewStreamServerMetrics(opts ...Option) grpc.StreamServerInterceptor {
+	return newMetricsInterceptor(opts...).handleStreamServer
+}
+
+func newMetricsInterceptor(opts ...Option) *interceptor {
+	i := &interceptor{}
+	for _, withOpts := range opts {
+		withOpts(&i.opts)
+	}
+	if i.opts.registerer == nil {
+		i.opts.registerer = prometheus.DefaultRegisterer
+	}
+	if len(i.opts.buckets) == 0 {
+		i.opts.buckets = prometheus.DefBuckets
+	}
+
+	labels := []string{"service", "method"}
+
+	i.started = Register(i.opts.registerer, prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rpc_started_total",
+			Help:      "Total number of RPCs started on the server.",
+		}, labels))
+
+	i.handled = Register(i.opts.registerer, prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rpc_handled_total",
+			Help: "Total number of RPCs completed on the server, " +
+				"regardless of success or failure.",
+		}, append(labels, "code")))
+
+	i.duration = Register(i.opts.registerer, prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "rpc_duration_seconds",
+			Help:      "Latency of RPCs handled by the server.",
+			Buckets:   i.opts.buckets,
+		}, labels))
+
+	i.inFlight = Register(i.opts.registerer, prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "rpc_in_flight",
+			Help:      "Number of RPCs currently being handled by the server.",
+		}, labels))
+
+	return i
+}
+
+func (i *interceptor) handleServer(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	var rep interface{}
+	err := i.handle(info.FullMethod, func() error {
+		var err error
+		rep, err = handler(ctx, req)
+		return err
+	})
+	return rep, err
+}
+
+func (i *interceptor) handleStreamServer(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	return i.handle(info.FullMethod, func() error {
+		return handler(srv, ss)
+	})
+}
+
+func (i *interceptor) handle(fullMethod string, next func() error) error {
+	service, method := rpcs.ServiceMethod(fullMethod)
+
+	i.started.WithLabelValues(service, method).Inc()
+	inFlight := i.inFlight.WithLabelValues(service, method)
+	inFlight.Inc()
+	defer inFlight.Dec()
+
+	start := time.Now()
+	err := next()
+
+	i.duration.WithLabelValues(service, method).Observe(
+		time.Since(start).Seconds())
+	i.handled.WithLabelValues(
+		service, method, status.Code(err).String()).Inc()
+
+	return err
+}
@@ -0,0 +1,131 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// gather returns the metric with the provided name whose labels match
+// the provided label values.
+func gather(
+	t *testing.T,
+	g prometheus.Gatherer,
+	name string,
+	labels map[string]string,
+) *dto.Metric {
+	mfs, err := g.Gather()
+	assert.NoError(t, err)
+	for _, mf := range mfs {
+		if mf.GetName() != name {
+			continue
+		}
+	next:
+		for _, m := range mf.GetMetric() {
+			for _, lp := range m.GetLabel() {
+				if v, ok := labels[lp.GetName()]; ok && v != lp.GetValue() {
+					continue next
+				}
+			}
+			return m
+		}
+	}
+	return nil
+}
+
+func TestServerMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	i := NewServerMetrics(WithRegisterer(reg))
+
+	createInfo := &grpc.UnaryServerInfo{
+		FullMethod: "/csi.v1.Controller/CreateVolume",
+	}
+
+	// Assert the in-flight gauge is incremented while the handler runs.
+	_, err := i(context.Background(), &csi.CreateVolumeRequest{}, createInfo,
+		func(_ context.Context, _ interface{}) (interface{}, error) {
+			m := gather(t, reg, "csi_rpc_in_flight", map[string]string{
+				"service": "Controller", "method": "CreateVolume",
+			})
+			assert.Equal(t, 1.0, m.GetGauge().GetValue())
+			return &csi.CreateVolumeResponse{}, nil
+		})
+	assert.NoError(t, err)
+
+	_, err = i(context.Background(), &csi.CreateVolumeRequest{}, createInfo,
+		func(_ context.Context, _ interface{}) (interface{}, error) {
+			return nil, status.Error(codes.AlreadyExists, "exists")
+		})
+	assert.Error(t, err)
+
+	labels := map[string]string{"service": "Controller", "method": "CreateVolume"}
+
+	m := gather(t, reg, "csi_rpc_started_total", labels)
+	assert.Equal(t, 2.0, m.GetCounter().GetValue())
+
+	m = gather(t, reg, "csi_rpc_in_flight", labels)
+	assert.Equal(t, 0.0, m.GetGauge().GetValue())
+
+	m = gather(t, reg, "csi_rpc_duration_seconds", labels)
+	assert.Equal(t, uint64(2), m.GetHistogram().GetSampleCount())
+
+	for _, code := range []string{"OK", "AlreadyExists"} {
+		m = gather(t, reg, "csi_rpc_handled_total", map[string]string{
+			"service": "Controller", "method": "CreateVolume", "code": code,
+		})
+		assert.Equal(t, 1.0, m.GetCounter().GetValue(), code)
+	}
+}
+
+func TestStreamServerMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	// Creating both interceptors with the same registerer shares the
+	// underlying collectors.
+	unary := NewServerMetrics(WithRegisterer(reg))
+	stream := NewStreamServerMetrics(WithRegisterer(reg))
+
+	_, err := unary(context.Background(), nil,
+		&grpc.UnaryServerInfo{FullMethod: "/ext.v1.Admin/Watch"},
+		func(_ context.Context, _ interface{}) (interface{}, error) {
+			return nil, nil
+		})
+	assert.NoError(t, err)
+
+	err = stream(nil, nil,
+		&grpc.StreamServerInfo{FullMethod: "/ext.v1.Admin/Watch"},
+		func(_ interface{}, _ grpc.ServerStream) error {
+			return nil
+		})
+	assert.NoError(t, err)
+
+	m := gather(t, reg, "csi_rpc_started_total", map[string]string{
+		"service": "ext.v1.Admin", "method": "Watch",
+	})
+	assert.Equal(t, 2.0, m.GetCounter().GetValue())
+}
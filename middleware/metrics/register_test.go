@@ -0,0 +1,53 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegister(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	newCounter := func(labels ...string) *prometheus.CounterVec {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "csi",
+			Name:      "test_total",
+			Help:      "Test counter.",
+		}, labels)
+	}
+
+	c := newCounter("method")
+	assert.Same(t, c, Register(reg, c))
+
+	// An identical collector is replaced by the registered one.
+	assert.Same(t, c, Register(reg, newCounter("method")))
+
+	// A conflicting collector is returned without being registered.
+	conflict := newCounter("service")
+	assert.NotPanics(t, func() {
+		assert.Same(t, conflict, Register(reg, conflict))
+	})
+	conflict.WithLabelValues("Controller").Inc()
+	mfs, err := reg.Gather()
+	assert.NoError(t, err)
+	assert.Empty(t, mfs)
+}
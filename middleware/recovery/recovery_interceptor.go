@@ -0,0 +1,157 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package recovery
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	csictx "github.com/dell/gocsi/context"
+	"github.com/dell/gocsi/middleware/metrics"
+	"github.com/dell/gocsi/utils/middleware"
+	"github.com/dell/gocsi/utils/rpcs"
+)
+
+// FatalFunc returns whether or not the provided panic, which occurred
+// while handling the provided gRPC method, should crash the process.
+type FatalFunc func(ctx context.Context, method string, p interface{}) bool
+
+// Option configures the interceptor.
+type Option func(*opts)
+
+type opts struct {
+	fatal      FatalFunc
+	registerer prometheus.Registerer
+}
+
+// WithFatal is an Option that sets the function that determines whether
+// or not a panic should crash the process. By default no panic does.
+func WithFatal(f FatalFunc) Option {
+	return func(o *opts) {
+		o.fatal = f
+	}
+}
+
+// WithRegisterer is an Option that registers the counter of the panics
+// recovered by the interceptor with the provided Prometheus registerer.
+func WithRegisterer(r prometheus.Registerer) Option {
+	return func(o *opts) {
+		o.registerer = r
+	}
+}
+
+type interceptor struct {
+	opts   opts
+	panics *prometheus.CounterVec
+}
+
+// NewServerRecovery returns a new UnaryServerInterceptor that recovers
+// from panics in the handlers of the RPCs. The panic and its stack trace
+// are logged with the RPC's method and request ID, if one is available,
+// including a request ID generated by an interceptor that follows this
+// one, and the RPC fails with codes.Internal. The panic is raised again if
+// the FatalFunc returns true.
+func NewServerRecovery(opts ...Option) grpc.UnaryServerInterceptor {
+	return newRecoveryInterceptor(opts...).handleServer
+}
+
+// NewStreamServerRecovery returns a new StreamServerInterceptor that
+// recovers from panics in the handlers of streaming RPCs.
+func NewStreamServerRecovery(opts ...Option) grpc.StreamServerInterceptor {
+	return newRecoveryInterceptor(opts...).handleStreamServer
+}
+
+func newRecoveryInterceptor(opts ...Option) *interceptor {
+	i := &interceptor{}
+	for _, withOpts := range opts {
+		withOpts(&i.opts)
+	}
+	if r := i.opts.registerer; r != nil {
+		i.panics = metrics.Register(r, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "csi",
+				Name:      "rpc_panics_total",
+				Help:      "Total number of panics recovered while handling RPCs.",
+			}, []string{"service", "method"}))
+	}
+	return i
+}
+
+func (i *interceptor) handleServer(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (rep interface{}, err error) {
+	ctx = csictx.WithRequestIDHolder(ctx)
+	defer func() {
+		if p := recover(); p != nil {
+			rep, err = nil, i.recovered(ctx, info.FullMethod, p)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func (i *interceptor) handleStreamServer(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) (err error) {
+	ctx := csictx.WithRequestIDHolder(ss.Context())
+	defer func() {
+		if p := recover(); p != nil {
+			err = i.recovered(ctx, info.FullMethod, p)
+		}
+	}()
+	return handler(srv, middleware.NewServerStreamWithContext(ctx, ss))
+}
+
+// recovered logs and counts the panic, and returns the RPC's error. The
+// panic is raised again if it is fatal.
+func (i *interceptor) recovered(
+	ctx context.Context, method string, p interface{},
+) error {
+	fields := map[string]interface{}{
+		"method": method,
+		"panic":  p,
+		"stack":  string(debug.Stack()),
+	}
+	if id, ok := csictx.GetRequestID(ctx); ok {
+		fields["requestID"] = id
+	}
+	log.WithFields(fields).Error("recovered from panic")
+
+	if i.panics != nil {
+		service, name := rpcs.ServiceMethod(method)
+		i.panics.WithLabelValues(service, name).Inc()
+	}
+
+	if f := i.opts.fatal; f != nil && f(ctx, method, p) {
+		panic(p)
+	}
+
+	return status.Errorf(codes.Internal, "panic: %v", p)
+}
@@ -0,0 +1,127 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package recovery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	csictx "github.com/dell/gocsi/context"
+	"github.com/dell/gocsi/middleware/requestid"
+	"github.com/dell/gocsi/utils/middleware"
+)
+
+const createVolume = "/csi.v1.Controller/CreateVolume"
+
+func panics(context.Context, interface{}) (interface{}, error) {
+	panic("nil pointer")
+}
+
+func TestServerRecovery(t *testing.T) {
+	r := prometheus.NewRegistry()
+	i := NewServerRecovery(WithRegisterer(r))
+
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(csictx.RequestIDKey, "7"))
+	rep, err := i(ctx, nil,
+		&grpc.UnaryServerInfo{FullMethod: createVolume}, panics)
+	assert.Nil(t, rep)
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	n, err := testutil.GatherAndCount(r, "csi_rpc_panics_total")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// RPCs that do not panic are not affected.
+	rep, err = i(ctx, "req",
+		&grpc.UnaryServerInfo{FullMethod: createVolume},
+		func(_ context.Context, req interface{}) (interface{}, error) {
+			return req, nil
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, "req", rep)
+}
+
+func TestServerRecoveryGeneratedRequestID(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	// The request ID is generated by an interceptor that follows the
+	// recovery interceptor.
+	i := middleware.ChainUnaryServer(
+		NewServerRecovery(), requestid.NewServerRequestIDInjector())
+	_, err := i(context.Background(), nil,
+		&grpc.UnaryServerInfo{FullMethod: createVolume}, panics)
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	entry := hook.LastEntry()
+	if assert.NotNil(t, entry) {
+		assert.Equal(t, "recovered from panic", entry.Message)
+		assert.Equal(t, uint64(1), entry.Data["requestID"])
+	}
+}
+
+func TestServerRecoveryFatal(t *testing.T) {
+	var method string
+	i := NewServerRecovery(WithFatal(
+		func(_ context.Context, m string, p interface{}) bool {
+			method = m
+			return p == "nil pointer"
+		}))
+
+	assert.PanicsWithValue(t, "nil pointer", func() {
+		_, _ = i(context.Background(), nil,
+			&grpc.UnaryServerInfo{FullMethod: createVolume}, panics)
+	})
+	assert.Equal(t, createVolume, method)
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+}
+
+func (s *testServerStream) Context() context.Context {
+	return context.Background()
+}
+
+func TestStreamServerRecovery(t *testing.T) {
+	r := prometheus.NewRegistry()
+	NewServerRecovery(WithRegisterer(r))
+	i := NewStreamServerRecovery(WithRegisterer(r))
+
+	err := i(nil, &testServerStream{},
+		&grpc.StreamServerInfo{FullMethod: "/ext.Service/Watch"},
+		func(interface{}, grpc.ServerStream) error {
+			panic("nil pointer")
+		})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(
+		newRecoveryInterceptor(WithRegisterer(r)).panics.WithLabelValues(
+			"ext.Service", "Watch")))
+}
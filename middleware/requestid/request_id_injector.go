@@ -29,6 +29,7 @@ import (
 	"google.golang.org/grpc/metadata"
 
 	csictx "github.com/dell/gocsi/context"
+	"github.com/dell/gocsi/utils/middleware"
 )
 
 type interceptor struct {
@@ -50,6 +51,21 @@ func NewClientRequestIDInjector() grpc.UnaryClientInterceptor {
 	return newRequestIDInjector().handleClient
 }
 
+// NewStreamServerRequestIDInjector returns a new StreamServerInterceptor
+// that reads a unique request ID from the incoming stream context's gRPC
+// metadata. If the incoming context does not contain gRPC metadata or
+// a request ID, then a new request ID is generated.
+func NewStreamServerRequestIDInjector() grpc.StreamServerInterceptor {
+	return newRequestIDInjector().handleStreamServer
+}
+
+// NewStreamClientRequestIDInjector provides a StreamClientInterceptor
+// that injects the outgoing stream context with gRPC metadata that
+// contains a unique ID.
+func NewStreamClientRequestIDInjector() grpc.StreamClientInterceptor {
+	return newRequestIDInjector().handleStreamClient
+}
+
 func newRequestIDInjector() *interceptor {
 	return &interceptor{}
 }
@@ -60,6 +76,20 @@ func (s *interceptor) handleServer(
 	_ *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler,
 ) (interface{}, error) {
+	return handler(s.injectServer(ctx), req)
+}
+
+func (s *interceptor) handleStreamServer(
+	srv interface{},
+	ss grpc.ServerStream,
+	_ *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	return handler(srv, middleware.NewServerStreamWithContext(
+		s.injectServer(ss.Context()), ss))
+}
+
+func (s *interceptor) injectServer(ctx context.Context) context.Context {
 	// storeID is a flag that indicates whether or not the request ID
 	// should be atomically stored in the interceptor's id field at
 	// the end of this function. If the ID was found in the incoming
@@ -100,7 +130,10 @@ func (s *interceptor) handleServer(
 		atomic.StoreUint64(&s.id, id)
 	}
 
-	return handler(ctx, req)
+	// Record the request ID for the interceptors that precede this one.
+	csictx.SetRequestID(ctx, id)
+
+	return ctx
 }
 
 func (s *interceptor) handleClient(
@@ -111,11 +144,25 @@ func (s *interceptor) handleClient(
 	invoker grpc.UnaryInvoker,
 	opts ...grpc.CallOption,
 ) error {
+	return invoker(s.injectClient(ctx), method, req, rep, cc, opts...)
+}
+
+func (s *interceptor) handleStreamClient(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	return streamer(s.injectClient(ctx), desc, cc, method, opts...)
+}
+
+func (s *interceptor) injectClient(ctx context.Context) context.Context {
 	// Ensure there is an outgoing gRPC context with metadata.
 	md, mdOK := metadata.FromOutgoingContext(ctx)
 	if !mdOK {
 		md = metadata.Pairs()
-		ctx = metadata.NewOutgoingContext(ctx, md)
 	}
 
 	// Ensure the request ID is set in the metadata.
@@ -124,5 +171,7 @@ func (s *interceptor) handleClient(
 		md[csictx.RequestIDKey] = szID
 	}
 
-	return invoker(ctx, method, req, rep, cc, opts...)
+	// The metadata returned by FromOutgoingContext is a copy, so the
+	// outgoing context must be replaced for the request ID to be sent.
+	return metadata.NewOutgoingContext(ctx, md)
 }
@@ -168,3 +168,96 @@ func TestInterceptorHandleClient(t *testing.T) {
 		})
 	}
 }
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestInterceptorHandleStreamServer(t *testing.T) {
+	tests := []struct {
+		name   string
+		getCtx func() context.Context
+		want   uint64
+	}{
+		{
+			name:   "Generated request ID",
+			getCtx: context.Background,
+			want:   1,
+		},
+		{
+			name: "With good request ID",
+			getCtx: func() context.Context {
+				md := metadata.Pairs(csictx.RequestIDKey, "2452")
+				return metadata.NewIncomingContext(context.Background(), md)
+			},
+			want: 2452,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := NewStreamServerRequestIDInjector()
+			err := i(nil, &testServerStream{ctx: tt.getCtx()}, nil,
+				func(_ interface{}, ss grpc.ServerStream) error {
+					id, ok := csictx.GetRequestID(ss.Context())
+					assert.True(t, ok)
+					assert.Equal(t, tt.want, id)
+					return nil
+				})
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestInterceptorHandleStreamClient(t *testing.T) {
+	tests := []struct {
+		name   string
+		getCtx func() context.Context
+		want   uint64
+	}{
+		{
+			name:   "Generated request ID",
+			getCtx: context.Background,
+			want:   1,
+		},
+		{
+			name: "Existing metadata without request ID",
+			getCtx: func() context.Context {
+				md := metadata.Pairs("key", "value")
+				return metadata.NewOutgoingContext(context.Background(), md)
+			},
+			want: 1,
+		},
+		{
+			name: "With request ID",
+			getCtx: func() context.Context {
+				md := metadata.Pairs(csictx.RequestIDKey, "42")
+				return metadata.NewOutgoingContext(context.Background(), md)
+			},
+			want: 42,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := NewStreamClientRequestIDInjector()
+			_, err := i(tt.getCtx(), &grpc.StreamDesc{}, nil, "exampleMethod",
+				func(
+					ctx context.Context,
+					_ *grpc.StreamDesc,
+					_ *grpc.ClientConn,
+					_ string,
+					_ ...grpc.CallOption,
+				) (grpc.ClientStream, error) {
+					id, ok := csictx.GetRequestID(ctx)
+					assert.True(t, ok)
+					assert.Equal(t, tt.want, id)
+					return nil, nil
+				})
+			assert.NoError(t, err)
+		})
+	}
+}
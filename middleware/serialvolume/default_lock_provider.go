@@ -20,40 +20,148 @@ package serialvolume
 
 import (
 	"context"
+	"hash/fnv"
 	"sync"
+	"sync/atomic"
 
 	"github.com/akutz/gosync"
+
+	mwtypes "github.com/dell/gocsi/middleware/serialvolume/lockprovider"
 )
 
+// lockShards is the number of shards in a lockMap. Keys are spread over
+// the shards so that unrelated volumes rarely contend for the same
+// shard mutex.
+const lockShards = 64
+
+// defaultLockProvider is the in-memory lock provider. Its locks are
+// reference counted: each lock returned by the provider must be closed
+// once it is unlocked, and a lock is removed from the provider when the
+// last reference to it is closed. The zero value is ready to use.
 type defaultLockProvider struct {
-	volIDLocksL   sync.Mutex
-	volNameLocksL sync.Mutex
-	volIDLocks    map[string]gosync.TryLocker
-	volNameLocks  map[string]gosync.TryLocker
+	volIDLocks   lockMap
+	volNameLocks lockMap
+
+	// snapLocks contains the snapshot and group snapshot locks. The keys
+	// are prefixed with the kind of lock.
+	snapLocks lockMap
 }
 
+// Volume ID locks are shared/exclusive locks so that the same lock is
+// used by both GetLockWithID and GetRWLockWithID.
 func (i *defaultLockProvider) GetLockWithID(
 	_ context.Context, id string,
 ) (gosync.TryLocker, error) {
-	i.volIDLocksL.Lock()
-	defer i.volIDLocksL.Unlock()
-	lock := i.volIDLocks[id]
-	if lock == nil {
-		lock = &gosync.TryMutex{}
-		i.volIDLocks[id] = lock
-	}
-	return lock, nil
+	return i.volIDLocks.get(id), nil
+}
+
+func (i *defaultLockProvider) GetRWLockWithID(
+	_ context.Context, id string,
+) (mwtypes.TryRWLocker, error) {
+	return i.volIDLocks.get(id), nil
 }
 
 func (i *defaultLockProvider) GetLockWithName(
 	_ context.Context, name string,
 ) (gosync.TryLocker, error) {
-	i.volNameLocksL.Lock()
-	defer i.volNameLocksL.Unlock()
-	lock := i.volNameLocks[name]
+	return i.volNameLocks.get(name), nil
+}
+
+func (i *defaultLockProvider) GetLockWithSnapshotID(
+	_ context.Context, id string,
+) (gosync.TryLocker, error) {
+	return i.snapLocks.get("snapshotsByID/" + id), nil
+}
+
+func (i *defaultLockProvider) GetLockWithSnapshotName(
+	_ context.Context, name string,
+) (gosync.TryLocker, error) {
+	return i.snapLocks.get("snapshotsByName/" + name), nil
+}
+
+func (i *defaultLockProvider) GetLockWithGroupSnapshotID(
+	_ context.Context, id string,
+) (gosync.TryLocker, error) {
+	return i.snapLocks.get("groupSnapshotsByID/" + id), nil
+}
+
+func (i *defaultLockProvider) GetLockWithGroupSnapshotName(
+	_ context.Context, name string,
+) (gosync.TryLocker, error) {
+	return i.snapLocks.get("groupSnapshotsByName/" + name), nil
+}
+
+// lockMap is a sharded map of reference counted locks. The zero value is
+// an empty map.
+type lockMap struct {
+	shards [lockShards]lockShard
+}
+
+type lockShard struct {
+	sync.Mutex
+	locks map[string]*refLock
+}
+
+// refLock is a lock and the number of open references to it.
+type refLock struct {
+	mwtypes.TryRWMutex
+	refs int
+}
+
+// get returns a new reference to the lock for the provided key. The lock
+// is created if it does not exist.
+func (m *lockMap) get(key string) *lockRef {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	shard := &m.shards[h.Sum32()%lockShards]
+
+	shard.Lock()
+	defer shard.Unlock()
+	if shard.locks == nil {
+		shard.locks = map[string]*refLock{}
+	}
+	lock := shard.locks[key]
 	if lock == nil {
-		lock = &gosync.TryMutex{}
-		i.volNameLocks[name] = lock
+		lock = &refLock{}
+		shard.locks[key] = lock
+	}
+	lock.refs++
+	return &lockRef{TryRWMutex: &lock.TryRWMutex, shard: shard, key: key}
+}
+
+// len returns the number of locks in the map.
+func (m *lockMap) len() int {
+	n := 0
+	for i := range m.shards {
+		shard := &m.shards[i]
+		shard.Lock()
+		n += len(shard.locks)
+		shard.Unlock()
+	}
+	return n
+}
+
+// lockRef is a reference to a lock in a lockMap.
+type lockRef struct {
+	*mwtypes.TryRWMutex
+	shard  *lockShard
+	key    string
+	closed int32
+}
+
+// Close releases the reference to the lock. The lock is removed from its
+// map when its last reference is released. Closing a reference more than
+// once has no effect.
+func (r *lockRef) Close() error {
+	if !atomic.CompareAndSwapInt32(&r.closed, 0, 1) {
+		return nil
+	}
+	r.shard.Lock()
+	defer r.shard.Unlock()
+	lock := r.shard.locks[r.key]
+	lock.refs--
+	if lock.refs == 0 {
+		delete(r.shard.locks, r.key)
 	}
-	return lock, nil
+	return nil
 }
@@ -20,16 +20,26 @@ package serialvolume
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/akutz/gosync"
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc"
 )
 
+// sameLock returns a flag indicating whether or not the provided locks
+// are references to the same lock.
+func sameLock(a, b gosync.TryLocker) bool {
+	return a.(*lockRef).TryRWMutex == b.(*lockRef).TryRWMutex
+}
+
 func TestGetLockWithID(t *testing.T) {
-	provider := &defaultLockProvider{
-		volIDLocks:   make(map[string]gosync.TryLocker),
-		volNameLocks: make(map[string]gosync.TryLocker),
-	}
+	provider := &defaultLockProvider{}
 
 	ctx := context.Background()
 	id := "test-id"
@@ -43,21 +53,20 @@ func TestGetLockWithID(t *testing.T) {
 		t.Error("expected non-nil lock")
 	}
 
-	storedLock, exists := provider.volIDLocks[id]
-	if !exists {
-		t.Errorf("lock not found for ID %s", id)
+	again, err := provider.GetLockWithID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
 	}
-
-	if lock != storedLock {
-		t.Errorf("expected lock %v, got %v", lock, storedLock)
+	if !sameLock(lock, again) {
+		t.Errorf("expected lock %v, got %v", lock, again)
+	}
+	if n := provider.volIDLocks.len(); n != 1 {
+		t.Errorf("expected 1 lock, got %d", n)
 	}
 }
 
 func TestGetLockWithName(t *testing.T) {
-	provider := &defaultLockProvider{
-		volIDLocks:   make(map[string]gosync.TryLocker),
-		volNameLocks: make(map[string]gosync.TryLocker),
-	}
+	provider := &defaultLockProvider{}
 
 	ctx := context.Background()
 	name := "test-name"
@@ -71,13 +80,172 @@ func TestGetLockWithName(t *testing.T) {
 		t.Error("expected non-nil lock")
 	}
 
-	// Ensure the lock is stored in the map
-	storedLock, exists := provider.volNameLocks[name]
-	if !exists {
-		t.Errorf("lock not found for name %s", name)
+	again, err := provider.GetLockWithName(ctx, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameLock(lock, again) {
+		t.Errorf("expected lock %v, got %v", lock, again)
+	}
+	if n := provider.volNameLocks.len(); n != 1 {
+		t.Errorf("expected 1 lock, got %d", n)
+	}
+}
+
+func TestGetRWLockWithID(t *testing.T) {
+	provider := &defaultLockProvider{}
+
+	ctx := context.Background()
+	id := "test-id"
+
+	rwLock, err := provider.GetRWLockWithID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The exclusive lock for the same ID is the same lock.
+	lock, err := provider.GetLockWithID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameLock(lock, rwLock) {
+		t.Errorf("expected lock %v, got %v", rwLock, lock)
+	}
+}
+
+func TestGetSnapshotLocks(t *testing.T) {
+	provider := &defaultLockProvider{}
+	ctx := context.Background()
+
+	getLocks := []func(context.Context, string) (gosync.TryLocker, error){
+		provider.GetLockWithSnapshotID,
+		provider.GetLockWithSnapshotName,
+		provider.GetLockWithGroupSnapshotID,
+		provider.GetLockWithGroupSnapshotName,
 	}
 
-	if lock != storedLock {
-		t.Errorf("expected lock %v, got %v", lock, storedLock)
+	// The same key returns the same lock for each kind of lock, and a
+	// different lock for the other kinds.
+	for i, getLock := range getLocks {
+		lock, err := getLock(ctx, "key")
+		if err != nil {
+			t.Fatal(err)
+		}
+		again, _ := getLock(ctx, "key")
+		if !sameLock(lock, again) {
+			t.Errorf("expected lock %v, got %v", lock, again)
+		}
+		for j, getOther := range getLocks {
+			if i == j {
+				continue
+			}
+			other, _ := getOther(ctx, "key")
+			if sameLock(lock, other) {
+				t.Errorf("expected different locks for kinds %d and %d", i, j)
+			}
+		}
 	}
 }
+
+func TestLockReclaimed(t *testing.T) {
+	provider := &defaultLockProvider{}
+	ctx := context.Background()
+
+	held, _ := provider.GetLockWithID(ctx, "vol-1")
+	held.Lock()
+
+	// Closing another reference does not remove a lock that is in use.
+	other, _ := provider.GetLockWithID(ctx, "vol-1")
+	if other.TryLock(0) {
+		t.Fatal("expected lock to be held")
+	}
+	if err := other.(io.Closer).Close(); err != nil {
+		t.Fatal(err)
+	}
+	other, _ = provider.GetLockWithID(ctx, "vol-1")
+	if other.TryLock(0) {
+		t.Fatal("expected lock to be held")
+	}
+	other.(io.Closer).Close()
+
+	// Closing a reference more than once has no effect.
+	other.(io.Closer).Close()
+	if n := provider.volIDLocks.len(); n != 1 {
+		t.Fatalf("expected 1 lock, got %d", n)
+	}
+
+	held.Unlock()
+	held.(io.Closer).Close()
+	if n := provider.volIDLocks.len(); n != 0 {
+		t.Fatalf("expected 0 locks, got %d", n)
+	}
+}
+
+func TestLockMapsEmptyAfterTraffic(t *testing.T) {
+	provider := &defaultLockProvider{}
+	interceptor := New(WithTimeout(time.Second), WithLockProvider(provider))
+
+	var handled int64
+	handler := func(_ context.Context, _ interface{}) (interface{}, error) {
+		atomic.AddInt64(&handled, 1)
+		return nil, nil
+	}
+
+	// Concurrent requests for a small set of volumes and snapshots so
+	// that references to the same locks overlap.
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				vol := fmt.Sprintf("vol-%d", (g+i)%8)
+				for _, req := range []interface{}{
+					&csi.CreateVolumeRequest{Name: vol},
+					&csi.NodeStageVolumeRequest{VolumeId: vol},
+					&csi.ControllerGetVolumeRequest{VolumeId: vol},
+					&csi.CreateSnapshotRequest{
+						Name: "snap-" + vol, SourceVolumeId: vol,
+					},
+				} {
+					_, err := interceptor(context.Background(), req,
+						&grpc.UnaryServerInfo{}, handler)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if handled != 16*200*4 {
+		t.Fatalf("expected %d handled requests, got %d", 16*200*4, handled)
+	}
+	for name, m := range map[string]*lockMap{
+		"volume ID":   &provider.volIDLocks,
+		"volume name": &provider.volNameLocks,
+		"snapshot":    &provider.snapLocks,
+	} {
+		if n := m.len(); n != 0 {
+			t.Errorf("expected 0 %s locks, got %d", name, n)
+		}
+	}
+}
+
+func BenchmarkDefaultLockProvider(b *testing.B) {
+	provider := &defaultLockProvider{}
+	ctx := context.Background()
+
+	var next int64
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			id := fmt.Sprintf("vol-%d", atomic.AddInt64(&next, 1)%1024)
+			lock, _ := provider.GetLockWithID(ctx, id)
+			lock.Lock()
+			lock.Unlock()
+			lock.(io.Closer).Close()
+		}
+	})
+}
@@ -21,6 +21,7 @@ package etcd
 import (
 	"context"
 	"crypto/tls"
+	"fmt"
 	"path"
 	"strconv"
 	"strings"
@@ -79,6 +80,13 @@ func New(
 	}, nil
 }
 
+// NewConfig returns an etcd client configuration initialized from the
+// serial volume access etcd environment variables. Other middleware that
+// use etcd share this configuration.
+func NewConfig(ctx context.Context) (etcd.Config, error) {
+	return initConfig(ctx, map[string]interface{}{})
+}
+
 func initConfig(
 	ctx context.Context,
 	fields map[string]interface{},
@@ -185,6 +193,11 @@ func initConfig(
 	return config, nil
 }
 
+// defaultSessionTTL is the TTL, in seconds, of the lease of a lock's
+// session when no TTL is configured. It matches the default of the etcd
+// concurrency package.
+const defaultSessionTTL = 60
+
 type provider struct {
 	client *etcd.Client
 	domain string
@@ -201,33 +214,83 @@ func (p *provider) GetLockWithID(
 	return p.getLock(ctx, path.Join(p.domain, "volumesByID", id))
 }
 
+func (p *provider) GetRWLockWithID(
+	ctx context.Context, id string,
+) (mwtypes.TryRWLocker, error) {
+	pfx := path.Join(p.domain, "volumesByID", id)
+	lock, err := p.getLock(ctx, pfx)
+	if err != nil {
+		return nil, err
+	}
+	return &TryRWMutex{TryMutex: lock.(*TryMutex), pfx: pfx}, nil
+}
+
 func (p *provider) GetLockWithName(
 	ctx context.Context, name string,
 ) (gosync.TryLocker, error) {
 	return p.getLock(ctx, path.Join(p.domain, "volumesByName", name))
 }
 
+func (p *provider) GetLockWithSnapshotID(
+	ctx context.Context, id string,
+) (gosync.TryLocker, error) {
+	return p.getLock(ctx, path.Join(p.domain, "snapshotsByID", id))
+}
+
+func (p *provider) GetLockWithSnapshotName(
+	ctx context.Context, name string,
+) (gosync.TryLocker, error) {
+	return p.getLock(ctx, path.Join(p.domain, "snapshotsByName", name))
+}
+
+func (p *provider) GetLockWithGroupSnapshotID(
+	ctx context.Context, id string,
+) (gosync.TryLocker, error) {
+	return p.getLock(ctx, path.Join(p.domain, "groupSnapshotsByID", id))
+}
+
+func (p *provider) GetLockWithGroupSnapshotName(
+	ctx context.Context, name string,
+) (gosync.TryLocker, error) {
+	return p.getLock(ctx, path.Join(p.domain, "groupSnapshotsByName", name))
+}
+
 func (p *provider) getLock(
 	ctx context.Context, pfx string,
 ) (gosync.TryLocker, error) {
 	log.Debugf("EtcdVolumeLockProvider: getLock: pfx=%v", pfx)
 
-	opts := []etcdsync.SessionOption{etcdsync.WithContext(ctx)}
-	if p.ttl > 0 {
-		opts = append(opts, etcdsync.WithTTL(p.ttl))
+	ttl := p.ttl
+	if ttl <= 0 {
+		ttl = defaultSessionTTL
 	}
 
-	sess, err := etcdsync.NewSession(p.client, opts...)
+	// The session's lease is granted with the caller's context so that a
+	// canceled request does not wait for etcd. The session itself must
+	// outlive a canceled request so that the lock may still be released
+	// and the lease revoked.
+	lease, err := p.client.Grant(ctx, int64(ttl))
 	if err != nil {
 		return nil, err
 	}
+	sess, err := etcdsync.NewSession(p.client,
+		etcdsync.WithContext(context.WithoutCancel(ctx)),
+		etcdsync.WithLease(lease.ID),
+		etcdsync.WithTTL(ttl))
+	if err != nil {
+		_, _ = p.client.Revoke(context.WithoutCancel(ctx), lease.ID)
+		return nil, err
+	}
 	return &TryMutex{
 		ctx: ctx, sess: sess, mtx: etcdsync.NewMutex(sess, pfx),
 	}, nil
 }
 
 // TryMutex is a mutual exclusion lock backed by etcd that implements the
-// TryLocker interface.
+// ContextTryLocker interface. Attempts to obtain the lock use the context
+// of the request for which the lock was created, or LockCtx and
+// TryLockCtx when set. Unlock is not affected by the cancellation of the
+// request's context.
 // The zero value for a TryMutex is an unlocked mutex.
 //
 // A TryMutex may be copied after first use.
@@ -272,7 +335,7 @@ func (m *TryMutex) Unlock() {
 	// log.Debug("TryMutex: unlock")
 	ctx := m.UnlockCtx
 	if ctx == nil {
-		ctx = m.ctx
+		ctx = context.WithoutCancel(m.ctx)
 	}
 	if err := m.mtx.Unlock(ctx); err != nil {
 		log.Debugf("TryMutex: unlock err: %v", err)
@@ -299,7 +362,15 @@ func (m *TryMutex) TryLock(timeout time.Duration) bool {
 	if ctx == nil {
 		ctx = m.ctx
 	}
+	return m.TryLockContext(ctx, timeout)
+}
 
+// TryLockContext attempts to lock m. If no lock can be obtained in the
+// specified duration, or before ctx is done, then a false value is
+// returned.
+func (m *TryMutex) TryLockContext(
+	ctx context.Context, timeout time.Duration,
+) bool {
 	// Create a timeout context only if the timeout is greater than zero.
 	if timeout > 0 {
 		var cancel context.CancelFunc
@@ -316,3 +387,159 @@ func (m *TryMutex) TryLock(timeout time.Duration) bool {
 	}
 	return true
 }
+
+// TryRWMutex is a reader/writer mutual exclusion lock backed by etcd that
+// implements the ContextTryRWLocker interface. Writers use the same keys as a
+// TryMutex with the same prefix, so a TryRWMutex locked for writing
+// excludes a TryMutex and vice versa. Readers are recorded under the
+// prefix's "read" directory and wait for any writers that requested the
+// lock before them.
+//
+// A TryRWMutex may be copied after first use.
+type TryRWMutex struct {
+	*TryMutex
+	pfx string
+}
+
+// RLock locks m for reading. If the lock is held or requested by a
+// writer, the calling goroutine blocks until the lock is available.
+func (m *TryRWMutex) RLock() {
+	ctx := m.LockCtx
+	if ctx == nil {
+		ctx = m.ctx
+	}
+	if err := m.rlock(ctx); err != nil {
+		log.Debugf("TryRWMutex: rlock err: %v", err)
+		if err != context.Canceled && err != context.DeadlineExceeded {
+			log.Panicf("TryRWMutex: rlock panic: %v", err)
+		}
+	}
+}
+
+// RUnlock undoes a single RLock call.
+func (m *TryRWMutex) RUnlock() {
+	ctx := m.UnlockCtx
+	if ctx == nil {
+		ctx = context.WithoutCancel(m.ctx)
+	}
+	if _, err := m.sess.Client().Delete(ctx, m.readKey()); err != nil {
+		log.Debugf("TryRWMutex: runlock err: %v", err)
+		if err != context.Canceled && err != context.DeadlineExceeded {
+			log.Panicf("TryRWMutex: runlock panic: %v", err)
+		}
+	}
+}
+
+// TryRLock attempts to lock m for reading. If no lock can be obtained in
+// the specified duration then a false value is returned.
+func (m *TryRWMutex) TryRLock(timeout time.Duration) bool {
+	ctx := m.TryLockCtx
+	if ctx == nil {
+		ctx = m.ctx
+	}
+	return m.TryRLockContext(ctx, timeout)
+}
+
+// TryRLockContext attempts to lock m for reading. If no lock can be
+// obtained in the specified duration, or before ctx is done, then a false
+// value is returned.
+func (m *TryRWMutex) TryRLockContext(
+	ctx context.Context, timeout time.Duration,
+) bool {
+	// Create a timeout context only if the timeout is greater than zero.
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if err := m.rlock(ctx); err != nil {
+		log.Debugf("TryRWMutex: TryRLock err: %v", err)
+		if err != context.Canceled && err != context.DeadlineExceeded {
+			log.Panicf("TryRWMutex: TryRLock panic: %v", err)
+		}
+		return false
+	}
+	return true
+}
+
+// readKey returns the key that records the session's read lock. Writer
+// keys are the session lease IDs in hex, which sort before "read".
+func (m *TryRWMutex) readKey() string {
+	return fmt.Sprintf("%s/read/%x", m.pfx, m.sess.Lease())
+}
+
+func (m *TryRWMutex) rlock(ctx context.Context) error {
+	client := m.sess.Client()
+	k := m.readKey()
+
+	// Record the read lock, or find the existing read lock if the session
+	// already holds one.
+	cmp := etcd.Compare(etcd.CreateRevision(k), "=", 0)
+	put := etcd.OpPut(k, "", etcd.WithLease(m.sess.Lease()))
+	get := etcd.OpGet(k)
+	resp, err := client.Txn(ctx).If(cmp).Then(put).Else(get).Commit()
+	if err != nil {
+		return err
+	}
+	rev := resp.Header.Revision
+	if !resp.Succeeded {
+		rev = resp.Responses[0].GetResponseRange().Kvs[0].CreateRevision
+	}
+
+	if err := m.waitWriters(ctx, rev-1); err != nil {
+		// Remove the read lock so it does not block writers. The
+		// cancellation of ctx is ignored since ctx is likely done.
+		dctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		_, _ = client.Delete(dctx, k)
+		return err
+	}
+	return nil
+}
+
+// waitWriters waits until the writer keys with a create revision less
+// than or equal to maxRev are deleted. The writer keys are the keys in
+// the range [pfx/, pfx/r) since the lease IDs are hex strings.
+func (m *TryRWMutex) waitWriters(ctx context.Context, maxRev int64) error {
+	client := m.sess.Client()
+	for {
+		resp, err := client.Get(ctx, m.pfx+"/",
+			etcd.WithRange(m.pfx+"/r"),
+			etcd.WithSort(etcd.SortByCreateRevision, etcd.SortDescend),
+			etcd.WithMaxCreateRev(maxRev),
+			etcd.WithLimit(1))
+		if err != nil {
+			return err
+		}
+		if len(resp.Kvs) == 0 {
+			return nil
+		}
+
+		// Wait for the most recent writer to be deleted.
+		wctx, cancel := context.WithCancel(ctx)
+		wch := client.Watch(wctx, string(resp.Kvs[0].Key),
+			etcd.WithRev(resp.Header.Revision+1))
+		deleted := false
+		for wresp := range wch {
+			if err := wresp.Err(); err != nil {
+				cancel()
+				return err
+			}
+			for _, ev := range wresp.Events {
+				if ev.Type == etcd.EventTypeDelete {
+					deleted = true
+				}
+			}
+			if deleted {
+				break
+			}
+		}
+		cancel()
+		if !deleted {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -35,6 +35,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/akutz/gosync"
 	log "github.com/sirupsen/logrus"
 
 	mwtypes "github.com/dell/gocsi/middleware/serialvolume/lockprovider"
@@ -171,6 +172,122 @@ func TestTryMutex_Lock(t *testing.T) {
 	}
 }
 
+func TestTryRWMutex(t *testing.T) {
+	ctx := context.Background()
+	id := t.Name()
+	rwp := p.(mwtypes.RWVolumeLockerProvider)
+
+	getRWLock := func() mwtypes.TryRWLocker {
+		m, err := rwp.GetRWLockWithID(ctx, id)
+		assert.NoError(t, err)
+		t.Cleanup(func() { m.(io.Closer).Close() })
+		return m
+	}
+
+	r1, r2, w := getRWLock(), getRWLock(), getRWLock()
+
+	// Readers share the lock.
+	assert.True(t, r1.TryRLock(time.Second))
+	assert.True(t, r2.TryRLock(time.Second))
+
+	// Writers, including exclusive locks for the same ID, are excluded.
+	assert.False(t, w.TryLock(500*time.Millisecond))
+	m, err := p.GetLockWithID(ctx, id)
+	assert.NoError(t, err)
+	defer m.(io.Closer).Close()
+	assert.False(t, m.TryLock(500*time.Millisecond))
+
+	r1.RUnlock()
+	r2.RUnlock()
+
+	// A writer excludes readers.
+	assert.True(t, w.TryLock(time.Second))
+	assert.False(t, r1.TryRLock(500*time.Millisecond))
+
+	// A waiting reader obtains the lock once the writer unlocks.
+	locked := make(chan bool)
+	go func() {
+		locked <- r2.TryRLock(5 * time.Second)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	w.Unlock()
+	assert.True(t, <-locked)
+
+	// An exclusive lock waits for the reader.
+	assert.False(t, m.TryLock(500*time.Millisecond))
+	r2.RUnlock()
+	assert.True(t, m.TryLock(time.Second))
+	assert.False(t, r1.TryRLock(500*time.Millisecond))
+	m.Unlock()
+	assert.True(t, r1.TryRLock(time.Second))
+	r1.RUnlock()
+}
+
+func TestTryMutexContext(t *testing.T) {
+	id := t.Name()
+
+	// A lock obtained for a request is released even if the request is
+	// canceled before the lock is unlocked.
+	reqCtx, cancelReq := context.WithCancel(context.Background())
+	held, err := p.GetLockWithID(reqCtx, id)
+	assert.NoError(t, err)
+	assert.True(t, held.TryLock(time.Second))
+	cancelReq()
+
+	// Waiting for the held lock stops when the request is canceled.
+	waitCtx, cancelWait := context.WithCancel(context.Background())
+	m, err := p.GetLockWithID(waitCtx, id)
+	assert.NoError(t, err)
+	defer m.(io.Closer).Close()
+	time.AfterFunc(200*time.Millisecond, cancelWait)
+	start := time.Now()
+	assert.False(t, m.TryLock(time.Minute))
+	assert.Less(t, time.Since(start), 30*time.Second)
+
+	rw, err := p.(mwtypes.RWVolumeLockerProvider).GetRWLockWithID(
+		context.Background(), id)
+	assert.NoError(t, err)
+	defer rw.(io.Closer).Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	assert.False(t, rw.(mwtypes.ContextTryRWLocker).TryRLockContext(ctx, time.Minute))
+
+	held.Unlock()
+	assert.NoError(t, held.(io.Closer).Close())
+	assert.True(t, rw.(mwtypes.ContextTryRWLocker).TryLockContext(
+		context.Background(), time.Second))
+	rw.Unlock()
+}
+
+func TestSnapshotLocks(t *testing.T) {
+	ctx := context.Background()
+	id := t.Name()
+	sp := p.(mwtypes.SnapshotLockerProvider)
+
+	getLocks := []func(context.Context, string) (gosync.TryLocker, error){
+		p.GetLockWithID,
+		sp.GetLockWithSnapshotID,
+		sp.GetLockWithSnapshotName,
+		sp.GetLockWithGroupSnapshotID,
+		sp.GetLockWithGroupSnapshotName,
+	}
+
+	// Locks of different kinds with the same key do not exclude each other.
+	for _, getLock := range getLocks {
+		m, err := getLock(ctx, id)
+		assert.NoError(t, err)
+		defer m.(io.Closer).Close()
+		assert.True(t, m.TryLock(time.Second))
+		defer m.Unlock()
+	}
+
+	// Locks of the same kind with the same key exclude each other.
+	m, err := sp.GetLockWithSnapshotID(ctx, id)
+	assert.NoError(t, err)
+	defer m.(io.Closer).Close()
+	assert.False(t, m.TryLock(500*time.Millisecond))
+}
+
 func ExampleTryMutex_TryLock() {
 	const lockName = "ExampleTryMutex_TryLock"
 
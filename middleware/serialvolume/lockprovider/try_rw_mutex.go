@@ -0,0 +1,189 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package lockprovider
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TryRWMutex is a reader/writer mutual exclusion lock that implements the
+// ContextTryRWLocker interface. Once a writer is waiting for the lock, new
+// readers wait for that writer so writers are not starved.
+// The zero value for a TryRWMutex is an unlocked mutex.
+//
+// A TryRWMutex must not be copied after first use.
+type TryRWMutex struct {
+	mu      sync.Mutex
+	readers int
+	writer  bool
+	waiting int
+
+	// changed is closed and replaced whenever the lock is released so
+	// that waiting goroutines may try again.
+	changed chan struct{}
+}
+
+// Lock locks m for writing. If the lock is already in use, the calling
+// goroutine blocks until the mutex is available.
+func (m *TryRWMutex) Lock() {
+	m.lock(context.Background(), false, -1)
+}
+
+// Unlock unlocks m for writing. It is a run-time error if m is not locked
+// for writing on entry to Unlock.
+func (m *TryRWMutex) Unlock() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.writer {
+		panic("lockprovider: unlock of unlocked mutex")
+	}
+	m.writer = false
+	m.broadcast()
+}
+
+// TryLock attempts to lock m for writing. If no lock can be obtained in
+// the specified duration then a false value is returned.
+func (m *TryRWMutex) TryLock(timeout time.Duration) bool {
+	return m.lock(context.Background(), false, timeout)
+}
+
+// TryLockContext attempts to lock m for writing. If no lock can be
+// obtained in the specified duration, or before ctx is done, then a false
+// value is returned.
+func (m *TryRWMutex) TryLockContext(
+	ctx context.Context, timeout time.Duration,
+) bool {
+	return m.lock(ctx, false, timeout)
+}
+
+// RLock locks m for reading. If the lock is held or requested by a
+// writer, the calling goroutine blocks until the lock is available.
+func (m *TryRWMutex) RLock() {
+	m.lock(context.Background(), true, -1)
+}
+
+// RUnlock undoes a single RLock call. It is a run-time error if m is not
+// locked for reading on entry to RUnlock.
+func (m *TryRWMutex) RUnlock() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.readers == 0 {
+		panic("lockprovider: runlock of unlocked mutex")
+	}
+	m.readers--
+	if m.readers == 0 {
+		m.broadcast()
+	}
+}
+
+// TryRLock attempts to lock m for reading. If no lock can be obtained in
+// the specified duration then a false value is returned.
+func (m *TryRWMutex) TryRLock(timeout time.Duration) bool {
+	return m.lock(context.Background(), true, timeout)
+}
+
+// TryRLockContext attempts to lock m for reading. If no lock can be
+// obtained in the specified duration, or before ctx is done, then a false
+// value is returned.
+func (m *TryRWMutex) TryRLockContext(
+	ctx context.Context, timeout time.Duration,
+) bool {
+	return m.lock(ctx, true, timeout)
+}
+
+// lock obtains the lock for reading or writing. A negative timeout waits
+// until ctx is done and a zero timeout does not wait at all.
+func (m *TryRWMutex) lock(
+	ctx context.Context, read bool, timeout time.Duration,
+) bool {
+	var timer <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		timer = t.C
+	}
+
+	m.mu.Lock()
+	if !read {
+		// Readers that are waiting for this writer must be woken if the
+		// writer gives up.
+		m.waiting++
+		defer func() {
+			m.mu.Lock()
+			m.waiting--
+			m.broadcast()
+			m.mu.Unlock()
+		}()
+	}
+	for {
+		if m.acquire(read) {
+			m.mu.Unlock()
+			return true
+		}
+		if timeout == 0 {
+			m.mu.Unlock()
+			return false
+		}
+		if m.changed == nil {
+			m.changed = make(chan struct{})
+		}
+		changed := m.changed
+		m.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-timer:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+		m.mu.Lock()
+	}
+}
+
+// acquire obtains the lock if it is available. The caller must hold mu.
+func (m *TryRWMutex) acquire(read bool) bool {
+	if m.writer {
+		return false
+	}
+	if read {
+		// Readers wait for writers that are already waiting.
+		if m.waiting > 0 {
+			return false
+		}
+		m.readers++
+		return true
+	}
+	if m.readers > 0 {
+		return false
+	}
+	m.writer = true
+	return true
+}
+
+// broadcast wakes the goroutines waiting for the lock. The caller must
+// hold mu.
+func (m *TryRWMutex) broadcast() {
+	if m.changed != nil {
+		close(m.changed)
+		m.changed = nil
+	}
+}
@@ -0,0 +1,172 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package lockprovider
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTryRWMutexReaders(t *testing.T) {
+	var m TryRWMutex
+
+	// Any number of readers may hold the lock.
+	assert.True(t, m.TryRLock(0))
+	assert.True(t, m.TryRLock(0))
+	m.RLock()
+
+	// A writer is excluded by the readers.
+	assert.False(t, m.TryLock(0))
+	assert.False(t, m.TryLock(10*time.Millisecond))
+
+	m.RUnlock()
+	m.RUnlock()
+	m.RUnlock()
+	assert.True(t, m.TryLock(0))
+	m.Unlock()
+}
+
+func TestTryRWMutexWriter(t *testing.T) {
+	var m TryRWMutex
+
+	m.Lock()
+	assert.False(t, m.TryLock(0))
+	assert.False(t, m.TryRLock(0))
+	assert.False(t, m.TryRLock(10*time.Millisecond))
+
+	// A waiting reader obtains the lock once the writer unlocks.
+	locked := make(chan bool)
+	go func() {
+		locked <- m.TryRLock(time.Second)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	m.Unlock()
+	assert.True(t, <-locked)
+	m.RUnlock()
+}
+
+func TestTryRWMutexWriterPreference(t *testing.T) {
+	var m TryRWMutex
+
+	m.RLock()
+
+	// A waiting writer blocks new readers.
+	locked := make(chan bool)
+	go func() {
+		locked <- m.TryLock(time.Second)
+	}()
+	assert.Eventually(t, func() bool {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return m.waiting == 1
+	}, time.Second, time.Millisecond)
+	assert.False(t, m.TryRLock(0))
+
+	m.RUnlock()
+	assert.True(t, <-locked)
+	m.Unlock()
+
+	// A writer that gives up no longer blocks readers.
+	m.RLock()
+	assert.False(t, m.TryLock(10*time.Millisecond))
+	assert.True(t, m.TryRLock(0))
+	m.RUnlock()
+	m.RUnlock()
+}
+
+func TestTryRWMutexConcurrent(t *testing.T) {
+	var (
+		m       TryRWMutex
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		readers int
+		writers int
+	)
+	check := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.True(t, writers == 0 || (writers == 1 && readers == 0))
+	}
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			m.RLock()
+			mu.Lock()
+			readers++
+			mu.Unlock()
+			check()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			readers--
+			mu.Unlock()
+			m.RUnlock()
+		}()
+		go func() {
+			defer wg.Done()
+			m.Lock()
+			mu.Lock()
+			writers++
+			mu.Unlock()
+			check()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			writers--
+			mu.Unlock()
+			m.Unlock()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTryRWMutexContext(t *testing.T) {
+	var m TryRWMutex
+	m.Lock()
+
+	// Waiting for the lock stops when the context is canceled.
+	ctx, cancel := context.WithCancel(context.Background())
+	locked := make(chan bool, 2)
+	go func() {
+		locked <- m.TryLockContext(ctx, time.Minute)
+	}()
+	go func() {
+		locked <- m.TryRLockContext(ctx, -1)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	assert.False(t, <-locked)
+	assert.False(t, <-locked)
+
+	// The abandoned attempts do not affect the lock.
+	m.Unlock()
+	assert.True(t, m.TryLockContext(context.Background(), 0))
+	m.Unlock()
+	assert.True(t, m.TryRLockContext(context.Background(), 0))
+	m.RUnlock()
+}
+
+func TestTryRWMutexUnlockPanics(t *testing.T) {
+	var m TryRWMutex
+	assert.Panics(t, m.Unlock)
+	assert.Panics(t, m.RUnlock)
+}
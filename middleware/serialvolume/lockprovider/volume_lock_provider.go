@@ -20,12 +20,15 @@ package lockprovider
 
 import (
 	"context"
+	"time"
 
 	"github.com/akutz/gosync"
 )
 
 // VolumeLockerProvider is able to provide gosync.TryLocker objects for
-// volumes by ID and name.
+// volumes by ID and name. Locks that implement io.Closer are closed by
+// their user once they are unlocked and no longer needed, which allows
+// a provider to reclaim the resources of idle locks.
 type VolumeLockerProvider interface {
 	// GetLockWithID gets a lock for a volume with provided ID. If a lock
 	// for the specified volume ID does not exist then a new lock is created
@@ -37,3 +40,85 @@ type VolumeLockerProvider interface {
 	// and returned.
 	GetLockWithName(ctx context.Context, name string) (gosync.TryLocker, error)
 }
+
+// TryRWLocker is a gosync.TryLocker that may also be locked for reading.
+// Any number of readers may hold the lock at the same time, but a writer
+// excludes both readers and other writers.
+type TryRWLocker interface {
+	gosync.TryLocker
+
+	// RLock locks the lock for reading.
+	RLock()
+
+	// RUnlock undoes a single RLock call.
+	RUnlock()
+
+	// TryRLock attempts to obtain a read lock and times out if no lock
+	// can be obtained in the specified duration. A flag is returned
+	// indicating whether or not the lock was obtained.
+	TryRLock(timeout time.Duration) bool
+}
+
+// ContextTryLocker is a gosync.TryLocker whose attempts to obtain the
+// lock may be abandoned when a context is done.
+type ContextTryLocker interface {
+	gosync.TryLocker
+
+	// TryLockContext attempts to obtain the lock and gives up when the
+	// specified duration elapses or when ctx is done, whichever happens
+	// first. A flag is returned indicating whether or not the lock was
+	// obtained.
+	TryLockContext(ctx context.Context, timeout time.Duration) bool
+}
+
+// ContextTryRWLocker is a TryRWLocker whose attempts to obtain the lock
+// may be abandoned when a context is done.
+type ContextTryRWLocker interface {
+	ContextTryLocker
+	TryRWLocker
+
+	// TryRLockContext attempts to obtain a read lock and gives up when
+	// the specified duration elapses or when ctx is done, whichever
+	// happens first. A flag is returned indicating whether or not the
+	// lock was obtained.
+	TryRLockContext(ctx context.Context, timeout time.Duration) bool
+}
+
+// RWVolumeLockerProvider is a VolumeLockerProvider that is also able to
+// provide shared/exclusive locks for volumes by ID. The lock returned by
+// GetRWLockWithID for a volume ID must exclude the lock returned by
+// GetLockWithID for the same ID.
+type RWVolumeLockerProvider interface {
+	VolumeLockerProvider
+
+	// GetRWLockWithID gets a shared/exclusive lock for a volume with the
+	// provided ID. If a lock for the specified volume ID does not exist
+	// then a new lock is created and returned.
+	GetRWLockWithID(ctx context.Context, id string) (TryRWLocker, error)
+}
+
+// SnapshotLockerProvider is able to provide gosync.TryLocker objects for
+// snapshots and group snapshots by ID and name. Lock providers that also
+// implement this interface enable the serialization of the snapshot RPCs
+// by snapshot.
+type SnapshotLockerProvider interface {
+	// GetLockWithSnapshotID gets a lock for a snapshot with the provided
+	// ID. If a lock for the specified snapshot ID does not exist then a
+	// new lock is created and returned.
+	GetLockWithSnapshotID(ctx context.Context, id string) (gosync.TryLocker, error)
+
+	// GetLockWithSnapshotName gets a lock for a snapshot with the provided
+	// name. If a lock for the specified snapshot name does not exist then
+	// a new lock is created and returned.
+	GetLockWithSnapshotName(ctx context.Context, name string) (gosync.TryLocker, error)
+
+	// GetLockWithGroupSnapshotID gets a lock for a group snapshot with the
+	// provided ID. If a lock for the specified group snapshot ID does not
+	// exist then a new lock is created and returned.
+	GetLockWithGroupSnapshotID(ctx context.Context, id string) (gosync.TryLocker, error)
+
+	// GetLockWithGroupSnapshotName gets a lock for a group snapshot with
+	// the provided name. If a lock for the specified group snapshot name
+	// does not exist then a new lock is created and returned.
+	GetLockWithGroupSnapshotName(ctx context.Context, name string) (gosync.TryLocker, error)
+}
@@ -0,0 +1,80 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package serialvolume
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// HeldLock is a lock held by an RPC serialized by the interceptor.
+type HeldLock struct {
+	RPC    string    `json:"rpc"`
+	Kind   string    `json:"kind"`
+	Key    string    `json:"key"`
+	Shared bool      `json:"shared"`
+	Since  time.Time `json:"since"`
+}
+
+// Registry records the locks held by the RPCs serialized by the
+// interceptors it is shared with. The zero value is not usable; use
+// NewRegistry.
+type Registry struct {
+	mu   sync.Mutex
+	next uint64
+	held map[uint64]HeldLock
+}
+
+// NewRegistry returns a new, empty lock registry.
+func NewRegistry() *Registry {
+	return &Registry{held: map[uint64]HeldLock{}}
+}
+
+// Held returns the locks that are held, oldest first.
+func (r *Registry) Held() []HeldLock {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := make([]uint64, 0, len(r.held))
+	for id := range r.held {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+
+	held := make([]HeldLock, len(ids))
+	for j, id := range ids {
+		held[j] = r.held[id]
+	}
+	return held
+}
+
+// add records a held lock and returns a function that removes it.
+func (r *Registry) add(l HeldLock) func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.next++
+	id := r.next
+	r.held[id] = l
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.held, id)
+	}
+}
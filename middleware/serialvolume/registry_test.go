@@ -0,0 +1,66 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package serialvolume
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	interceptor := New(
+		WithTimeout(time.Second),
+		WithLockProvider(&defaultLockProvider{}),
+		WithRegistry(r))
+
+	var held []HeldLock
+	handler := func(_ context.Context, _ interface{}) (interface{}, error) {
+		held = r.Held()
+		return &csi.CreateSnapshotResponse{}, nil
+	}
+	req := &csi.CreateSnapshotRequest{Name: "snap", SourceVolumeId: "vol"}
+	if _, err := interceptor(context.Background(), req,
+		&grpc.UnaryServerInfo{}, handler); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(held) != 2 {
+		t.Fatalf("expected 2 held locks, got %v", held)
+	}
+	for j, want := range []HeldLock{
+		{RPC: "CreateSnapshot", Kind: "snapshotName", Key: "snap"},
+		{RPC: "CreateSnapshot", Kind: "volumeID", Key: "vol"},
+	} {
+		got := held[j]
+		got.Since = time.Time{}
+		if got != want {
+			t.Errorf("held[%d]: expected %+v, got %+v", j, want, got)
+		}
+	}
+
+	// The locks are removed from the registry when they are released.
+	if held := r.Held(); len(held) != 0 {
+		t.Errorf("expected no held locks, got %v", held)
+	}
+}
@@ -21,6 +21,7 @@ package serialvolume
 import (
 	"context"
 	"io"
+	"sort"
 	"time"
 
 	"github.com/akutz/gosync"
@@ -34,12 +35,37 @@ import (
 
 const pending = "pending"
 
+// RPCs is the list of the names of the RPCs that are serialized by the
+// interceptor by default.
+var RPCs = []string{
+	"CreateVolume",
+	"DeleteVolume",
+	"ControllerPublishVolume",
+	"ControllerUnpublishVolume",
+	"ControllerExpandVolume",
+	"ControllerModifyVolume",
+	"ControllerGetVolume",
+	"ValidateVolumeCapabilities",
+	"CreateSnapshot",
+	"DeleteSnapshot",
+	"CreateVolumeGroupSnapshot",
+	"DeleteVolumeGroupSnapshot",
+	"NodeStageVolume",
+	"NodeUnstageVolume",
+	"NodePublishVolume",
+	"NodeUnpublishVolume",
+	"NodeExpandVolume",
+	"NodeGetVolumeStats",
+}
+
 // Option configures the interceptor.
 type Option func(*opts)
 
 type opts struct {
-	timeout time.Duration
-	locker  mwtypes.VolumeLockerProvider
+	timeout  time.Duration
+	locker   mwtypes.VolumeLockerProvider
+	rpcs     map[string]bool
+	registry *Registry
 }
 
 // WithTimeout is an Option that sets the timeout used by the interceptor.
@@ -57,6 +83,27 @@ func WithLockProvider(p mwtypes.VolumeLockerProvider) Option {
 	}
 }
 
+// WithRegistry is an Option that records the locks held by the RPCs in
+// the provided registry.
+func WithRegistry(r *Registry) Option {
+	return func(o *opts) {
+		o.registry = r
+	}
+}
+
+// WithRPCs is an Option that sets the names of the RPCs that are
+// serialized by the interceptor, ex. NodeStageVolume. The names of RPCs
+// that are not in the RPCs list are ignored. By default all of the RPCs
+// in the RPCs list are serialized.
+func WithRPCs(rpcs ...string) Option {
+	return func(o *opts) {
+		o.rpcs = map[string]bool{}
+		for _, rpc := range rpcs {
+			o.rpcs[rpc] = true
+		}
+	}
+}
+
 // New returns a new server-side, gRPC interceptor
 // that provides serial access to volume resources across the following
 // RPCs:
@@ -65,8 +112,29 @@ func WithLockProvider(p mwtypes.VolumeLockerProvider) Option {
 //   - DeleteVolume
 //   - ControllerPublishVolume
 //   - ControllerUnpublishVolume
+//   - ControllerExpandVolume
+//   - ControllerModifyVolume
+//   - CreateSnapshot
+//   - DeleteSnapshot
+//   - CreateVolumeGroupSnapshot
+//   - DeleteVolumeGroupSnapshot
+//   - NodeStageVolume
+//   - NodeUnstageVolume
 //   - NodePublishVolume
 //   - NodeUnpublishVolume
+//   - NodeExpandVolume
+//
+// CreateSnapshot and CreateVolumeGroupSnapshot lock their source volumes.
+// If the lock provider is a SnapshotLockerProvider then the snapshot RPCs
+// also lock their snapshots and group snapshots by name or ID.
+//
+// If the lock provider is a RWVolumeLockerProvider then the following
+// read-only RPCs obtain shared locks. These RPCs wait for the RPCs above
+// but do not wait for each other:
+//
+//   - ControllerGetVolume
+//   - ValidateVolumeCapabilities
+//   - NodeGetVolumeStats
 func New(opts ...Option) grpc.UnaryServerInterceptor {
 	i := &interceptor{}
 
@@ -78,165 +146,323 @@ func New(opts ...Option) grpc.UnaryServerInterceptor {
 	// If no lock provider is configured then set the default,
 	// in-memory provider.
 	if i.opts.locker == nil {
-		i.opts.locker = &defaultLockProvider{
-			volIDLocks:   map[string]gosync.TryLocker{},
-			volNameLocks: map[string]gosync.TryLocker{},
-		}
+		i.opts.locker = NewDefaultLockProvider()
 	}
 
 	return i.handle
 }
 
+// NewDefaultLockProvider returns a new instance of the in-memory lock
+// provider the interceptor uses when no lock provider is configured.
+// Sharing the provider between interceptors allows an interceptor to be
+// replaced with one that has different options without releasing the
+// locks held by RPCs that are in flight.
+func NewDefaultLockProvider() mwtypes.VolumeLockerProvider {
+	return &defaultLockProvider{}
+}
+
 type interceptor struct {
 	opts opts
 }
 
+// lockKind is the kind of resource a lock is for. The locks for a
+// request are obtained in the order of their kinds so that requests that
+// lock the same resources cannot deadlock.
+type lockKind int
+
+func (k lockKind) String() string {
+	switch k {
+	case groupSnapshotName:
+		return "groupSnapshotName"
+	case groupSnapshotID:
+		return "groupSnapshotID"
+	case snapshotName:
+		return "snapshotName"
+	case snapshotID:
+		return "snapshotID"
+	case volumeName:
+		return "volumeName"
+	case volumeID:
+		return "volumeID"
+	}
+	return "unknown"
+}
+
+const (
+	groupSnapshotName lockKind = iota
+	groupSnapshotID
+	snapshotName
+	snapshotID
+	volumeName
+	volumeID
+)
+
+type lockKey struct {
+	kind   lockKind
+	key    string
+	shared bool
+}
+
 func (i *interceptor) handle(
 	ctx context.Context,
 	req interface{},
-	info *grpc.UnaryServerInfo,
+	_ *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler,
 ) (interface{}, error) {
+	rpc, keys := lockKeys(req)
+	if rpc == "" || (i.opts.rpcs != nil && !i.opts.rpcs[rpc]) {
+		return handler(ctx, req)
+	}
+
+	unlock, err := i.lock(ctx, rpc, keys)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return handler(ctx, req)
+}
+
+// lockKeys returns the name of the RPC and the keys of the locks the
+// request requires. An empty name is returned for requests that are not
+// serialized.
+func lockKeys(req interface{}) (string, []lockKey) {
 	switch treq := req.(type) {
-	case *csi.ControllerPublishVolumeRequest:
-		return i.controllerPublishVolume(ctx, treq, info, handler)
-	case *csi.ControllerUnpublishVolumeRequest:
-		return i.controllerUnpublishVolume(ctx, treq, info, handler)
 	case *csi.CreateVolumeRequest:
-		return i.createVolume(ctx, treq, info, handler)
+		return "CreateVolume", []lockKey{{kind: volumeName, key: treq.Name}}
 	case *csi.DeleteVolumeRequest:
-		return i.deleteVolume(ctx, treq, info, handler)
+		return "DeleteVolume", []lockKey{{kind: volumeID, key: treq.VolumeId}}
+	case *csi.ControllerPublishVolumeRequest:
+		return "ControllerPublishVolume", []lockKey{{kind: volumeID, key: treq.VolumeId}}
+	case *csi.ControllerUnpublishVolumeRequest:
+		return "ControllerUnpublishVolume", []lockKey{{kind: volumeID, key: treq.VolumeId}}
+	case *csi.ControllerExpandVolumeRequest:
+		return "ControllerExpandVolume", []lockKey{{kind: volumeID, key: treq.VolumeId}}
+	case *csi.ControllerModifyVolumeRequest:
+		return "ControllerModifyVolume", []lockKey{{kind: volumeID, key: treq.VolumeId}}
+	case *csi.ControllerGetVolumeRequest:
+		return "ControllerGetVolume", []lockKey{{kind: volumeID, key: treq.VolumeId, shared: true}}
+	case *csi.ValidateVolumeCapabilitiesRequest:
+		return "ValidateVolumeCapabilities", []lockKey{{kind: volumeID, key: treq.VolumeId, shared: true}}
+	case *csi.CreateSnapshotRequest:
+		return "CreateSnapshot", []lockKey{
+			{kind: snapshotName, key: treq.Name},
+			{kind: volumeID, key: treq.SourceVolumeId},
+		}
+	case *csi.DeleteSnapshotRequest:
+		return "DeleteSnapshot", []lockKey{{kind: snapshotID, key: treq.SnapshotId}}
+	case *csi.CreateVolumeGroupSnapshotRequest:
+		keys := []lockKey{{kind: groupSnapshotName, key: treq.Name}}
+		for _, id := range treq.SourceVolumeIds {
+			keys = append(keys, lockKey{kind: volumeID, key: id})
+		}
+		return "CreateVolumeGroupSnapshot", keys
+	case *csi.DeleteVolumeGroupSnapshotRequest:
+		keys := []lockKey{{kind: groupSnapshotID, key: treq.GroupSnapshotId}}
+		for _, id := range treq.SnapshotIds {
+			keys = append(keys, lockKey{kind: snapshotID, key: id})
+		}
+		return "DeleteVolumeGroupSnapshot", keys
+	case *csi.NodeStageVolumeRequest:
+		return "NodeStageVolume", []lockKey{{kind: volumeID, key: treq.VolumeId}}
+	case *csi.NodeUnstageVolumeRequest:
+		return "NodeUnstageVolume", []lockKey{{kind: volumeID, key: treq.VolumeId}}
 	case *csi.NodePublishVolumeRequest:
-		return i.nodePublishVolume(ctx, treq, info, handler)
+		return "NodePublishVolume", []lockKey{{kind: volumeID, key: treq.VolumeId}}
 	case *csi.NodeUnpublishVolumeRequest:
-		return i.nodeUnpublishVolume(ctx, treq, info, handler)
+		return "NodeUnpublishVolume", []lockKey{{kind: volumeID, key: treq.VolumeId}}
+	case *csi.NodeExpandVolumeRequest:
+		return "NodeExpandVolume", []lockKey{{kind: volumeID, key: treq.VolumeId}}
+	case *csi.NodeGetVolumeStatsRequest:
+		return "NodeGetVolumeStats", []lockKey{{kind: volumeID, key: treq.VolumeId, shared: true}}
 	}
-
-	return handler(ctx, req)
+	return "", nil
 }
 
-func (i *interceptor) controllerPublishVolume(
+// lock obtains the locks for the provided keys and returns a function
+// that releases them. Empty and duplicate keys are ignored. If any lock
+// cannot be obtained then the locks that were obtained are released.
+func (i *interceptor) lock(
 	ctx context.Context,
-	req *csi.ControllerPublishVolumeRequest,
-	_ *grpc.UnaryServerInfo,
-	handler grpc.UnaryHandler,
-) (res interface{}, resErr error) {
-	lock, err := i.opts.locker.GetLockWithID(ctx, req.VolumeId)
-	if err != nil {
-		return nil, err
-	}
-	if closer, ok := lock.(io.Closer); ok {
-		defer closer.Close()
-	}
-	if !lock.TryLock(i.opts.timeout) {
-		return nil, status.Error(codes.Aborted, pending)
+	rpc string,
+	keys []lockKey,
+) (func(), error) {
+	sort.SliceStable(keys, func(a, b int) bool {
+		if keys[a].kind != keys[b].kind {
+			return keys[a].kind < keys[b].kind
+		}
+		return keys[a].key < keys[b].key
+	})
+
+	var unlocks []func()
+	unlock := func() {
+		for j := len(unlocks) - 1; j >= 0; j-- {
+			unlocks[j]()
+		}
 	}
-	defer lock.Unlock()
 
-	return handler(ctx, req)
+	for j, k := range keys {
+		if k.key == "" || (j > 0 && k == keys[j-1]) {
+			continue
+		}
+		u, err := i.lockOne(ctx, k)
+		if err != nil {
+			unlock()
+			return nil, err
+		}
+		if u == nil {
+			continue
+		}
+		if r := i.opts.registry; r != nil {
+			remove := r.add(HeldLock{
+				RPC:    rpc,
+				Kind:   k.kind.String(),
+				Key:    k.key,
+				Shared: k.shared,
+				Since:  time.Now(),
+			})
+			unlockOne := u
+			u = func() {
+				remove()
+				unlockOne()
+			}
+		}
+		unlocks = append(unlocks, u)
+	}
+	return unlock, nil
 }
 
-func (i *interceptor) controllerUnpublishVolume(
+// lockOne obtains the lock for the provided key and returns a function
+// that releases it. A nil function is returned if the lock provider is
+// not able to provide the lock.
+func (i *interceptor) lockOne(
 	ctx context.Context,
-	req *csi.ControllerUnpublishVolumeRequest,
-	_ *grpc.UnaryServerInfo,
-	handler grpc.UnaryHandler,
-) (res interface{}, resErr error) {
-	lock, err := i.opts.locker.GetLockWithID(ctx, req.VolumeId)
-	if err != nil {
-		return nil, err
-	}
-	if closer, ok := lock.(io.Closer); ok {
-		defer closer.Close()
+	k lockKey,
+) (func(), error) {
+	if k.shared {
+		return i.rlockOne(ctx, k)
 	}
-	if !lock.TryLock(i.opts.timeout) {
-		return nil, status.Error(codes.Aborted, pending)
-	}
-	defer lock.Unlock()
-
-	return handler(ctx, req)
-}
 
-func (i *interceptor) createVolume(
-	ctx context.Context,
-	req *csi.CreateVolumeRequest,
-	_ *grpc.UnaryServerInfo,
-	handler grpc.UnaryHandler,
-) (res interface{}, resErr error) {
-	lock, err := i.opts.locker.GetLockWithName(ctx, req.Name)
+	var (
+		lock gosync.TryLocker
+		err  error
+	)
+	switch k.kind {
+	case volumeID:
+		lock, err = i.opts.locker.GetLockWithID(ctx, k.key)
+	case volumeName:
+		lock, err = i.opts.locker.GetLockWithName(ctx, k.key)
+	default:
+		p, ok := i.opts.locker.(mwtypes.SnapshotLockerProvider)
+		if !ok {
+			return nil, nil
+		}
+		switch k.kind {
+		case snapshotID:
+			lock, err = p.GetLockWithSnapshotID(ctx, k.key)
+		case snapshotName:
+			lock, err = p.GetLockWithSnapshotName(ctx, k.key)
+		case groupSnapshotID:
+			lock, err = p.GetLockWithGroupSnapshotID(ctx, k.key)
+		case groupSnapshotName:
+			lock, err = p.GetLockWithGroupSnapshotName(ctx, k.key)
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
-	if closer, ok := lock.(io.Closer); ok {
-		defer closer.Close()
-	}
-	if !lock.TryLock(i.opts.timeout) {
-		return nil, status.Error(codes.Aborted, pending)
-	}
-	defer lock.Unlock()
 
-	return handler(ctx, req)
+	if err := i.tryLock(ctx, lock, false); err != nil {
+		closeLock(lock)
+		return nil, err
+	}
+	return func() {
+		lock.Unlock()
+		closeLock(lock)
+	}, nil
 }
 
-func (i *interceptor) deleteVolume(
+// rlockOne obtains a shared lock for the provided volume ID key. A nil
+// function is returned if the lock provider does not provide shared
+// locks.
+func (i *interceptor) rlockOne(
 	ctx context.Context,
-	req *csi.DeleteVolumeRequest,
-	_ *grpc.UnaryServerInfo,
-	handler grpc.UnaryHandler,
-) (res interface{}, resErr error) {
-	lock, err := i.opts.locker.GetLockWithID(ctx, req.VolumeId)
+	k lockKey,
+) (func(), error) {
+	p, ok := i.opts.locker.(mwtypes.RWVolumeLockerProvider)
+	if !ok || k.kind != volumeID {
+		return nil, nil
+	}
+	lock, err := p.GetRWLockWithID(ctx, k.key)
 	if err != nil {
 		return nil, err
 	}
-	if closer, ok := lock.(io.Closer); ok {
-		defer closer.Close()
-	}
-	if !lock.TryLock(i.opts.timeout) {
-		return nil, status.Error(codes.Aborted, pending)
+	if err := i.tryLock(ctx, lock, true); err != nil {
+		closeLock(lock)
+		return nil, err
 	}
-	defer lock.Unlock()
-
-	return handler(ctx, req)
+	return func() {
+		lock.RUnlock()
+		closeLock(lock)
+	}, nil
 }
 
-func (i *interceptor) nodePublishVolume(
+// tryLock obtains the lock for reading or writing. It waits for the
+// interceptor's timeout or until ctx is done, whichever happens first. A
+// gRPC Canceled or DeadlineExceeded error is returned if ctx is done
+// before the lock is obtained, otherwise Aborted is returned if the lock
+// is not obtained. Locks that do not accept a context only wait until
+// the context's deadline.
+func (i *interceptor) tryLock(
 	ctx context.Context,
-	req *csi.NodePublishVolumeRequest,
-	_ *grpc.UnaryServerInfo,
-	handler grpc.UnaryHandler,
-) (res interface{}, resErr error) {
-	lock, err := i.opts.locker.GetLockWithID(ctx, req.VolumeId)
-	if err != nil {
-		return nil, err
+	lock gosync.TryLocker,
+	read bool,
+) error {
+	if err := ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
 	}
-	if closer, ok := lock.(io.Closer); ok {
-		defer closer.Close()
+
+	var (
+		locked bool
+		capped bool
+	)
+	rwl, rwOK := lock.(mwtypes.ContextTryRWLocker)
+	l, ok := lock.(mwtypes.ContextTryLocker)
+	switch {
+	case rwOK && read:
+		locked = rwl.TryRLockContext(ctx, i.opts.timeout)
+	case ok && !read:
+		locked = l.TryLockContext(ctx, i.opts.timeout)
+	default:
+		timeout := i.opts.timeout
+		if deadline, ok := ctx.Deadline(); ok {
+			if d := time.Until(deadline); d < timeout {
+				timeout, capped = max(d, 0), true
+			}
+		}
+		if read {
+			locked = lock.(mwtypes.TryRWLocker).TryRLock(timeout)
+		} else {
+			locked = lock.TryLock(timeout)
+		}
 	}
-	if !lock.TryLock(i.opts.timeout) {
-		return nil, status.Error(codes.Aborted, pending)
+	if locked {
+		return nil
 	}
-	defer lock.Unlock()
 
-	return handler(ctx, req)
+	if err := ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	if capped {
+		return status.FromContextError(context.DeadlineExceeded).Err()
+	}
+	return status.Error(codes.Aborted, pending)
 }
 
-func (i *interceptor) nodeUnpublishVolume(
-	ctx context.Context,
-	req *csi.NodeUnpublishVolumeRequest,
-	_ *grpc.UnaryServerInfo,
-	handler grpc.UnaryHandler,
-) (res interface{}, resErr error) {
-	lock, err := i.opts.locker.GetLockWithID(ctx, req.VolumeId)
-	if err != nil {
-		return nil, err
-	}
+// closeLock closes locks that must be closed after use, ex. the etcd
+// provider's locks.
+func closeLock(lock interface{}) {
 	if closer, ok := lock.(io.Closer); ok {
-		defer closer.Close()
+		closer.Close()
 	}
-	if !lock.TryLock(i.opts.timeout) {
-		return nil, status.Error(codes.Aborted, pending)
-	}
-	defer lock.Unlock()
-
-	return handler(ctx, req)
 }
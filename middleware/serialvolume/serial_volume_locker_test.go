@@ -28,13 +28,12 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	mwtypes "github.com/dell/gocsi/middleware/serialvolume/lockprovider"
 )
 
 func TestCreateVolume(t *testing.T) {
-	locker := &defaultLockProvider{
-		volIDLocks:   map[string]gosync.TryLocker{},
-		volNameLocks: map[string]gosync.TryLocker{},
-	}
+	locker := &defaultLockProvider{}
 	interceptor := New(WithTimeout(1*time.Second), WithLockProvider(locker))
 
 	handler := func(_ context.Context, _ interface{}) (interface{}, error) {
@@ -319,3 +318,544 @@ func (m *MockLock) Unlock() {
 func (m *MockLock) Close() error {
 	return nil
 }
+
+func TestReadVolume(t *testing.T) {
+	interceptor := New(WithTimeout(10 * time.Millisecond))
+
+	reqs := []interface{}{
+		&csi.ControllerGetVolumeRequest{VolumeId: "test-volume"},
+		&csi.ValidateVolumeCapabilitiesRequest{VolumeId: "test-volume"},
+		&csi.NodeGetVolumeStatsRequest{VolumeId: "test-volume"},
+	}
+	info := &grpc.UnaryServerInfo{}
+
+	// Hold a read lock with a blocked read RPC.
+	release := make(chan struct{})
+	started := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		_, err := interceptor(context.Background(), reqs[0], info,
+			func(_ context.Context, _ interface{}) (interface{}, error) {
+				close(started)
+				<-release
+				return &csi.ControllerGetVolumeResponse{}, nil
+			})
+		done <- err
+	}()
+	<-started
+
+	// Read RPCs share the lock.
+	for _, req := range reqs {
+		_, err := interceptor(context.Background(), req, info,
+			func(_ context.Context, _ interface{}) (interface{}, error) {
+				return nil, nil
+			})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	// Mutating RPCs wait for the read RPCs.
+	_, err := interceptor(context.Background(),
+		&csi.NodePublishVolumeRequest{VolumeId: "test-volume"}, info,
+		func(_ context.Context, _ interface{}) (interface{}, error) {
+			return &csi.NodePublishVolumeResponse{}, nil
+		})
+	if status.Code(err) != codes.Aborted {
+		t.Fatalf("expected Aborted error, got %v", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestReadVolumeWaitsForWriter(t *testing.T) {
+	interceptor := New(WithTimeout(10 * time.Millisecond))
+	info := &grpc.UnaryServerInfo{}
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		_, err := interceptor(context.Background(),
+			&csi.NodeUnpublishVolumeRequest{VolumeId: "test-volume"}, info,
+			func(_ context.Context, _ interface{}) (interface{}, error) {
+				close(started)
+				<-release
+				return &csi.NodeUnpublishVolumeResponse{}, nil
+			})
+		done <- err
+	}()
+	<-started
+
+	_, err := interceptor(context.Background(),
+		&csi.NodeGetVolumeStatsRequest{VolumeId: "test-volume"}, info,
+		func(_ context.Context, _ interface{}) (interface{}, error) {
+			return &csi.NodeGetVolumeStatsResponse{}, nil
+		})
+	if status.Code(err) != codes.Aborted {
+		t.Fatalf("expected Aborted error, got %v", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestReadVolumeExclusiveProvider(t *testing.T) {
+	// Providers without shared locks do not lock read RPCs.
+	locker := &MockVolumeLockerProvider{locks: make(map[string]bool)}
+	interceptor := New(WithLockProvider(locker))
+
+	lock, _ := locker.GetLockWithID(context.Background(), "test-volume")
+	lock.TryLock(0)
+
+	_, err := interceptor(context.Background(),
+		&csi.NodeGetVolumeStatsRequest{VolumeId: "test-volume"},
+		&grpc.UnaryServerInfo{},
+		func(_ context.Context, _ interface{}) (interface{}, error) {
+			return &csi.NodeGetVolumeStatsResponse{}, nil
+		})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+// holdLocks handles the request with a handler that blocks until the
+// returned function is called.
+func holdLocks(
+	t *testing.T,
+	interceptor grpc.UnaryServerInterceptor,
+	req interface{},
+) func() {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		_, err := interceptor(context.Background(), req, &grpc.UnaryServerInfo{},
+			func(_ context.Context, _ interface{}) (interface{}, error) {
+				close(started)
+				<-release
+				return nil, nil
+			})
+		done <- err
+	}()
+	<-started
+	return func() {
+		close(release)
+		if err := <-done; err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+}
+
+func handleRequest(
+	interceptor grpc.UnaryServerInterceptor,
+	req interface{},
+) error {
+	_, err := interceptor(context.Background(), req, &grpc.UnaryServerInfo{},
+		func(_ context.Context, _ interface{}) (interface{}, error) {
+			return nil, nil
+		})
+	return err
+}
+
+// timeoutLockProvider provides plain gosync.TryMutex locks that do not
+// accept a context.
+type timeoutLockProvider struct {
+	lock gosync.TryMutex
+}
+
+func (p *timeoutLockProvider) GetLockWithID(
+	_ context.Context, _ string,
+) (gosync.TryLocker, error) {
+	return &p.lock, nil
+}
+
+func (p *timeoutLockProvider) GetLockWithName(
+	_ context.Context, _ string,
+) (gosync.TryLocker, error) {
+	return &p.lock, nil
+}
+
+func TestLockContext(t *testing.T) {
+	tests := []struct {
+		name     string
+		locker   mwtypes.VolumeLockerProvider
+		req      interface{}
+		cancel   bool
+		deadline time.Duration
+		code     codes.Code
+	}{
+		{
+			name:   "canceled",
+			locker: &defaultLockProvider{},
+			req:    &csi.NodeStageVolumeRequest{VolumeId: "vol-1"},
+			cancel: true,
+			code:   codes.Canceled,
+		},
+		{
+			name:   "canceled shared",
+			locker: &defaultLockProvider{},
+			req:    &csi.ControllerGetVolumeRequest{VolumeId: "vol-1"},
+			cancel: true,
+			code:   codes.Canceled,
+		},
+		{
+			name:     "deadline exceeded",
+			locker:   &defaultLockProvider{},
+			req:      &csi.NodeStageVolumeRequest{VolumeId: "vol-1"},
+			deadline: 20 * time.Millisecond,
+			code:     codes.DeadlineExceeded,
+		},
+		{
+			name:     "deadline exceeded without context locks",
+			locker:   &timeoutLockProvider{},
+			req:      &csi.NodeStageVolumeRequest{VolumeId: "vol-1"},
+			deadline: 20 * time.Millisecond,
+			code:     codes.DeadlineExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := New(WithTimeout(time.Minute),
+				WithLockProvider(tt.locker))
+			release := holdLocks(t, interceptor,
+				&csi.NodePublishVolumeRequest{VolumeId: "vol-1"})
+			defer release()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.deadline > 0 {
+				ctx, cancel = context.WithTimeout(ctx, tt.deadline)
+				defer cancel()
+			}
+			if tt.cancel {
+				time.AfterFunc(20*time.Millisecond, cancel)
+			}
+
+			start := time.Now()
+			_, err := interceptor(ctx, tt.req, &grpc.UnaryServerInfo{},
+				func(_ context.Context, _ interface{}) (interface{}, error) {
+					return nil, nil
+				})
+			if status.Code(err) != tt.code {
+				t.Fatalf("expected %v error, got %v", tt.code, err)
+			}
+			if d := time.Since(start); d > 10*time.Second {
+				t.Fatalf("expected lock attempt to stop early, took %v", d)
+			}
+		})
+	}
+
+	// A request whose context is already done does not wait at all.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := New()(ctx, &csi.NodeStageVolumeRequest{VolumeId: "vol-1"},
+		&grpc.UnaryServerInfo{},
+		func(_ context.Context, _ interface{}) (interface{}, error) {
+			return nil, nil
+		})
+	if status.Code(err) != codes.Canceled {
+		t.Fatalf("expected Canceled error, got %v", err)
+	}
+}
+
+func TestSerializedRPCs(t *testing.T) {
+	tests := []struct {
+		name     string
+		held     interface{}
+		req      interface{}
+		rejected bool
+	}{
+		{
+			name:     "stage waits for publish",
+			held:     &csi.NodePublishVolumeRequest{VolumeId: "vol-1"},
+			req:      &csi.NodeStageVolumeRequest{VolumeId: "vol-1"},
+			rejected: true,
+		},
+		{
+			name:     "unstage waits for stage",
+			held:     &csi.NodeStageVolumeRequest{VolumeId: "vol-1"},
+			req:      &csi.NodeUnstageVolumeRequest{VolumeId: "vol-1"},
+			rejected: true,
+		},
+		{
+			name:     "controller expand waits for publish",
+			held:     &csi.ControllerPublishVolumeRequest{VolumeId: "vol-1"},
+			req:      &csi.ControllerExpandVolumeRequest{VolumeId: "vol-1"},
+			rejected: true,
+		},
+		{
+			name:     "node expand waits for stage",
+			held:     &csi.NodeStageVolumeRequest{VolumeId: "vol-1"},
+			req:      &csi.NodeExpandVolumeRequest{VolumeId: "vol-1"},
+			rejected: true,
+		},
+		{
+			name:     "modify waits for delete",
+			held:     &csi.DeleteVolumeRequest{VolumeId: "vol-1"},
+			req:      &csi.ControllerModifyVolumeRequest{VolumeId: "vol-1"},
+			rejected: true,
+		},
+		{
+			name: "different volumes",
+			held: &csi.NodeStageVolumeRequest{VolumeId: "vol-1"},
+			req:  &csi.NodeStageVolumeRequest{VolumeId: "vol-2"},
+		},
+		{
+			name:     "snapshot waits for its source volume",
+			held:     &csi.ControllerExpandVolumeRequest{VolumeId: "vol-1"},
+			req:      &csi.CreateSnapshotRequest{Name: "snap", SourceVolumeId: "vol-1"},
+			rejected: true,
+		},
+		{
+			name: "snapshot name",
+			held: &csi.CreateSnapshotRequest{Name: "snap", SourceVolumeId: "vol-1"},
+			req: &csi.CreateSnapshotRequest{
+				Name: "snap", SourceVolumeId: "vol-2",
+			},
+			rejected: true,
+		},
+		{
+			name: "different snapshot names",
+			held: &csi.CreateSnapshotRequest{Name: "snap-1", SourceVolumeId: "vol-1"},
+			req: &csi.CreateSnapshotRequest{
+				Name: "snap-2", SourceVolumeId: "vol-2",
+			},
+		},
+		{
+			name:     "snapshot ID",
+			held:     &csi.DeleteSnapshotRequest{SnapshotId: "snap-1"},
+			req:      &csi.DeleteSnapshotRequest{SnapshotId: "snap-1"},
+			rejected: true,
+		},
+		{
+			name: "snapshot ID and volume ID are different keys",
+			held: &csi.DeleteSnapshotRequest{SnapshotId: "id-1"},
+			req:  &csi.DeleteVolumeRequest{VolumeId: "id-1"},
+		},
+		{
+			name: "group snapshot waits for its source volumes",
+			held: &csi.NodeStageVolumeRequest{VolumeId: "vol-2"},
+			req: &csi.CreateVolumeGroupSnapshotRequest{
+				Name: "group", SourceVolumeIds: []string{"vol-1", "vol-2"},
+			},
+			rejected: true,
+		},
+		{
+			name: "group snapshot name",
+			held: &csi.CreateVolumeGroupSnapshotRequest{
+				Name: "group", SourceVolumeIds: []string{"vol-1"},
+			},
+			req: &csi.CreateVolumeGroupSnapshotRequest{
+				Name: "group", SourceVolumeIds: []string{"vol-2"},
+			},
+			rejected: true,
+		},
+		{
+			name: "group snapshot delete waits for its snapshots",
+			held: &csi.DeleteSnapshotRequest{SnapshotId: "snap-2"},
+			req: &csi.DeleteVolumeGroupSnapshotRequest{
+				GroupSnapshotId: "group-1",
+				SnapshotIds:     []string{"snap-1", "snap-2"},
+			},
+			rejected: true,
+		},
+		{
+			name: "group snapshot ID",
+			held: &csi.DeleteVolumeGroupSnapshotRequest{GroupSnapshotId: "group-1"},
+			req: &csi.DeleteVolumeGroupSnapshotRequest{
+				GroupSnapshotId: "group-1",
+			},
+			rejected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := New(WithTimeout(10 * time.Millisecond))
+			release := holdLocks(t, interceptor, tt.held)
+			defer release()
+
+			err := handleRequest(interceptor, tt.req)
+			if tt.rejected && status.Code(err) != codes.Aborted {
+				t.Fatalf("expected Aborted error, got %v", err)
+			}
+			if !tt.rejected && err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLockReleasedOnFailure(t *testing.T) {
+	interceptor := New(WithTimeout(10 * time.Millisecond))
+	release := holdLocks(t, interceptor,
+		&csi.NodeStageVolumeRequest{VolumeId: "vol-2"})
+
+	// The group snapshot obtains its name and vol-1 locks before it fails
+	// to lock vol-2.
+	err := handleRequest(interceptor, &csi.CreateVolumeGroupSnapshotRequest{
+		Name: "group", SourceVolumeIds: []string{"vol-2", "vol-1", "vol-1"},
+	})
+	if status.Code(err) != codes.Aborted {
+		t.Fatalf("expected Aborted error, got %v", err)
+	}
+	release()
+
+	// The locks obtained by the failed RPC were released.
+	for _, req := range []interface{}{
+		&csi.NodeStageVolumeRequest{VolumeId: "vol-1"},
+		&csi.CreateVolumeGroupSnapshotRequest{Name: "group"},
+	} {
+		if err := handleRequest(interceptor, req); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+}
+
+func TestWithRPCs(t *testing.T) {
+	interceptor := New(WithTimeout(10*time.Millisecond),
+		WithRPCs("NodePublishVolume"))
+	release := holdLocks(t, interceptor,
+		&csi.NodePublishVolumeRequest{VolumeId: "vol-1"})
+	defer release()
+
+	// NodeStageVolume does not participate so it is not serialized.
+	if err := handleRequest(interceptor,
+		&csi.NodeStageVolumeRequest{VolumeId: "vol-1"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	err := handleRequest(interceptor,
+		&csi.NodePublishVolumeRequest{VolumeId: "vol-1"})
+	if status.Code(err) != codes.Aborted {
+		t.Fatalf("expected Aborted error, got %v", err)
+	}
+}
+
+func TestSnapshotsWithoutSnapshotLocks(t *testing.T) {
+	// Providers without snapshot locks only lock the source volumes.
+	locker := &MockVolumeLockerProvider{locks: make(map[string]bool)}
+	interceptor := New(WithLockProvider(locker))
+
+	for _, req := range []interface{}{
+		&csi.CreateSnapshotRequest{Name: "snap", SourceVolumeId: "vol-1"},
+		&csi.DeleteSnapshotRequest{SnapshotId: "snap-1"},
+	} {
+		if err := handleRequest(interceptor, req); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if locked, ok := locker.locks["vol-1"]; !ok || locked {
+		t.Fatalf("expected the source volume to be locked and unlocked")
+	}
+}
+
+func TestLockKeys(t *testing.T) {
+	for _, rpc := range RPCs {
+		found := false
+		for _, req := range []interface{}{
+			&csi.CreateVolumeRequest{},
+			&csi.DeleteVolumeRequest{},
+			&csi.ControllerPublishVolumeRequest{},
+			&csi.ControllerUnpublishVolumeRequest{},
+			&csi.ControllerExpandVolumeRequest{},
+			&csi.ControllerModifyVolumeRequest{},
+			&csi.ControllerGetVolumeRequest{},
+			&csi.ValidateVolumeCapabilitiesRequest{},
+			&csi.CreateSnapshotRequest{},
+			&csi.DeleteSnapshotRequest{},
+			&csi.CreateVolumeGroupSnapshotRequest{},
+			&csi.DeleteVolumeGroupSnapshotRequest{},
+			&csi.NodeStageVolumeRequest{},
+			&csi.NodeUnstageVolumeRequest{},
+			&csi.NodePublishVolumeRequest{},
+			&csi.NodeUnpublishVolumeRequest{},
+			&csi.NodeExpandVolumeRequest{},
+			&csi.NodeGetVolumeStatsRequest{},
+		} {
+			if name, _ := lockKeys(req); name == rpc {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("no request for RPC %s", rpc)
+		}
+	}
+	if name, keys := lockKeys(&csi.ProbeRequest{}); name != "" || keys != nil {
+		t.Errorf("expected Probe to not be serialized")
+	}
+}
+
+// writeContextRWMutex is a shared lock that accepts a context only when
+// it is locked for writing.
+type writeContextRWMutex struct {
+	mu mwtypes.TryRWMutex
+}
+
+func (m *writeContextRWMutex) Lock()                         { m.mu.Lock() }
+func (m *writeContextRWMutex) Unlock()                       { m.mu.Unlock() }
+func (m *writeContextRWMutex) RLock()                        { m.mu.RLock() }
+func (m *writeContextRWMutex) RUnlock()                      { m.mu.RUnlock() }
+func (m *writeContextRWMutex) TryLock(d time.Duration) bool  { return m.mu.TryLock(d) }
+func (m *writeContextRWMutex) TryRLock(d time.Duration) bool { return m.mu.TryRLock(d) }
+
+func (m *writeContextRWMutex) TryLockContext(
+	ctx context.Context, d time.Duration,
+) bool {
+	return m.mu.TryLockContext(ctx, d)
+}
+
+// writeContextLockProvider provides a single writeContextRWMutex.
+type writeContextLockProvider struct {
+	lock writeContextRWMutex
+}
+
+func (p *writeContextLockProvider) GetLockWithID(
+	_ context.Context, _ string,
+) (gosync.TryLocker, error) {
+	return &p.lock, nil
+}
+
+func (p *writeContextLockProvider) GetLockWithName(
+	_ context.Context, _ string,
+) (gosync.TryLocker, error) {
+	return &p.lock, nil
+}
+
+func (p *writeContextLockProvider) GetRWLockWithID(
+	_ context.Context, _ string,
+) (mwtypes.TryRWLocker, error) {
+	return &p.lock, nil
+}
+
+func TestReadVolumeWriteContextLock(t *testing.T) {
+	interceptor := New(WithTimeout(10*time.Millisecond),
+		WithLockProvider(&writeContextLockProvider{}))
+
+	// Read RPCs share the lock even though it only accepts a context
+	// for writing.
+	release := holdLocks(t, interceptor,
+		&csi.ControllerGetVolumeRequest{VolumeId: "test-volume"})
+	if err := handleRequest(interceptor,
+		&csi.NodeGetVolumeStatsRequest{VolumeId: "test-volume"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Mutating RPCs wait for the read RPCs.
+	err := handleRequest(interceptor,
+		&csi.NodePublishVolumeRequest{VolumeId: "test-volume"})
+	if status.Code(err) != codes.Aborted {
+		t.Fatalf("expected Aborted error, got %v", err)
+	}
+	release()
+
+	if err := handleRequest(interceptor,
+		&csi.NodePublishVolumeRequest{VolumeId: "test-volume"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
@@ -180,6 +180,17 @@ func NewClientSpecValidator(
 	return newSpecValidator(opts...).handleClient
 }
 
+// ValidateResponse validates a response against the CSI specification
+// outside of an RPC. The options that configure response validation,
+// such as WithDisableFieldLenCheck, apply.
+func ValidateResponse(
+	ctx context.Context,
+	rep interface{},
+	opts ...Option,
+) error {
+	return newSpecValidator(opts...).validateResponse(ctx, "", rep)
+}
+
 func newSpecValidator(opts ...Option) *interceptor {
 	i := &interceptor{}
 	for _, withOpts := range opts {
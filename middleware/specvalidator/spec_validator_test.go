@@ -1579,3 +1579,25 @@ func TestHandle(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateResponse(t *testing.T) {
+	ctx := context.Background()
+	assert.NoError(t, ValidateResponse(ctx, &csi.GetPluginInfoResponse{
+		Name:          "csi.example.com",
+		VendorVersion: "1.0.0",
+	}))
+
+	err := ValidateResponse(ctx, &csi.GetPluginInfoResponse{
+		Name:          "example",
+		VendorVersion: "1.0.0",
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.ErrorContains(t, err, "invalid: Name=example")
+
+	nodeID := strings.Repeat("n", 300)
+	assert.Error(t, ValidateResponse(ctx,
+		&csi.NodeGetInfoResponse{NodeId: nodeID}))
+	assert.NoError(t, ValidateResponse(ctx,
+		&csi.NodeGetInfoResponse{NodeId: nodeID},
+		WithDisableFieldLenCheck()))
+}
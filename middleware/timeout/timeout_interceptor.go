@@ -0,0 +1,167 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package timeout
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	csictx "github.com/dell/gocsi/context"
+	"github.com/dell/gocsi/utils/rpcs"
+)
+
+// Option configures the interceptor.
+type Option func(*opts)
+
+type opts struct {
+	defaultTimeout time.Duration
+	maxTimeout     time.Duration
+	methodDefaults map[string]time.Duration
+	methodMaxes    map[string]time.Duration
+}
+
+// WithDefaultTimeout is an Option that sets the timeout of the RPCs that
+// have no deadline.
+func WithDefaultTimeout(d time.Duration) Option {
+	return func(o *opts) {
+		o.defaultTimeout = d
+	}
+}
+
+// WithMaxTimeout is an Option that sets the maximum timeout of the RPCs.
+// The deadline of an RPC that expires later is shortened.
+func WithMaxTimeout(d time.Duration) Option {
+	return func(o *opts) {
+		o.maxTimeout = d
+	}
+}
+
+// WithMethodDefaultTimeout is an Option that sets the timeout of the RPCs
+// of the provided method, ex. "CreateVolume", that have no deadline. It
+// takes precedence over WithDefaultTimeout.
+func WithMethodDefaultTimeout(method string, d time.Duration) Option {
+	return func(o *opts) {
+		if o.methodDefaults == nil {
+			o.methodDefaults = map[string]time.Duration{}
+		}
+		o.methodDefaults[method] = d
+	}
+}
+
+// WithMethodMaxTimeout is an Option that sets the maximum timeout of the
+// RPCs of the provided method. It takes precedence over WithMaxTimeout.
+func WithMethodMaxTimeout(method string, d time.Duration) Option {
+	return func(o *opts) {
+		if o.methodMaxes == nil {
+			o.methodMaxes = map[string]time.Duration{}
+		}
+		o.methodMaxes[method] = d
+	}
+}
+
+type interceptor struct {
+	opts opts
+}
+
+// NewServerTimeout returns a new UnaryServerInterceptor that enforces a
+// deadline on the context of the RPCs' handlers. An RPC without a
+// deadline is given the default timeout of its method, and the deadline
+// of an RPC is shortened to the maximum timeout of its method.
+//
+// A handler that returns after its deadline expired is logged, and the
+// RPC fails with codes.DeadlineExceeded. The interceptor waits for the
+// handler to return so the resources it holds, such as volume locks, are
+// not released early; handlers must return when their context is done.
+func NewServerTimeout(opts ...Option) grpc.UnaryServerInterceptor {
+	i := &interceptor{}
+	for _, withOpts := range opts {
+		withOpts(&i.opts)
+	}
+	return i.handle
+}
+
+// timeouts returns the default and maximum timeouts of the method.
+func (i *interceptor) timeouts(fullMethod string) (def, max time.Duration) {
+	def, max = i.opts.defaultTimeout, i.opts.maxTimeout
+	if _, _, method, err := rpcs.ParseMethod(fullMethod); err == nil {
+		if d, ok := i.opts.methodDefaults[method]; ok {
+			def = d
+		}
+		if d, ok := i.opts.methodMaxes[method]; ok {
+			max = d
+		}
+	}
+	if max > 0 && (def <= 0 || def > max) {
+		def = max
+	}
+	return def, max
+}
+
+func (i *interceptor) handle(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	def, max := i.timeouts(info.FullMethod)
+
+	var budget time.Duration
+	if deadline, ok := ctx.Deadline(); ok {
+		if max <= 0 || time.Until(deadline) <= max {
+			return handler(ctx, req)
+		}
+		budget = max
+	} else {
+		if def <= 0 {
+			return handler(ctx, req)
+		}
+		budget = def
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, budget)
+	defer cancel()
+
+	start := time.Now()
+	rep, err := handler(ctx, req)
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return rep, err
+	}
+
+	fields := map[string]interface{}{
+		"method":  info.FullMethod,
+		"timeout": budget,
+		"elapsed": time.Since(start),
+	}
+	if id, ok := csictx.GetRequestID(ctx); ok {
+		fields["requestID"] = id
+	}
+	log.WithFields(fields).Warn("rpc exceeded its timeout")
+
+	if status.Code(err) == codes.DeadlineExceeded {
+		return nil, err
+	}
+	return nil, status.Errorf(codes.DeadlineExceeded,
+		"%s exceeded its timeout of %v", info.FullMethod, budget)
+}
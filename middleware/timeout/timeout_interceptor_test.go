@@ -0,0 +1,130 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package timeout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	createVolume = "/csi.v1.Controller/CreateVolume"
+	deleteVolume = "/csi.v1.Controller/DeleteVolume"
+)
+
+func TestTimeouts(t *testing.T) {
+	i := &interceptor{}
+	for _, o := range []Option{
+		WithDefaultTimeout(time.Minute),
+		WithMaxTimeout(time.Hour),
+		WithMethodDefaultTimeout("CreateVolume", 5*time.Minute),
+		WithMethodMaxTimeout("DeleteVolume", 30*time.Second),
+	} {
+		o(&i.opts)
+	}
+
+	tests := []struct {
+		method  string
+		wantDef time.Duration
+		wantMax time.Duration
+	}{
+		{createVolume, 5 * time.Minute, time.Hour},
+		{deleteVolume, 30 * time.Second, 30 * time.Second},
+		{"/csi.v1.Node/NodeGetInfo", time.Minute, time.Hour},
+		{"/ext.Service/Method", time.Minute, time.Hour},
+	}
+	for _, tt := range tests {
+		def, max := i.timeouts(tt.method)
+		assert.Equal(t, tt.wantDef, def, tt.method)
+		assert.Equal(t, tt.wantMax, max, tt.method)
+	}
+}
+
+// deadline returns a handler that records the remaining time of its
+// context's deadline.
+func deadline(remaining *time.Duration) grpc.UnaryHandler {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		*remaining = 0
+		if d, ok := ctx.Deadline(); ok {
+			*remaining = time.Until(d)
+		}
+		return "ok", nil
+	}
+}
+
+func TestServerTimeout(t *testing.T) {
+	i := NewServerTimeout(
+		WithDefaultTimeout(time.Minute),
+		WithMaxTimeout(time.Hour))
+	info := &grpc.UnaryServerInfo{FullMethod: createVolume}
+
+	// The default timeout applies to an RPC without a deadline.
+	var remaining time.Duration
+	_, err := i(context.Background(), nil, info, deadline(&remaining))
+	assert.NoError(t, err)
+	assert.InDelta(t, time.Minute, remaining, float64(time.Second))
+
+	// The deadline of an RPC that expires before the maximum is kept.
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	_, err = i(ctx, nil, info, deadline(&remaining))
+	assert.NoError(t, err)
+	assert.InDelta(t, 2*time.Minute, remaining, float64(time.Second))
+
+	// The deadline of an RPC that expires after the maximum is shortened.
+	ctx, cancel = context.WithTimeout(context.Background(), 2*time.Hour)
+	defer cancel()
+	_, err = i(ctx, nil, info, deadline(&remaining))
+	assert.NoError(t, err)
+	assert.InDelta(t, time.Hour, remaining, float64(time.Second))
+
+	// No deadline is applied without timeouts.
+	_, err = NewServerTimeout()(context.Background(), nil, info, deadline(&remaining))
+	assert.NoError(t, err)
+	assert.Zero(t, remaining)
+}
+
+func TestServerTimeoutExceeded(t *testing.T) {
+	i := NewServerTimeout(WithDefaultTimeout(10 * time.Millisecond))
+	info := &grpc.UnaryServerInfo{FullMethod: createVolume}
+
+	// A handler that ignores its deadline.
+	rep, err := i(context.Background(), nil, info,
+		func(context.Context, interface{}) (interface{}, error) {
+			time.Sleep(50 * time.Millisecond)
+			return "ok", nil
+		})
+	assert.Nil(t, rep)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	// A handler that returns when its context is done.
+	rep, err = i(context.Background(), nil, info,
+		func(ctx context.Context, _ interface{}) (interface{}, error) {
+			<-ctx.Done()
+			return nil, status.FromContextError(ctx.Err()).Err()
+		})
+	assert.Nil(t, rep)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
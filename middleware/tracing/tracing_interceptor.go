@@ -0,0 +1,302 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tracing
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	csictx "github.com/dell/gocsi/context"
+	"github.com/dell/gocsi/utils/middleware"
+)
+
+// instrumentationName is the name of the tracer used by the interceptor.
+const instrumentationName = "github.com/dell/gocsi/middleware/tracing"
+
+// Option configures the tracing interceptor.
+type Option func(*opts)
+
+type opts struct {
+	provider   trace.TracerProvider
+	propagator propagation.TextMapPropagator
+}
+
+// WithTracerProvider is an Option that sets the provider of the tracer
+// used to create spans. The default value is the global provider
+// returned by otel.GetTracerProvider.
+func WithTracerProvider(p trace.TracerProvider) Option {
+	return func(o *opts) {
+		o.provider = p
+	}
+}
+
+// WithPropagator is an Option that sets the propagator used to extract
+// and inject the trace context from and into gRPC metadata. The default
+// value is the W3C trace-context propagator.
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return func(o *opts) {
+		o.propagator = p
+	}
+}
+
+type interceptor struct {
+	opts   opts
+	tracer trace.Tracer
+}
+
+// NewServerTracer returns a new UnaryServerInterceptor that extracts
+// the W3C trace context from the incoming gRPC metadata and creates a
+// server span named after the RPC's method. The span's attributes
+// include the request's scalar fields, such as its volume ID and node
+// ID. Fields whose names contain "Secrets" are never recorded.
+func NewServerTracer(opts ...Option) grpc.UnaryServerInterceptor {
+	return newTracingInterceptor(opts...).handleServer
+}
+
+// NewStreamServerTracer returns a new StreamServerInterceptor that
+// creates a server span for the lifetime of a stream.
+func NewStreamServerTracer(opts ...Option) grpc.StreamServerInterceptor {
+	return newTracingInterceptor(opts...).handleStreamServer
+}
+
+// NewClientTracer provides a UnaryClientInterceptor that creates a
+// client span named after the RPC's method and injects its W3C trace
+// context into the outgoing gRPC metadata.
+func NewClientTracer(opts ...Option) grpc.UnaryClientInterceptor {
+	return newTracingInterceptor(opts...).handleClient
+}
+
+func newTracingInterceptor(opts ...Option) *interceptor {
+	i := &interceptor{}
+	for _, withOpts := range opts {
+		withOpts(&i.opts)
+	}
+	if i.opts.provider == nil {
+		i.opts.provider = otel.GetTracerProvider()
+	}
+	if i.opts.propagator == nil {
+		i.opts.propagator = propagation.TraceContext{}
+	}
+	i.tracer = i.opts.provider.Tracer(instrumentationName)
+	return i
+}
+
+func (i *interceptor) handleServer(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	ctx, span := i.startServerSpan(ctx, info.FullMethod, req)
+	defer span.End()
+
+	rep, err := handler(ctx, req)
+	endSpan(span, err)
+	return rep, err
+}
+
+func (i *interceptor) handleStreamServer(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx, span := i.startServerSpan(ss.Context(), info.FullMethod, nil)
+	defer span.End()
+
+	err := handler(srv, middleware.NewServerStreamWithContext(ctx, ss))
+	endSpan(span, err)
+	return err
+}
+
+func (i *interceptor) startServerSpan(
+	ctx context.Context,
+	fullMethod string,
+	req interface{},
+) (context.Context, trace.Span) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		md = metadata.MD{}
+	}
+	ctx = i.opts.propagator.Extract(ctx, metadataCarrier(md))
+
+	return i.tracer.Start(ctx, spanName(fullMethod),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(spanAttrs(ctx, fullMethod, req)...))
+}
+
+func (i *interceptor) handleClient(
+	ctx context.Context,
+	method string,
+	req, rep interface{},
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	ctx, span := i.tracer.Start(ctx, spanName(method),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(spanAttrs(ctx, method, req)...))
+	defer span.End()
+
+	// The metadata returned by FromOutgoingContext is a copy, so the
+	// outgoing context is replaced once the trace context is injected.
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		md = metadata.MD{}
+	}
+	i.opts.propagator.Inject(ctx, metadataCarrier(md))
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	err := invoker(ctx, method, req, rep, cc, opts...)
+	endSpan(span, err)
+	return err
+}
+
+// endSpan records the gRPC status code of the RPC on the span and marks
+// the span as failed if err is not nil.
+func endSpan(span trace.Span, err error) {
+	s := status.Convert(err)
+	span.SetAttributes(attribute.Int64("rpc.grpc.status_code", int64(s.Code())))
+	if err != nil {
+		span.SetStatus(otelcodes.Error, s.Message())
+	}
+}
+
+// spanName returns the span name for a gRPC method, ex.
+// "csi.v1.Controller/CreateVolume".
+func spanName(fullMethod string) string {
+	return strings.TrimPrefix(fullMethod, "/")
+}
+
+// spanAttrs returns the RPC's attributes and those of the request's
+// exported, non-empty, scalar fields. Each field is recorded with a key
+// of "csi." followed by the field's snake-cased name, ex. the field
+// VolumeId becomes "csi.volume_id". Fields whose names contain
+// "Secrets" are skipped, the same as when requests are logged.
+func spanAttrs(
+	ctx context.Context,
+	fullMethod string,
+	req interface{},
+) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{attribute.String("rpc.system", "grpc")}
+
+	if parts := strings.SplitN(spanName(fullMethod), "/", 2); len(parts) == 2 {
+		attrs = append(attrs,
+			attribute.String("rpc.service", parts[0]),
+			attribute.String("rpc.method", parts[1]))
+	}
+
+	if id, ok := csictx.GetRequestID(ctx); ok {
+		attrs = append(attrs, attribute.Int64("csi.request_id", int64(id))) // #nosec G115
+	}
+
+	if req == nil {
+		return attrs
+	}
+	rv := reflect.ValueOf(req)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return attrs
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return attrs
+	}
+
+	tv := rv.Type()
+	for j := 0; j < tv.NumField(); j++ {
+		f := tv.Field(j)
+		if f.PkgPath != "" || strings.Contains(f.Name, "Secrets") {
+			continue
+		}
+		key := "csi." + snakeCase(f.Name)
+		fv := rv.Field(j)
+		switch fv.Kind() {
+		case reflect.String:
+			if v := fv.String(); v != "" {
+				attrs = append(attrs, attribute.String(key, v))
+			}
+		case reflect.Bool:
+			if v := fv.Bool(); v {
+				attrs = append(attrs, attribute.Bool(key, v))
+			}
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			if v := fv.Int(); v != 0 {
+				attrs = append(attrs, attribute.Int64(key, v))
+			}
+		}
+	}
+	return attrs
+}
+
+// snakeCase converts a Go field name such as "VolumeId" into "volume_id".
+func snakeCase(s string) string {
+	var b strings.Builder
+	for j, r := range s {
+		if unicode.IsUpper(r) {
+			if j > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// metadataCarrier adapts gRPC metadata to a propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// TraceID returns the trace ID of the span in ctx, or an empty string if
+// ctx does not contain a valid span.
+func TraceID(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
@@ -0,0 +1,207 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	csictx "github.com/dell/gocsi/context"
+)
+
+const (
+	testTraceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testParentSpan  = "00f067aa0ba902b7"
+	testTraceParent = "00-" + testTraceID + "-" + testParentSpan + "-01"
+)
+
+func newTestProvider() (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exp := tracetest.NewInMemoryExporter()
+	return sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp)), exp
+}
+
+func attrMap(kvs []attribute.KeyValue) map[string]attribute.Value {
+	m := map[string]attribute.Value{}
+	for _, kv := range kvs {
+		m[string(kv.Key)] = kv.Value
+	}
+	return m
+}
+
+func TestServerTracer(t *testing.T) {
+	tp, exp := newTestProvider()
+	i := NewServerTracer(WithTracerProvider(tp))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"traceparent", testTraceParent,
+		csictx.RequestIDKey, "12",
+	))
+	req := &csi.NodeStageVolumeRequest{
+		VolumeId:          "vol-1",
+		StagingTargetPath: "/stage",
+		Secrets:           map[string]string{"password": "hunter2"},
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/csi.v1.Node/NodeStageVolume"}
+
+	_, err := i(ctx, req, info,
+		func(ctx context.Context, _ interface{}) (interface{}, error) {
+			// The handler's context carries the server span.
+			assert.Equal(t, testTraceID, TraceID(ctx))
+			return &csi.NodeStageVolumeResponse{}, nil
+		})
+	assert.NoError(t, err)
+
+	spans := exp.GetSpans()
+	assert.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "csi.v1.Node/NodeStageVolume", span.Name)
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+	assert.Equal(t, testTraceID, span.SpanContext.TraceID().String())
+	assert.Equal(t, testParentSpan, span.Parent.SpanID().String())
+	assert.True(t, span.Parent.IsRemote())
+
+	attrs := attrMap(span.Attributes)
+	assert.Equal(t, "vol-1", attrs["csi.volume_id"].AsString())
+	assert.Equal(t, "/stage", attrs["csi.staging_target_path"].AsString())
+	assert.Equal(t, "csi.v1.Node", attrs["rpc.service"].AsString())
+	assert.Equal(t, "NodeStageVolume", attrs["rpc.method"].AsString())
+	assert.Equal(t, int64(12), attrs["csi.request_id"].AsInt64())
+	assert.Equal(t, int64(codes.OK), attrs["rpc.grpc.status_code"].AsInt64())
+	for k, v := range attrs {
+		assert.NotContains(t, k, "secrets")
+		assert.NotContains(t, v.Emit(), "hunter2")
+	}
+}
+
+func TestServerTracerError(t *testing.T) {
+	tp, exp := newTestProvider()
+	i := NewServerTracer(WithTracerProvider(tp))
+
+	_, err := i(context.Background(), &csi.DeleteVolumeRequest{VolumeId: "vol-2"},
+		&grpc.UnaryServerInfo{FullMethod: "/csi.v1.Controller/DeleteVolume"},
+		func(_ context.Context, _ interface{}) (interface{}, error) {
+			return nil, status.Error(codes.NotFound, "no such volume")
+		})
+	assert.Error(t, err)
+
+	spans := exp.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, otelcodes.Error, spans[0].Status.Code)
+	assert.Equal(t, "no such volume", spans[0].Status.Description)
+	assert.Equal(t, int64(codes.NotFound),
+		attrMap(spans[0].Attributes)["rpc.grpc.status_code"].AsInt64())
+
+	// Without an incoming trace context a new trace is started.
+	assert.False(t, spans[0].Parent.IsValid())
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerTracer(t *testing.T) {
+	tp, exp := newTestProvider()
+	i := NewStreamServerTracer(WithTracerProvider(tp))
+
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs("traceparent", testTraceParent))
+
+	err := i(nil, &testServerStream{ctx: ctx},
+		&grpc.StreamServerInfo{FullMethod: "/ext.v1.Admin/Watch"},
+		func(_ interface{}, ss grpc.ServerStream) error {
+			assert.Equal(t, testTraceID, TraceID(ss.Context()))
+			return nil
+		})
+	assert.NoError(t, err)
+
+	spans := exp.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "ext.v1.Admin/Watch", spans[0].Name)
+}
+
+func TestClientTracer(t *testing.T) {
+	tp, exp := newTestProvider()
+	i := NewClientTracer(WithTracerProvider(tp))
+
+	ctx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("key", "value"))
+
+	var md metadata.MD
+	err := i(ctx, "/csi.v1.Controller/CreateVolume",
+		&csi.CreateVolumeRequest{Name: "pvc-1"}, &csi.CreateVolumeResponse{},
+		nil,
+		func(
+			ctx context.Context,
+			_ string,
+			_, _ interface{},
+			_ *grpc.ClientConn,
+			_ ...grpc.CallOption,
+		) error {
+			md, _ = metadata.FromOutgoingContext(ctx)
+			return nil
+		})
+	assert.NoError(t, err)
+
+	spans := exp.GetSpans()
+	assert.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind)
+	assert.Equal(t, "pvc-1", attrMap(span.Attributes)["csi.name"].AsString())
+
+	// The existing metadata is preserved and the span's trace context
+	// is injected.
+	assert.Equal(t, []string{"value"}, md.Get("key"))
+	assert.Equal(t,
+		[]string{"00-" + span.SpanContext.TraceID().String() + "-" +
+			span.SpanContext.SpanID().String() + "-01"},
+		md.Get("traceparent"))
+}
+
+func TestTraceID(t *testing.T) {
+	assert.Empty(t, TraceID(context.Background()))
+}
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"VolumeId":          "volume_id",
+		"NodeId":            "node_id",
+		"Name":              "name",
+		"StagingTargetPath": "staging_target_path",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, snakeCase(in))
+	}
+}
@@ -0,0 +1,189 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package gocsi
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	csictx "github.com/dell/gocsi/context"
+	"github.com/dell/gocsi/middleware/capabilities"
+	"github.com/dell/gocsi/middleware/concurrency"
+	"github.com/dell/gocsi/middleware/leaderelection/election"
+	"github.com/dell/gocsi/middleware/serialvolume"
+	"github.com/dell/gocsi/middleware/serialvolume/lockprovider"
+	"github.com/dell/gocsi/utils/middleware"
+)
+
+// Reloader is a StoragePluginProvider that is able to reload its
+// configuration while it is serving. Run reloads the configuration of a
+// Reloader when the process receives SIGHUP instead of exiting.
+type Reloader interface {
+	// Reload re-reads the configuration and applies the settings that
+	// may change while serving.
+	Reload(ctx context.Context) error
+}
+
+// interceptorChain is a chain of interceptors that may be replaced while
+// the server is running. RPCs that are in flight when the chain is
+// replaced complete with the chain with which they started.
+type interceptorChain struct {
+	chains atomic.Pointer[chains]
+
+	// outer are the names of the interceptors that precede the chain.
+	outer interceptorNames
+}
+
+type chains struct {
+	unary  grpc.UnaryServerInterceptor
+	stream grpc.StreamServerInterceptor
+	names  interceptorNames
+}
+
+// interceptorNames are the names of the interceptors of a chain, in the
+// order in which they handle RPCs.
+type interceptorNames struct {
+	Unary  []string `json:"unary"`
+	Stream []string `json:"stream"`
+}
+
+// add appends the name of a unary interceptor, and of its stream
+// counterpart if it has one.
+func (n *interceptorNames) add(name string, stream bool) {
+	n.Unary = append(n.Unary, name)
+	if stream {
+		n.Stream = append(n.Stream, name)
+	}
+}
+
+// set replaces the chain with the provided interceptors.
+func (c *interceptorChain) set(
+	unary []grpc.UnaryServerInterceptor,
+	stream []grpc.StreamServerInterceptor,
+	names interceptorNames,
+) {
+	c.chains.Store(&chains{
+		unary:  middleware.ChainUnaryServer(unary...),
+		stream: middleware.ChainStreamServer(stream...),
+		names:  names,
+	})
+}
+
+// names returns the names of the interceptors that precede the chain
+// followed by the names of the chain's interceptors.
+func (c *interceptorChain) names() interceptorNames {
+	names := c.chains.Load().names
+	return interceptorNames{
+		Unary:  append(append([]string{}, c.outer.Unary...), names.Unary...),
+		Stream: append(append([]string{}, c.outer.Stream...), names.Stream...),
+	}
+}
+
+func (c *interceptorChain) unary(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	return c.chains.Load().unary(ctx, req, info, handler)
+}
+
+func (c *interceptorChain) stream(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	return c.chains.Load().stream(srv, ss, info, handler)
+}
+
+// sharedInterceptors are the interceptors that keep state across RPCs.
+// They are created when the chain is first built and are reused when the
+// chain is rebuilt so their state is not lost.
+type sharedInterceptors struct {
+	initialized   bool
+	metrics       grpc.UnaryServerInterceptor
+	streamMetrics grpc.StreamServerInterceptor
+	tracer        grpc.UnaryServerInterceptor
+	streamTracer  grpc.StreamServerInterceptor
+	idempotency   grpc.UnaryServerInterceptor
+	lockProvider  lockprovider.VolumeLockerProvider
+	locks         *serialvolume.Registry
+	inflight      *inflightRPCs
+	limiter       *concurrency.Limiter
+	capabilities  *capabilities.Enforcer
+	elector       election.Elector
+	leader        grpc.UnaryServerInterceptor
+}
+
+// Reload re-reads the storage plug-in's environment variables and config
+// file, and applies the settings that may change while serving: the log
+// level, request and response logging, log redaction, RPC timeouts, spec
+// validation, and the serial volume access timeout and RPCs. The other
+// settings take effect when the storage plug-in is restarted. Values set
+// with csictx.Setenv after the storage plug-in started serving are kept.
+//
+// The GoCSI interceptors are rebuilt and replace the current ones for
+// new RPCs; RPCs that are in flight are not affected.
+func (sp *StoragePlugin) Reload(ctx context.Context) error {
+	sp.reloadL.Lock()
+	defer sp.reloadL.Unlock()
+
+	if sp.chain == nil {
+		return errors.New("storage plug-in is not serving")
+	}
+
+	// Read the configuration into a new storage plug-in so that RPCs
+	// observe either the previous or the new configuration.
+	next := &StoragePlugin{EnvVars: sp.EnvVars}
+	nctx := csictx.WithLookupEnv(ctx, next.lookupEnv)
+	nctx = csictx.WithSetenv(nctx, next.setenv)
+	next.initEnvVars(nctx)
+	if err := next.initConfig(nctx); err != nil {
+		return err
+	}
+
+	sp.envVarsL.Lock()
+	for k, v := range sp.setVars {
+		next.envVars[k] = v
+	}
+	// Reject invalid RPC timeouts before the configuration is applied.
+	if _, err := newTimeoutInterceptor(nctx); err != nil {
+		sp.envVarsL.Unlock()
+		return err
+	}
+	sp.envVars, sp.configVars = next.envVars, next.configVars
+	sp.envVarsL.Unlock()
+
+	ctx = csictx.WithLookupEnv(ctx, sp.lookupEnv)
+	ctx = csictx.WithSetenv(ctx, sp.setenv)
+	setLogLevel(ctx)
+	unary, stream, names, err := sp.newInterceptors(ctx)
+	if err != nil {
+		return err
+	}
+	sp.chain.set(unary, stream, names)
+
+	log.Info("reloaded configuration")
+	return nil
+}
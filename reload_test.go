@@ -0,0 +1,176 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package gocsi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	csictx "github.com/dell/gocsi/context"
+	"github.com/dell/gocsi/utils/middleware"
+)
+
+// newServingPlugin returns a storage plug-in whose environment and
+// interceptors are initialized as they are by Serve.
+func newServingPlugin(t *testing.T, envVars ...string) *StoragePlugin {
+	sp := &StoragePlugin{EnvVars: envVars}
+	ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+	ctx = csictx.WithSetenv(ctx, sp.setenv)
+	sp.initEnvVars(ctx)
+	assert.NoError(t, sp.initConfig(ctx))
+	sp.envInit = true
+	assert.NoError(t, sp.initInterceptors(ctx))
+	return sp
+}
+
+func deleteVolume(
+	sp *StoragePlugin, volID string, handler grpc.UnaryHandler,
+) error {
+	_, err := middleware.ChainUnaryServer(sp.Interceptors...)(
+		context.Background(),
+		&csi.DeleteVolumeRequest{VolumeId: volID},
+		&grpc.UnaryServerInfo{FullMethod: "/csi.v1.Controller/DeleteVolume"},
+		handler)
+	return err
+}
+
+func TestReloadNotServing(t *testing.T) {
+	sp := &StoragePlugin{}
+	assert.Error(t, sp.Reload(context.Background()))
+}
+
+func TestReload(t *testing.T) {
+	t.Setenv(EnvVarReqLogging, "false")
+	t.Setenv(EnvVarRepLogging, "false")
+	t.Setenv(EnvVarLogLevel, "info")
+	defer log.SetLevel(log.GetLevel())
+
+	sp := newServingPlugin(t, EnvVarPluginInfo+"=plugin,v1")
+
+	// Values set after the environment is initialized are kept.
+	ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+	ctx = csictx.WithSetenv(ctx, sp.setenv)
+	assert.NoError(t, csictx.Setenv(ctx, "X_CSI_TEST_RELOAD", "kept"))
+
+	// An RPC that is in flight during the reload completes with the
+	// interceptors with which it started.
+	started, release := make(chan struct{}), make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- deleteVolume(sp, "vol-1",
+			func(ctx context.Context, _ interface{}) (interface{}, error) {
+				close(started)
+				<-release
+				_, ok := csictx.GetRequestID(ctx)
+				assert.False(t, ok)
+				return &csi.DeleteVolumeResponse{}, nil
+			})
+	}()
+	<-started
+
+	t.Setenv(EnvVarReqLogging, "true")
+	t.Setenv(EnvVarLogLevel, "debug")
+	assert.NoError(t, sp.Reload(context.Background()))
+	assert.Equal(t, log.DebugLevel, log.GetLevel())
+
+	v, ok := sp.lookupEnv("X_CSI_TEST_RELOAD")
+	assert.True(t, ok)
+	assert.Equal(t, "kept", v)
+	v, _ = sp.lookupEnv(EnvVarPluginInfo)
+	assert.Equal(t, "plugin,v1", v)
+
+	// New RPCs use the rebuilt interceptors.
+	err := deleteVolume(sp, "vol-2",
+		func(ctx context.Context, _ interface{}) (interface{}, error) {
+			_, ok := csictx.GetRequestID(ctx)
+			assert.True(t, ok)
+			return &csi.DeleteVolumeResponse{}, nil
+		})
+	assert.NoError(t, err)
+
+	close(release)
+	assert.NoError(t, <-done)
+}
+
+func TestReloadConfigError(t *testing.T) {
+	sp := newServingPlugin(t)
+	t.Setenv(EnvVarConfigFile, writeConfigFile(t, "config.yaml",
+		"X_CSI_UNKNOWN: true\n"))
+	assert.Error(t, sp.Reload(context.Background()))
+
+	// The previous configuration is kept.
+	_, ok := sp.lookupEnv("X_CSI_UNKNOWN")
+	assert.False(t, ok)
+}
+
+func TestReloadTimeoutError(t *testing.T) {
+	sp := newServingPlugin(t)
+	t.Setenv(EnvVarConfigFile, writeConfigFile(t, "config.yaml",
+		"X_CSI_RPC_TIMEOUT: soon\n"))
+	assert.ErrorContains(t, sp.Reload(context.Background()), EnvVarRPCTimeout)
+
+	// The previous configuration is kept.
+	_, ok := sp.lookupEnv(EnvVarRPCTimeout)
+	assert.False(t, ok)
+}
+
+func TestReloadSerialVolume(t *testing.T) {
+	t.Setenv(EnvVarSerialVolAccessTimeout, "1h")
+	sp := newServingPlugin(t, EnvVarSerialVolAccess+"=true")
+
+	started, release := make(chan struct{}), make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- deleteVolume(sp, "vol-1",
+			func(_ context.Context, _ interface{}) (interface{}, error) {
+				close(started)
+				<-release
+				return &csi.DeleteVolumeResponse{}, nil
+			})
+	}()
+	<-started
+
+	// The rebuilt interceptors share the locks held by RPCs in flight.
+	t.Setenv(EnvVarSerialVolAccessTimeout, "10ms")
+	assert.NoError(t, sp.Reload(context.Background()))
+
+	start := time.Now()
+	err := deleteVolume(sp, "vol-1",
+		func(_ context.Context, _ interface{}) (interface{}, error) {
+			return &csi.DeleteVolumeResponse{}, nil
+		})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+	assert.Less(t, time.Since(start), time.Minute)
+
+	close(release)
+	assert.NoError(t, <-done)
+}
+
+func TestReloadFunc(t *testing.T) {
+	assert.Nil(t, reloadFunc(context.Background(), nil))
+	assert.NotNil(t, reloadFunc(context.Background(), &StoragePlugin{}))
+}
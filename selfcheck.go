@@ -0,0 +1,150 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package gocsi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	log "github.com/sirupsen/logrus"
+
+	csictx "github.com/dell/gocsi/context"
+	"github.com/dell/gocsi/middleware/specvalidator"
+)
+
+// selfCheck checks the registered services when X_CSI_SELF_CHECK is set.
+// The problems found are logged, or returned as an error when the storage
+// plug-in should refuse to start.
+func (sp *StoragePlugin) selfCheck(
+	ctx context.Context, registered []string,
+) error {
+	var fail bool
+	v, _ := csictx.LookupEnv(ctx, EnvVarSelfCheck)
+	switch {
+	case v == "":
+		return nil
+	case strings.EqualFold(v, "fail"):
+		fail = true
+	case strings.EqualFold(v, "warn"):
+	default:
+		return fmt.Errorf("invalid %s: %s", EnvVarSelfCheck, v)
+	}
+
+	problems := sp.checkServices(ctx, registered)
+	if len(problems) == 0 {
+		log.Info("self-check passed")
+		return nil
+	}
+	if fail {
+		return fmt.Errorf("self-check failed: %s", strings.Join(problems, "; "))
+	}
+	for _, p := range problems {
+		log.WithField("problem", p).Warn("self-check failed")
+	}
+	return nil
+}
+
+// checkServices calls the info and capability RPCs of the registered
+// services in-process, validates their responses against the CSI
+// specification, and cross-checks the advertised plug-in capabilities
+// with the registered services. It returns the problems found.
+func (sp *StoragePlugin) checkServices(
+	ctx context.Context, registered []string,
+) []string {
+	var (
+		problems []string
+		specOpts []specvalidator.Option
+	)
+	if sp.getEnvBool(ctx, EnvVarDisableFieldLen) {
+		specOpts = append(specOpts, specvalidator.WithDisableFieldLenCheck())
+	}
+	check := func(method string, rep interface{}, err error) bool {
+		if err == nil {
+			err = specvalidator.ValidateResponse(ctx, rep, specOpts...)
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", method, err))
+			return false
+		}
+		return true
+	}
+
+	// The plug-in info set with X_CSI_PLUGIN_INFO is returned in place
+	// of the Identity service's.
+	info := sp.pluginInfo
+	if info == nil || info.Name == "" {
+		var err error
+		info, err = sp.Identity.GetPluginInfo(ctx, &csi.GetPluginInfoRequest{})
+		check("GetPluginInfo", info, err)
+	} else {
+		check("GetPluginInfo", info, nil)
+	}
+
+	advertised := map[csi.PluginCapability_Service_Type]bool{}
+	caps, err := sp.Identity.GetPluginCapabilities(
+		ctx, &csi.GetPluginCapabilitiesRequest{})
+	if check("GetPluginCapabilities", caps, err) {
+		for _, c := range caps.GetCapabilities() {
+			advertised[c.GetService().GetType()] = true
+		}
+	}
+
+	mode := csictx.Getenv(ctx, EnvVarMode)
+	for _, svc := range []struct {
+		name string
+		cap  csi.PluginCapability_Service_Type
+	}{
+		{csi.Controller_ServiceDesc.ServiceName, csi.PluginCapability_Service_CONTROLLER_SERVICE},
+		{csi.GroupController_ServiceDesc.ServiceName, csi.PluginCapability_Service_GROUP_CONTROLLER_SERVICE},
+	} {
+		served := containsString(registered, svc.name)
+		switch {
+		case advertised[svc.cap] && !served:
+			problems = append(problems, fmt.Sprintf(
+				"%s is advertised but %s is not served: %s=%s",
+				svc.cap, svc.name, EnvVarMode, mode))
+		case !advertised[svc.cap] && served:
+			problems = append(problems, fmt.Sprintf(
+				"%s is served but %s is not advertised",
+				svc.name, svc.cap))
+		}
+	}
+
+	if containsString(registered, csi.Controller_ServiceDesc.ServiceName) {
+		rep, err := sp.Controller.ControllerGetCapabilities(
+			ctx, &csi.ControllerGetCapabilitiesRequest{})
+		check("ControllerGetCapabilities", rep, err)
+	}
+	if containsString(registered, csi.GroupController_ServiceDesc.ServiceName) {
+		rep, err := sp.GroupController.GroupControllerGetCapabilities(
+			ctx, &csi.GroupControllerGetCapabilitiesRequest{})
+		check("GroupControllerGetCapabilities", rep, err)
+	}
+	if containsString(registered, csi.Node_ServiceDesc.ServiceName) {
+		rep, err := sp.Node.NodeGetCapabilities(
+			ctx, &csi.NodeGetCapabilitiesRequest{})
+		check("NodeGetCapabilities", rep, err)
+		info, err := sp.Node.NodeGetInfo(ctx, &csi.NodeGetInfoRequest{})
+		check("NodeGetInfo", info, err)
+	}
+
+	return problems
+}
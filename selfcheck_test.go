@@ -0,0 +1,120 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package gocsi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+
+	csictx "github.com/dell/gocsi/context"
+	"github.com/dell/gocsi/mock/service"
+)
+
+var (
+	identityService   = csi.Identity_ServiceDesc.ServiceName
+	controllerService = csi.Controller_ServiceDesc.ServiceName
+	groupService      = csi.GroupController_ServiceDesc.ServiceName
+	nodeService       = csi.Node_ServiceDesc.ServiceName
+)
+
+func newSelfCheckPlugin(envVars ...string) (*StoragePlugin, context.Context) {
+	svc := service.NewServer()
+	sp := &StoragePlugin{
+		Identity:        svc,
+		Controller:      svc,
+		GroupController: svc,
+		Node:            svc,
+		EnvVars:         envVars,
+	}
+	ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+	sp.initEnvVars(ctx)
+	return sp, ctx
+}
+
+func TestSelfCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        []string
+		registered []string
+		wantErr    string
+	}{
+		{
+			name:       "disabled",
+			registered: []string{identityService, nodeService},
+		},
+		{
+			name:       "invalid",
+			env:        []string{EnvVarSelfCheck + "=true"},
+			registered: []string{identityService, controllerService},
+			wantErr:    "invalid " + EnvVarSelfCheck,
+		},
+		{
+			name:       "passed",
+			env:        []string{EnvVarSelfCheck + "=fail"},
+			registered: []string{identityService, controllerService, nodeService},
+		},
+		{
+			name: "node mode",
+			env: []string{
+				EnvVarSelfCheck + "=fail",
+				EnvVarMode + "=node",
+			},
+			registered: []string{identityService, nodeService},
+			wantErr:    "CONTROLLER_SERVICE is advertised but csi.v1.Controller is not served: X_CSI_MODE=node",
+		},
+		{
+			name:       "group controller",
+			env:        []string{EnvVarSelfCheck + "=fail"},
+			registered: []string{identityService, controllerService, groupService},
+			wantErr:    "csi.v1.GroupController is served but GROUP_CONTROLLER_SERVICE is not advertised",
+		},
+		{
+			name: "warn",
+			env: []string{
+				EnvVarSelfCheck + "=warn",
+				EnvVarMode + "=node",
+			},
+			registered: []string{identityService, nodeService},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sp, ctx := newSelfCheckPlugin(tt.env...)
+			err := sp.selfCheck(ctx, tt.registered)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSelfCheckPluginInfo(t *testing.T) {
+	sp, ctx := newSelfCheckPlugin(EnvVarSelfCheck + "=fail")
+	sp.pluginInfo = &csi.GetPluginInfoResponse{
+		Name:          "plugin",
+		VendorVersion: "1.0.0",
+	}
+	err := sp.selfCheck(ctx, []string{identityService, controllerService})
+	assert.ErrorContains(t, err, "GetPluginInfo: rpc error: code = Internal desc = invalid: Name=plugin")
+}
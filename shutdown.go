@@ -0,0 +1,245 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package gocsi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	csictx "github.com/dell/gocsi/context"
+	"github.com/dell/gocsi/utils/rpcs"
+)
+
+// initShutdown reads the time allowed for the storage plug-in to stop
+// gracefully.
+func (sp *StoragePlugin) initShutdown(ctx context.Context) error {
+	v, ok := csictx.LookupEnv(ctx, EnvVarShutdownTimeout)
+	if !ok || v == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", EnvVarShutdownTimeout, err)
+	}
+	if d < 0 {
+		return fmt.Errorf("invalid %s: %v", EnvVarShutdownTimeout, v)
+	}
+	sp.shutdownTimeout = d
+	return nil
+}
+
+// beginShutdown reports the storage plug-in as not ready, invokes the
+// BeforeStop callback, and logs the RPCs in flight. The returned context
+// is done when the shutdown timeout expires.
+func (sp *StoragePlugin) beginShutdown(
+	ctx context.Context,
+) (context.Context, context.CancelFunc) {
+	var cancel context.CancelFunc
+	if sp.shutdownTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, sp.shutdownTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+
+	if t := sp.shared.inflight; t != nil {
+		t.draining.Store(true)
+	}
+	if sp.health != nil {
+		sp.health.shutdown()
+	}
+
+	if f := sp.BeforeStop; f != nil {
+		if err := f(ctx, sp); err != nil {
+			log.WithError(err).Warn("BeforeStop failed")
+		}
+	}
+
+	if t := sp.shared.inflight; t != nil {
+		t.log("in-flight rpc at shutdown")
+	}
+	return ctx, cancel
+}
+
+// drain stops the gRPC servers gracefully, and stops them forcibly if the
+// RPCs in flight do not finish before the context is done.
+func (sp *StoragePlugin) drain(ctx context.Context) {
+	servers := sp.servers()
+	done := make(chan struct{})
+	go func() {
+		gracefulStopServers(servers)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		if t := sp.shared.inflight; t != nil {
+			t.log("cancelling in-flight rpc")
+		}
+		log.WithField("timeout", sp.shutdownTimeout).Warn(
+			"graceful stop timed out; stopping")
+		for _, s := range servers {
+			s.Stop()
+		}
+		<-done
+	}
+}
+
+// inflightRPCs tracks the RPCs in flight so they can be reported at
+// shutdown. While draining, the Identity service's Probe RPC reports the
+// storage plug-in as not ready.
+type inflightRPCs struct {
+	draining atomic.Bool
+
+	mu   sync.Mutex
+	next uint64
+	rpcs map[uint64]*inflightRPC
+}
+
+type inflightRPC struct {
+	method    string
+	requestID uint64
+	volumeID  string
+	start     time.Time
+}
+
+type hasVolumeID interface {
+	GetVolumeId() string
+}
+
+// add records an RPC in flight and returns a function that removes it.
+func (t *inflightRPCs) add(
+	ctx context.Context, method string, req interface{},
+) func() {
+	rpc := &inflightRPC{method: method, start: time.Now()}
+	rpc.requestID, _ = csictx.GetRequestID(ctx)
+	if r, ok := req.(hasVolumeID); ok {
+		rpc.volumeID = r.GetVolumeId()
+	}
+
+	t.mu.Lock()
+	if t.rpcs == nil {
+		t.rpcs = map[uint64]*inflightRPC{}
+	}
+	t.next++
+	id := t.next
+	t.rpcs[id] = rpc
+	t.mu.Unlock()
+
+	return func() {
+		t.mu.Lock()
+		delete(t.rpcs, id)
+		t.mu.Unlock()
+	}
+}
+
+// log logs each of the RPCs in flight with the provided message.
+func (t *inflightRPCs) log(msg string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, rpc := range t.rpcs {
+		fields := log.Fields{
+			"method": rpc.method,
+			"age":    time.Since(rpc.start),
+		}
+		if rpc.requestID != 0 {
+			fields["requestID"] = rpc.requestID
+		}
+		if rpc.volumeID != "" {
+			fields["volumeID"] = rpc.volumeID
+		}
+		log.WithFields(fields).Warn(msg)
+	}
+}
+
+// inflightRPCInfo describes an RPC in flight.
+type inflightRPCInfo struct {
+	Method    string    `json:"method"`
+	RequestID uint64    `json:"requestID,omitempty"`
+	VolumeID  string    `json:"volumeID,omitempty"`
+	Start     time.Time `json:"start"`
+	Age       string    `json:"age"`
+}
+
+// list returns the RPCs in flight, oldest first.
+func (t *inflightRPCs) list() []inflightRPCInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ids := make([]uint64, 0, len(t.rpcs))
+	for id := range t.rpcs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+
+	rpcs := make([]inflightRPCInfo, len(ids))
+	for j, id := range ids {
+		rpc := t.rpcs[id]
+		rpcs[j] = inflightRPCInfo{
+			Method:    rpc.method,
+			RequestID: rpc.requestID,
+			VolumeID:  rpc.volumeID,
+			Start:     rpc.start,
+			Age:       time.Since(rpc.start).String(),
+		}
+	}
+	return rpcs
+}
+
+func (t *inflightRPCs) len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.rpcs)
+}
+
+func (t *inflightRPCs) handle(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	if t.draining.Load() {
+		_, service, method, err := rpcs.ParseMethod(info.FullMethod)
+		if err == nil && service == "Identity" && method == "Probe" {
+			return &csi.ProbeResponse{Ready: wrapperspb.Bool(false)}, nil
+		}
+	}
+
+	defer t.add(ctx, info.FullMethod, req)()
+	return handler(ctx, req)
+}
+
+func (t *inflightRPCs) handleStream(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	defer t.add(ss.Context(), info.FullMethod, nil)()
+	return handler(srv, ss)
+}
@@ -0,0 +1,156 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package gocsi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	csictx "github.com/dell/gocsi/context"
+)
+
+// blockingNode is a Node service whose NodeUnpublishVolume RPC blocks
+// until its context is done.
+type blockingNode struct {
+	csi.UnimplementedNodeServer
+	started chan struct{}
+}
+
+func (n *blockingNode) NodeUnpublishVolume(
+	ctx context.Context, _ *csi.NodeUnpublishVolumeRequest,
+) (*csi.NodeUnpublishVolumeResponse, error) {
+	close(n.started)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestInitShutdown(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "unset"},
+		{name: "valid", value: "5s", want: 5 * time.Second},
+		{name: "invalid", value: "soon", wantErr: true},
+		{name: "negative", value: "-1s", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sp := &StoragePlugin{}
+			if tt.value != "" {
+				sp.EnvVars = []string{EnvVarShutdownTimeout + "=" + tt.value}
+			}
+			ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+			sp.initEnvVars(ctx)
+			err := sp.initShutdown(ctx)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, sp.shutdownTimeout)
+		})
+	}
+}
+
+func TestInflightRPCs(t *testing.T) {
+	var rpcs inflightRPCs
+	probe := &grpc.UnaryServerInfo{FullMethod: "/csi.v1.Identity/Probe"}
+	handler := func(_ context.Context, _ interface{}) (interface{}, error) {
+		assert.Equal(t, 1, rpcs.len())
+		return &csi.ProbeResponse{Ready: wrapperspb.Bool(true)}, nil
+	}
+
+	rep, err := rpcs.handle(context.Background(), &csi.ProbeRequest{}, probe, handler)
+	assert.NoError(t, err)
+	assert.True(t, rep.(*csi.ProbeResponse).GetReady().GetValue())
+	assert.Equal(t, 0, rpcs.len())
+
+	// Probe reports not ready while draining.
+	rpcs.draining.Store(true)
+	rep, err = rpcs.handle(context.Background(), &csi.ProbeRequest{}, probe, handler)
+	assert.NoError(t, err)
+	assert.False(t, rep.(*csi.ProbeResponse).GetReady().GetValue())
+}
+
+func TestGracefulStopTimeout(t *testing.T) {
+	node := &blockingNode{started: make(chan struct{})}
+	sp := &StoragePlugin{
+		Identity: &probeIdentity{ready: wrapperspb.Bool(true)},
+		Node:     node,
+		EnvVars: []string{
+			EnvVarMode + "=node",
+			EnvVarShutdownTimeout + "=100ms",
+		},
+	}
+
+	var probeRep interface{}
+	sp.BeforeStop = func(ctx context.Context, sp *StoragePlugin) error {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.Equal(t, 1, sp.shared.inflight.len())
+		probeRep, _ = sp.shared.inflight.handle(ctx, &csi.ProbeRequest{},
+			&grpc.UnaryServerInfo{FullMethod: "/csi.v1.Identity/Probe"},
+			func(context.Context, interface{}) (interface{}, error) {
+				return &csi.ProbeResponse{Ready: wrapperspb.Bool(true)}, nil
+			})
+		return nil
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() {
+		_ = sp.Serve(context.Background(), lis)
+	}()
+
+	conn, err := grpc.NewClient(lis.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	done := make(chan error)
+	go func() {
+		_, err := csi.NewNodeClient(conn).NodeUnpublishVolume(
+			context.Background(), &csi.NodeUnpublishVolumeRequest{
+				VolumeId:   "vol-1",
+				TargetPath: "/mnt/vol-1",
+			})
+		done <- err
+	}()
+	<-node.started
+
+	start := time.Now()
+	sp.GracefulStop(context.Background())
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Error(t, <-done)
+
+	if assert.IsType(t, &csi.ProbeResponse{}, probeRep) {
+		assert.False(t, probeRep.(*csi.ProbeResponse).GetReady().GetValue())
+	}
+}
@@ -0,0 +1,180 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package gocsi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	csictx "github.com/dell/gocsi/context"
+)
+
+// initTLS configures the server to serve its TCP endpoints with TLS if a
+// certificate and key are specified by X_CSI_TLS_CERT and X_CSI_TLS_KEY.
+// UNIX socket endpoints are always served in plaintext.
+func (sp *StoragePlugin) initTLS(ctx context.Context) error {
+	certFile := csictx.Getenv(ctx, EnvVarTLSCert)
+	keyFile := csictx.Getenv(ctx, EnvVarTLSKey)
+	caFile := csictx.Getenv(ctx, EnvVarTLSClientCA)
+
+	if certFile == "" && keyFile == "" {
+		if caFile != "" {
+			return fmt.Errorf("%s requires %s and %s",
+				EnvVarTLSClientCA, EnvVarTLSCert, EnvVarTLSKey)
+		}
+		return nil
+	}
+	if certFile == "" || keyFile == "" {
+		return fmt.Errorf("%s and %s must be set together",
+			EnvVarTLSCert, EnvVarTLSKey)
+	}
+
+	r := &certReloader{certFile: certFile, keyFile: keyFile, caFile: caFile}
+	if err := r.load(); err != nil {
+		return err
+	}
+
+	sp.tlsConfig = r.tlsConfig()
+
+	log.WithFields(map[string]interface{}{
+		"cert":     certFile,
+		"key":      keyFile,
+		"clientCA": caFile,
+	}).Info("serving with tls")
+	return nil
+}
+
+// useTLS returns whether the server of lis uses TLS, which is the case
+// if TLS is configured and lis is a TCP listener.
+func (sp *StoragePlugin) useTLS(lis net.Listener) bool {
+	return sp.tlsConfig != nil && lis.Addr().Network() == "tcp"
+}
+
+// serverOpts returns the options of the gRPC server of lis: the storage
+// plug-in's ServerOpts and, if the server uses TLS, its credentials.
+func (sp *StoragePlugin) serverOpts(lis net.Listener) []grpc.ServerOption {
+	opts := append([]grpc.ServerOption{}, sp.ServerOpts...)
+	if sp.useTLS(lis) {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(sp.tlsConfig)))
+	}
+	return opts
+}
+
+// certReloader provides the server's TLS certificate and the CAs used to
+// verify client certificates. The files are reloaded during a TLS
+// handshake when they have changed since they were last loaded.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	mu        sync.Mutex
+	stamps    [3]fileStamp
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+}
+
+// fileStamp records the size and modification time of a file.
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// tlsConfig returns the server's TLS configuration. The configuration
+// is created for each TLS handshake from the current certificate and
+// client CAs.
+func (r *certReloader) tlsConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			if err := r.load(); err != nil {
+				log.WithError(err).Error(
+					"failed to reload tls files; using previous files")
+			}
+
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.cert},
+				NextProtos:   []string{"h2"},
+			}
+			if r.clientCAs != nil {
+				config.ClientCAs = r.clientCAs
+				config.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return config, nil
+		},
+	}
+}
+
+// load loads the files if they have changed since they were last loaded.
+// The previously loaded certificate and CAs are kept if an error occurs.
+func (r *certReloader) load() error {
+	var stamps [3]fileStamp
+	for i, f := range []string{r.certFile, r.keyFile, r.caFile} {
+		if f == "" {
+			continue
+		}
+		fi, err := os.Stat(f)
+		if err != nil {
+			return err
+		}
+		stamps[i] = fileStamp{size: fi.Size(), modTime: fi.ModTime()}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cert != nil && stamps == r.stamps {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	var clientCAs *x509.CertPool
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return err
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return errors.New("no certificates found in " + r.caFile)
+		}
+	}
+
+	if r.cert != nil {
+		log.WithField("cert", r.certFile).Info("reloaded tls files")
+	}
+	r.cert, r.clientCAs, r.stamps = &cert, clientCAs, stamps
+	return nil
+}
@@ -0,0 +1,323 @@
+/*
+ *
+ * Copyright © 2026 Dell Inc. or its subsidiaries. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package gocsi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+
+	csictx "github.com/dell/gocsi/context"
+	"github.com/dell/gocsi/mock/service"
+)
+
+// testCert is a certificate and its key.
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCert creates a certificate signed by parent, or a self-signed CA
+// certificate if parent is nil.
+func newTestCert(t *testing.T, serial int64, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "gocsi"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{
+			x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth,
+		},
+	}
+	signer := &testCert{cert: tmpl, key: key}
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		signer = parent
+	}
+
+	der, err := x509.CreateCertificate(
+		rand.Reader, tmpl, signer.cert, &key.PublicKey, signer.key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return &testCert{cert: cert, key: key}
+}
+
+// write writes the certificate and key to PEM files and sets the files'
+// modification time.
+func (c *testCert) write(t *testing.T, certFile, keyFile string, mtime time.Time) {
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	assert.NoError(t, err)
+	for f, b := range map[string]*pem.Block{
+		certFile: {Type: "CERTIFICATE", Bytes: c.cert.Raw},
+		keyFile:  {Type: "EC PRIVATE KEY", Bytes: keyDER},
+	} {
+		assert.NoError(t, os.WriteFile(f, pem.EncodeToMemory(b), 0o600))
+		assert.NoError(t, os.Chtimes(f, mtime, mtime))
+	}
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key}
+}
+
+func TestInitTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	newTestCert(t, 1, nil).write(t, certFile, keyFile, time.Now())
+
+	tests := []struct {
+		name      string
+		env       []string
+		expectErr string
+		creds     bool
+	}{
+		{
+			name: "disabled",
+		},
+		{
+			name:  "enabled",
+			env:   []string{EnvVarTLSCert + "=" + certFile, EnvVarTLSKey + "=" + keyFile},
+			creds: true,
+		},
+		{
+			name:      "missing key",
+			env:       []string{EnvVarTLSCert + "=" + certFile},
+			expectErr: "must be set together",
+		},
+		{
+			name:      "client CA without cert",
+			env:       []string{EnvVarTLSClientCA + "=" + certFile},
+			expectErr: "requires",
+		},
+		{
+			name: "invalid client CA",
+			env: []string{
+				EnvVarTLSCert + "=" + certFile, EnvVarTLSKey + "=" + keyFile,
+				EnvVarTLSClientCA + "=" + keyFile,
+			},
+			expectErr: "no certificates found",
+		},
+		{
+			name: "missing file",
+			env: []string{
+				EnvVarTLSCert + "=" + filepath.Join(dir, "missing"),
+				EnvVarTLSKey + "=" + keyFile,
+			},
+			expectErr: "no such file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sp := &StoragePlugin{EnvVars: tt.env}
+			ctx := csictx.WithLookupEnv(context.Background(), sp.lookupEnv)
+			sp.initEnvVars(ctx)
+
+			err := sp.initTLS(ctx)
+			if tt.expectErr != "" {
+				assert.ErrorContains(t, err, tt.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.creds, sp.tlsConfig != nil)
+		})
+	}
+}
+
+func TestServeTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	caFile := filepath.Join(dir, "ca.crt")
+
+	ca := newTestCert(t, 1, nil)
+	ca.write(t, caFile, filepath.Join(dir, "ca.key"), time.Now())
+	mtime := time.Now().Add(-time.Minute)
+	newTestCert(t, 2, ca).write(t, certFile, keyFile, mtime)
+	client := newTestCert(t, 3, ca)
+
+	svc := service.NewServer()
+	sp := newMockStoragePlugin(svc, nil, svc, svc)
+	sp.EnvVars = []string{
+		EnvVarTLSCert + "=" + certFile,
+		EnvVarTLSKey + "=" + keyFile,
+		EnvVarTLSClientCA + "=" + caFile,
+	}
+
+	// The handlers receive the TLS information of the client.
+	authInfos := make(chan credentials.AuthInfo, 1)
+	sp.Interceptors = append(sp.Interceptors, func(
+		ctx context.Context,
+		req interface{},
+		_ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if p, ok := peer.FromContext(ctx); ok {
+			select {
+			case authInfos <- p.AuthInfo:
+			default:
+			}
+		}
+		return handler(ctx, req)
+	})
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() {
+		_ = sp.Serve(context.Background(), lis)
+	}()
+	defer sp.Stop(context.Background())
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	// probe calls GetPluginInfo with the provided client certificates and
+	// returns the serial number of the server's certificate.
+	probe := func(certs ...tls.Certificate) (int64, error) {
+		var serial int64
+		config := &tls.Config{
+			RootCAs:      roots,
+			Certificates: certs,
+			MinVersion:   tls.VersionTLS12,
+			VerifyConnection: func(cs tls.ConnectionState) error {
+				serial = cs.PeerCertificates[0].SerialNumber.Int64()
+				return nil
+			},
+		}
+		conn, err := grpc.NewClient(lis.Addr().String(),
+			grpc.WithTransportCredentials(credentials.NewTLS(config)))
+		if err != nil {
+			return 0, err
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err = csi.NewIdentityClient(conn).GetPluginInfo(
+			ctx, &csi.GetPluginInfoRequest{})
+		return serial, err
+	}
+
+	serial, err := probe(client.tlsCertificate())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), serial)
+	authInfo := <-authInfos
+	if assert.IsType(t, credentials.TLSInfo{}, authInfo) {
+		certs := authInfo.(credentials.TLSInfo).State.PeerCertificates
+		assert.Equal(t, int64(3), certs[0].SerialNumber.Int64())
+	}
+
+	// Clients without a certificate signed by the CA are rejected.
+	_, err = probe()
+	assert.Error(t, err)
+	_, err = probe(newTestCert(t, 4, nil).tlsCertificate())
+	assert.Error(t, err)
+
+	// A new server certificate is used once its files change.
+	newTestCert(t, 5, ca).write(t, certFile, keyFile, time.Now())
+	serial, err = probe(client.tlsCertificate())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), serial)
+}
+
+func TestServeTLSUnixSocket(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	ca := newTestCert(t, 1, nil)
+	newTestCert(t, 2, ca).write(t, certFile, keyFile, time.Now())
+
+	tcpLis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	tcpAddr := tcpLis.Addr().String()
+	assert.NoError(t, tcpLis.Close())
+	tcpLis, err = net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	allAddr := tcpLis.Addr().String()
+	assert.NoError(t, tcpLis.Close())
+
+	svc := service.NewServer()
+	sp := newMockStoragePlugin(svc, nil, svc, svc)
+	sp.EnvVars = []string{
+		EnvVarTLSCert + "=" + certFile,
+		EnvVarTLSKey + "=" + keyFile,
+		EnvVarAdditionalEndpoints + "=tcp://" + tcpAddr + ";identity," +
+			"tcp://" + allAddr,
+	}
+
+	sockFile := filepath.Join(dir, "csi.sock")
+	lis, err := net.Listen("unix", sockFile)
+	assert.NoError(t, err)
+	go func() {
+		_ = sp.Serve(context.Background(), lis)
+	}()
+	defer sp.Stop(context.Background())
+
+	getPluginInfo := func(addr string, creds credentials.TransportCredentials) error {
+		conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err = csi.NewIdentityClient(conn).GetPluginInfo(
+			ctx, &csi.GetPluginInfoRequest{})
+		return err
+	}
+
+	// The UNIX socket is served in plaintext.
+	assert.Eventually(t, func() bool {
+		return getPluginInfo("unix://"+sockFile, insecure.NewCredentials()) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The TCP endpoints are served with TLS.
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	for _, addr := range []string{tcpAddr, allAddr} {
+		assert.NoError(t, getPluginInfo(addr, credentials.NewTLS(
+			&tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12})))
+		assert.Error(t, getPluginInfo(addr, insecure.NewCredentials()))
+	}
+}
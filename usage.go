@@ -38,6 +38,16 @@ GLOBAL OPTIONS
         If the network type is omitted then the value is assumed to be an
         absolute or relative filesystem path to a UNIX socket file
 
+        A listening socket inherited by the process may be used instead:
+
+            * systemd:// or systemd://name, a socket passed with systemd
+              socket activation, optionally the first socket named by the
+              FileDescriptorName option of the socket unit
+            * fd://N, a socket inherited as the file descriptor N
+
+        The UNIX socket file of an inherited socket is not removed when
+        the process exits.
+
     X_CSI_MODE
         Specifies the service mode of the storage plug-in. Valid values are:
 
@@ -75,6 +85,97 @@ GLOBAL OPTIONS
         If no value is specified then the group owner of the file is the
         same as the group that starts the process.
 
+    X_CSI_ENDPOINT_REMOVE_STALE
+        A flag that removes a UNIX socket file left at the endpoint's path
+        by a process that exited, such as after a crash, before listening
+        on the endpoint. The file is never removed if a process is
+        listening on it; the plug-in fails to start instead. The default
+        value is true.
+
+    X_CSI_ADDITIONAL_ENDPOINTS
+        A comma-separated list of endpoints on which the plug-in is served
+        in addition to CSI_ENDPOINT. Each endpoint may be followed by a
+        semicolon-separated list of the services it exposes: identity,
+        controller, groupcontroller, and node. The identity service is
+        always exposed, as are the services registered by the storage
+        plug-in's RegisterAdditionalServers callback. For example:
+
+            unix:///var/run/csi/csi.sock,tcp://0.0.0.0:10000;node
+
+        The permissions and ownership of UNIX socket files are set with
+        X_CSI_ENDPOINT_PERMS, X_CSI_ENDPOINT_USER, and X_CSI_ENDPOINT_GROUP.
+
+    X_CSI_TLS_CERT
+        The path to the PEM-encoded certificate used to serve the TCP
+        endpoints with TLS. The TCP endpoints are served with TLS only if
+        both X_CSI_TLS_CERT and X_CSI_TLS_KEY are set. UNIX socket
+        endpoints are always served in plaintext. The certificate is
+        reloaded when the file changes.
+
+    X_CSI_TLS_KEY
+        The path to the PEM-encoded private key of the certificate
+        specified by X_CSI_TLS_CERT.
+
+    X_CSI_TLS_CLIENT_CA
+        The path to a PEM-encoded bundle of CA certificates. When set,
+        clients must present a certificate signed by one of the CAs
+        (mutual TLS). The bundle is reloaded when the file changes.
+
+        Only takes effect if X_CSI_TLS_CERT and X_CSI_TLS_KEY are set.
+
+    X_CSI_HEALTH_DISABLED
+        A flag that disables the gRPC health service. The health service
+        is not registered either if the storage plug-in's
+        RegisterAdditionalServers callback registers its own.
+
+    X_CSI_HEALTH_PROBE_INTERVAL
+        The server registers the standard gRPC health service,
+        grpc.health.v1.Health. The serving status of the Identity service
+        and of the Controller, Node, and GroupController services enabled
+        by X_CSI_MODE is derived from calling the Identity service's Probe
+        RPC at this interval. The default value is 10s.
+
+    X_CSI_HEALTH_PROBE_FAILURE_THRESHOLD
+        The number of consecutive probes that must fail, or report the
+        plug-in as not ready, before the services are reported as
+        NOT_SERVING. A single successful probe reports the services as
+        SERVING again. The default value is 3.
+
+    X_CSI_SHUTDOWN_TIMEOUT
+        The time allowed for the RPCs in flight to finish when the server
+        is stopped gracefully, after which the server is stopped forcibly.
+        The RPCs in flight are logged at shutdown, and the Probe RPC
+        reports the plug-in as not ready while they drain. By default
+        there is no timeout.
+
+    X_CSI_CONFIG_FILE
+        The path to a YAML or JSON file whose keys are the names of the
+        environment variables described here, or declared by the storage
+        plug-in, for example:
+
+            X_CSI_MODE: node
+            X_CSI_LOG_LEVEL: info
+            X_CSI_SERIAL_VOL_ACCESS: true
+
+        The values in the file are overridden by the storage plug-in's
+        default values, which are in turn overridden by the environment.
+        Unknown keys are rejected.
+
+        The file and the environment are read again when the process
+        receives SIGHUP. The reloaded configuration applies the log level,
+        request and response logging, log redaction, RPC timeouts, spec
+        validation, and the serial volume access timeout and RPCs to new
+        RPCs; RPCs in flight are not affected. Other values take effect on
+        restart.
+
+    X_CSI_CONFIG_PRINT
+        A flag that prints the effective configuration, the resolved
+        values of all the known environment variables, to STDERR at
+        startup. The values of the variables whose names contain the
+        segment PASSWORD, SECRET, TOKEN, KEY, CREDENTIAL, or CREDENTIALS
+        are masked, except for file paths such as X_CSI_TLS_KEY and the
+        variables whose names end with _FILE, _PATH, or _DIR.
+
     X_CSI_DEBUG
         Enabling this option is the same as:
             X_CSI_LOG_LEVEL=debug
@@ -124,12 +225,83 @@ GLOBAL OPTIONS
 
         Only takes effect if Request or Reply logging is enabled.
 
+    X_CSI_LOG_FORMAT
+        The format of the request and response logs. Valid values are
+        "text" and "json". The "json" format writes each request and
+        response as a single line JSON object that includes the method,
+        request ID, duration, and gRPC status code. Secrets are always
+        omitted. The default value is "text".
+
+        Only takes effect if Request or Reply logging is enabled.
+
+    X_CSI_LOG_REDACT
+        A whitespace-separated list of redaction rules. The values
+        described by the rules are masked in the request and response
+        logs instead of being omitted. A rule has one of three forms:
+
+            path         masks the value of the field at the path
+            ~regexp      masks the values of all map entries with
+                         keys that match the regular expression
+            path~regexp  masks the values of the entries of the map at
+                         the path with keys that match the expression
+
+        A path is a dot-separated list of field names, for example
+        "VolumeCapability.Mount.MountFlags". A path segment that follows
+        a map field is a map key, for example "Parameters.chap_password".
+        The following masks all map values with keys that contain
+        "password" as well as the "token" entry of the PublishContext:
+
+            X_CSI_LOG_REDACT="~(?i)password PublishContext.token"
+
+        Only takes effect if Request or Reply logging is enabled.
+
     X_CSI_REQ_ID_INJECTION
         A flag that enables request ID injection. The ID is parsed from
         the incoming request's metadata with a key of "csi.requestid".
         If no value for that key is found then a new request ID is
         generated using an atomic sequence counter.
 
+    X_CSI_METRICS
+        A flag that enables the metrics middleware. The middleware records
+        Prometheus counters of started and handled RPCs, the gRPC status
+        codes of handled RPCs, an RPC latency histogram, and a gauge of
+        the RPCs in flight. All are labeled with the RPC's service and
+        method.
+
+    X_CSI_METRICS_ADDR
+        The TCP address, ex. ":9090", on which the Prometheus metrics are
+        served over HTTP at the path /metrics. The metrics are not served
+        if this value is unset.
+
+    X_CSI_ADMIN_ADDR
+        The TCP address, ex. "127.0.0.1:9091", on which the admin and debug
+        endpoints are served over HTTP. The endpoints are not served if this
+        value is unset, and should not be exposed outside of the host:
+
+            /debug/pprof/  The Go runtime profiles.
+            /config        The effective configuration. The values of
+                           sensitive variables are masked as with
+                           X_CSI_CONFIG_PRINT.
+            /interceptors  The interceptors in the order they handle RPCs.
+            /inflight      The RPCs in flight.
+            /locks         The serial volume access locks held.
+            /loglevel      The log level. A PUT or POST request with the
+                           level parameter, ex. level=debug, sets it
+                           until the configuration is reloaded.
+
+    X_CSI_TRACING
+        A flag that enables the tracing middleware. The middleware extracts
+        the W3C trace context from the incoming request's "traceparent"
+        metadata and creates an OpenTelemetry span named after the RPC's
+        method using the global tracer provider. The span's attributes
+        include the request's scalar fields, such as its volume ID and
+        node ID. Fields that contain secrets are never recorded.
+
+    X_CSI_TRACING_PROPAGATORS
+        A comma-separated list of the propagators used to extract the
+        trace context from incoming requests. Valid values include
+        "tracecontext" and "baggage". The default value is "tracecontext".
+
     X_CSI_SPEC_VALIDATION
         Setting X_CSI_SPEC_VALIDATION=true is the same as:
             X_CSI_SPEC_REQ_VALIDATION=true
@@ -213,14 +385,168 @@ GLOBAL OPTIONS
 
         Enabling this option sets X_CSI_SPEC_REQ_VALIDATION=true.
 
+    X_CSI_IDEMPOTENCY
+        A flag that enables the idempotency middleware. Mutating volume
+        and snapshot RPCs are keyed by the method, the volume name or ID,
+        and a hash of the request. A duplicate RPC that arrives while the
+        original RPC is in flight waits for it and receives the same
+        response. A duplicate RPC that arrives after the original RPC
+        succeeded receives the cached response until another RPC for the
+        same volume name or ID starts. Failed RPCs are not cached.
+
+    X_CSI_IDEMPOTENCY_TTL
+        A time.Duration string that determines how long the response of a
+        completed RPC is cached by the idempotency middleware. The TTL
+        should only be long enough to cover the CO's retries. The default
+        value is 10s.
+
+    X_CSI_IDEMPOTENCY_ETCD
+        A flag that records the RPCs of the idempotency middleware in etcd
+        so that they are deduplicated across the replicas of a controller.
+        The etcd client is configured by the X_CSI_SERIAL_VOL_ACCESS_ETCD_*
+        environment variables.
+
+    X_CSI_IDEMPOTENCY_ETCD_DOMAIN
+        The etcd key prefix under which the idempotency middleware records
+        RPCs.
+
+    X_CSI_IDEMPOTENCY_ETCD_TTL
+        The length of time etcd will wait before releasing an in-flight RPC
+        if the lease of the RPC's owner has not been renewed. The default
+        value is 1m.
+
+    X_CSI_LEADER_ELECTION
+        A flag that enables leader election among the replicas of a
+        controller. Only the leader handles CreateVolume, DeleteVolume,
+        ControllerPublishVolume, ControllerUnpublishVolume,
+        ControllerExpandVolume, ControllerModifyVolume, CreateSnapshot,
+        DeleteSnapshot, CreateVolumeGroupSnapshot, and
+        DeleteVolumeGroupSnapshot. The other replicas reject them with the
+        gRPC status code Unavailable. The leader is elected in etcd if
+        X_CSI_SERIAL_VOL_ACCESS_ETCD_ENDPOINTS is set, otherwise the
+        storage plug-in must provide its LeaderElector.
+
+    X_CSI_LEADER_ELECTION_IDENTITY
+        The identity with which the storage plug-in campaigns for
+        leadership: the gRPC target at which the other replicas reach it,
+        for example 10.0.0.1:5000. The default value is the host name.
+
+    X_CSI_LEADER_ELECTION_FORWARD
+        A flag that forwards the RPCs received by a replica that is not
+        the leader to the leader instead of rejecting them. The replica
+        connects to X_CSI_LEADER_ELECTION_IDENTITY of the leader, which
+        must be set, with the storage plug-in's LeaderDialOpts.
+
+    X_CSI_LEADER_ELECTION_FORWARD_TOKEN
+        The token shared by the replicas to mark the RPCs they forward to
+        the leader. Only the RPCs marked with this token are recognized as
+        forwarded. It must be set if X_CSI_LEADER_ELECTION_FORWARD is
+        enabled.
+
+    X_CSI_LEADER_ELECTION_ETCD_DOMAIN
+        The etcd key prefix under which the leader is elected.
+
+    X_CSI_LEADER_ELECTION_ETCD_TTL
+        The length of time etcd will wait before electing a new leader if
+        the lease of the leader has not been renewed. The default value is
+        15s.
+
+    X_CSI_RPC_TIMEOUT
+        The timeout of the RPCs that have no deadline, for example 5m. By
+        default no timeout is applied. A handler whose context's deadline
+        expires fails with the gRPC status code DeadlineExceeded and is
+        logged. Handlers must return when their context is done.
+
+    X_CSI_RPC_TIMEOUT_MAX
+        The maximum timeout of the RPCs. The deadline of an RPC that
+        expires later is shortened. The default timeout is also limited
+        to this value.
+
+    X_CSI_RPC_TIMEOUT_PER_METHOD
+        The timeouts of the RPCs of a method that have no deadline, for
+        example: CreateVolume=5m,DeleteVolume=2m. They take precedence over
+        X_CSI_RPC_TIMEOUT.
+
+    X_CSI_RPC_TIMEOUT_MAX_PER_METHOD
+        The maximum timeouts of the RPCs of a method, for example:
+        CreateVolume=10m,DeleteVolume=5m. They take precedence over
+        X_CSI_RPC_TIMEOUT_MAX.
+
+    X_CSI_DISABLE_RECOVERY
+        A flag that disables the recovery from panics that occur while
+        handling RPCs. By default the panic and its stack trace are logged
+        and the RPC fails with the gRPC status code Internal, unless the
+        storage plug-in's IsPanicFatal callback returns true.
+
+    X_CSI_CONCURRENCY_MAX_IN_FLIGHT
+        The maximum number of Controller, GroupController, and Node RPCs
+        handled at the same time. The Identity service is not limited.
+        The concurrency limits are enabled if this value or
+        X_CSI_CONCURRENCY_MAX_IN_FLIGHT_PER_METHOD is set.
+
+    X_CSI_CONCURRENCY_MAX_IN_FLIGHT_PER_METHOD
+        The maximum number of RPCs of a method handled at the same time,
+        for example: CreateVolume=10,DeleteVolume=10
+
+    X_CSI_CONCURRENCY_MAX_QUEUE
+        The maximum number of RPCs that wait, in order, for the RPCs in
+        flight to complete when a concurrency limit is reached. The RPCs
+        that exceed a limit when the queue is full are rejected. The
+        default value, 0, rejects them immediately.
+
+    X_CSI_CONCURRENCY_REJECT_CODE
+        The gRPC status code of rejected RPCs, either ResourceExhausted or
+        Aborted. The default value is ResourceExhausted.
+
+    X_CSI_CAPABILITY_ENFORCEMENT
+        How RPCs that require a capability the storage plug-in does not
+        advertise are handled. The capabilities are queried with
+        GetPluginCapabilities and the GetCapabilities RPCs of the served
+        services when the storage plug-in starts. A value of "reject"
+        rejects the RPCs with Unimplemented and a value of "warn" logs
+        them. The RPCs are not checked by default.
+
+    X_CSI_SELF_CHECK
+        Enables a self-check of the registered services when the storage
+        plug-in starts. The info and capability RPCs of the services are
+        called, their responses are validated against the CSI
+        specification, and the advertised CONTROLLER_SERVICE and
+        GROUP_CONTROLLER_SERVICE capabilities are checked against the
+        services served in the X_CSI_MODE. A value of "fail" refuses to
+        start when a problem is found and a value of "warn" logs the
+        problems. The self-check is disabled by default.
+
     X_CSI_SERIAL_VOL_ACCESS
-        A flag that enables the serial volume access middleware.
+        A flag that enables the serial volume access middleware. Mutating
+        RPCs obtain exclusive locks for their volumes. CreateSnapshot and
+        CreateVolumeGroupSnapshot lock their source volumes as well as
+        their snapshot names, and DeleteSnapshot and
+        DeleteVolumeGroupSnapshot lock their snapshot IDs. The read-only
+        RPCs ControllerGetVolume, ValidateVolumeCapabilities, and
+        NodeGetVolumeStats obtain shared locks so they wait for mutating
+        RPCs but not for each other.
 
     X_CSI_SERIAL_VOL_ACCESS_TIMEOUT
         A time.Duration string that determines how long the serial volume
         access middleware waits to obtain a lock for the request's volume before
         returning a the gRPC error code FailedPrecondition (5) to indicate
-        an operation is already pending for the specified volume.
+        an operation is already pending for the specified volume. The
+        middleware stops waiting early if the request is canceled or its
+        deadline is exceeded, and returns the gRPC error code Canceled (1)
+        or DeadlineExceeded (4).
+
+    X_CSI_SERIAL_VOL_ACCESS_RPCS
+        A comma-separated list of the names of the RPCs that are serialized
+        by the serial volume access middleware, for example
+        "NodeStageVolume,NodeUnstageVolume,NodePublishVolume". By default
+        all of the following RPCs are serialized: CreateVolume,
+        DeleteVolume, ControllerPublishVolume, ControllerUnpublishVolume,
+        ControllerExpandVolume, ControllerModifyVolume,
+        ControllerGetVolume, ValidateVolumeCapabilities, CreateSnapshot,
+        DeleteSnapshot, CreateVolumeGroupSnapshot,
+        DeleteVolumeGroupSnapshot, NodeStageVolume, NodeUnstageVolume,
+        NodePublishVolume, NodeUnpublishVolume, NodeExpandVolume, and
+        NodeGetVolumeStats.
 
     X_CSI_SERIAL_VOL_ACCESS_ETCD_DOMAIN
         The name of the environment variable that defines the etcd lock
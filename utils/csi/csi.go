@@ -30,8 +30,11 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
@@ -39,6 +42,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/coreos/go-systemd/v22/activation"
 )
 
 // CSIEndpoint is the name of the environment variable that
@@ -66,13 +70,177 @@ func GetCSIEndpoint() (network, addr string, err error) {
 }
 
 // GetCSIEndpointListener returns the net.Listener for the endpoint
-// specified by the environment variable CSI_ENDPOINT.
+// specified by the environment variable CSI_ENDPOINT. Inherited
+// listeners are supported as described by ListenCSIEndpoint.
 func GetCSIEndpointListener() (net.Listener, error) {
-	proto, addr, err := GetCSIEndpoint()
+	protoAddr := os.Getenv(CSIEndpoint)
+	if emptyRX.MatchString(protoAddr) {
+		return nil, errors.New("missing CSI_ENDPOINT")
+	}
+	lis, _, err := ListenCSIEndpointRemoveStale(
+		protoAddr, os.Getenv(CSIEndpointRemoveStale))
+	return lis, err
+}
+
+// ListenCSIEndpointRemoveStale returns the net.Listener for the provided
+// endpoint as ListenCSIEndpoint does. A stale UNIX socket file at the
+// endpoint is removed first with RemoveStaleCSIEndpoint unless
+// removeStale, the value of X_CSI_ENDPOINT_REMOVE_STALE, is false.
+func ListenCSIEndpointRemoveStale(
+	protoAddr, removeStale string,
+) (net.Listener, bool, error) {
+	if v, err := strconv.ParseBool(removeStale); err != nil || v {
+		if err := RemoveStaleCSIEndpoint(protoAddr); err != nil {
+			return nil, false, err
+		}
+	}
+	return ListenCSIEndpoint(protoAddr)
+}
+
+// CSIEndpointRemoveStale is the name of the environment variable that
+// determines whether or not GetCSIEndpointListener removes a stale UNIX
+// socket file at the CSI endpoint. The default value is true.
+const CSIEndpointRemoveStale = "X_CSI_ENDPOINT_REMOVE_STALE"
+
+// ErrSocketInUse occurs when a process is listening on the UNIX socket
+// file that RemoveStaleCSIEndpoint is asked to remove.
+var ErrSocketInUse = errors.New("another process is listening on the socket")
+
+// staleSocketDialTimeout is the time allowed to connect to a UNIX socket
+// file to determine whether or not it is stale.
+const staleSocketDialTimeout = time.Second
+
+// RemoveStaleCSIEndpoint removes the UNIX socket file of the provided
+// endpoint if no process is listening on it, such as a socket file left
+// behind by a process that crashed. An error that wraps ErrSocketInUse
+// is returned if a process is listening on the socket. The endpoint is
+// ignored if it is not a UNIX socket file, or if it refers to an
+// inherited listener.
+func RemoveStaleCSIEndpoint(protoAddr string) error {
+	if strings.HasPrefix(protoAddr, SystemdEndpointScheme) ||
+		strings.HasPrefix(protoAddr, FDEndpointScheme) {
+		return nil
+	}
+	proto, addr, err := ParseProtoAddr(protoAddr)
+	if err != nil || proto != "unix" {
+		return nil
+	}
+
+	fi, err := os.Lstat(addr)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if fi.Mode()&os.ModeSocket == 0 {
+		return nil
+	}
+
+	conn, err := net.DialTimeout(proto, addr, staleSocketDialTimeout)
+	if err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("%s: %w", addr, ErrSocketInUse)
+	}
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		return fmt.Errorf("failed to check sock file: %s: %w", addr, err)
+	}
+
+	if err := os.Remove(addr); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	log.WithField("path", addr).Info("removed stale sock file")
+	return nil
+}
+
+const (
+	// SystemdEndpointScheme is the scheme of an endpoint that refers to
+	// a socket passed to the process with systemd socket activation.
+	// The endpoint "systemd://" refers to the first socket, and the
+	// endpoint "systemd://NAME" refers to the first socket named NAME
+	// with the FileDescriptorName option of the socket unit. Each socket
+	// is listened on once, so a later endpoint refers to the next socket.
+	SystemdEndpointScheme = "systemd://"
+
+	// FDEndpointScheme is the scheme of an endpoint that refers to a
+	// listening socket inherited by the process as an open file
+	// descriptor, for example "fd://3".
+	FDEndpointScheme = "fd://"
+)
+
+// systemdFiles are the files passed to the process with systemd socket
+// activation that have not been taken yet. The files are loaded, and the
+// LISTEN_* environment variables unset, the first time a file is taken.
+var systemdFiles struct {
+	sync.Mutex
+	loaded bool
+	files  []*os.File
+}
+
+// takeSystemdFile removes and returns the first systemd file with the
+// provided name, or the first systemd file if the name is empty. The
+// caller must close the returned file.
+func takeSystemdFile(name string) *os.File {
+	systemdFiles.Lock()
+	defer systemdFiles.Unlock()
+	if !systemdFiles.loaded {
+		systemdFiles.files = activation.Files(true)
+		systemdFiles.loaded = true
+	}
+	for i, f := range systemdFiles.files {
+		if name == "" || f.Name() == name {
+			systemdFiles.files = append(
+				systemdFiles.files[:i:i], systemdFiles.files[i+1:]...)
+			return f
+		}
+	}
+	return nil
+}
+
+// ListenCSIEndpoint returns the net.Listener for the provided endpoint.
+// The endpoint is either a network address parsed by ParseProtoAddr, on
+// which a new listener is created, or a reference to an inherited
+// listening socket with the SystemdEndpointScheme or FDEndpointScheme.
+// The returned flag is true if the listener is inherited, in which case
+// the listener does not remove its UNIX socket file when closed.
+func ListenCSIEndpoint(protoAddr string) (net.Listener, bool, error) {
+	switch {
+	case strings.HasPrefix(protoAddr, SystemdEndpointScheme):
+		name := strings.TrimPrefix(protoAddr, SystemdEndpointScheme)
+		f := takeSystemdFile(name)
+		if f == nil {
+			return nil, false, fmt.Errorf("no systemd socket: %s", protoAddr)
+		}
+		// The listener uses a duplicate of the file's descriptor.
+		defer f.Close()
+		lis, err := net.FileListener(f)
+		if err != nil {
+			return nil, false, fmt.Errorf(
+				"invalid systemd socket: %s: %w", f.Name(), err)
+		}
+		return lis, true, nil
+
+	case strings.HasPrefix(protoAddr, FDEndpointScheme):
+		fd, err := strconv.Atoi(strings.TrimPrefix(protoAddr, FDEndpointScheme))
+		if err != nil || fd < 3 {
+			return nil, false, fmt.Errorf("invalid fd endpoint: %s", protoAddr)
+		}
+		f := os.NewFile(uintptr(fd), protoAddr)
+		defer f.Close()
+		lis, err := net.FileListener(f)
+		if err != nil {
+			return nil, false, fmt.Errorf(
+				"invalid fd endpoint: %s: %w", protoAddr, err)
+		}
+		return lis, true, nil
+	}
+
+	proto, addr, err := ParseProtoAddr(protoAddr)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
-	return net.Listen(proto, addr)
+	lis, err := net.Listen(proto, addr)
+	return lis, false, err
 }
 
 const (
@@ -22,9 +22,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"syscall"
 	"testing"
 
 	"github.com/dell/gocsi/mock/service"
@@ -790,6 +792,102 @@ func TestGetCSIEndpointListener(t *testing.T) {
 	Ω(lis).Should(BeNil())
 }
 
+func TestListenCSIEndpoint(t *testing.T) {
+	RegisterTestingT(t)
+
+	// Test case: New listener
+	lis, inherited, err := utils.ListenCSIEndpoint("tcp://127.0.0.1:0")
+	Ω(err).ShouldNot(HaveOccurred())
+	Ω(inherited).Should(BeFalse())
+	defer lis.Close()
+
+	// Test case: Inherited file descriptor
+	f, err := lis.(*net.TCPListener).File()
+	Ω(err).ShouldNot(HaveOccurred())
+	fd, err := syscall.Dup(int(f.Fd()))
+	Ω(err).ShouldNot(HaveOccurred())
+	Ω(f.Close()).Should(Succeed())
+	fdLis, inherited, err := utils.ListenCSIEndpoint(fmt.Sprintf("fd://%d", fd))
+	Ω(err).ShouldNot(HaveOccurred())
+	Ω(inherited).Should(BeTrue())
+	Ω(fdLis.Addr().String()).Should(Equal(lis.Addr().String()))
+	Ω(fdLis.Close()).Should(Succeed())
+
+	// Test case: Invalid file descriptors
+	for _, endpoint := range []string{"fd://", "fd://stdin", "fd://0"} {
+		_, _, err = utils.ListenCSIEndpoint(endpoint)
+		Ω(err).Should(HaveOccurred())
+	}
+
+	// Test case: No systemd socket
+	_, _, err = utils.ListenCSIEndpoint("systemd://csi")
+	Ω(err).Should(HaveOccurred())
+}
+
+func TestRemoveStaleCSIEndpoint(t *testing.T) {
+	RegisterTestingT(t)
+
+	sockFile := filepath.Join(t.TempDir(), "csi.sock")
+	endpoint := "unix://" + sockFile
+
+	// Test case: No sock file
+	Ω(utils.RemoveStaleCSIEndpoint(endpoint)).Should(Succeed())
+
+	// Test case: Stale sock file
+	lis, err := net.Listen("unix", sockFile)
+	Ω(err).ShouldNot(HaveOccurred())
+	lis.(*net.UnixListener).SetUnlinkOnClose(false)
+	Ω(lis.Close()).Should(Succeed())
+	Ω(sockFile).Should(BeAnExistingFile())
+	Ω(utils.RemoveStaleCSIEndpoint(endpoint)).Should(Succeed())
+	Ω(sockFile).ShouldNot(BeAnExistingFile())
+
+	// Test case: Live sock file
+	lis, err = net.Listen("unix", sockFile)
+	Ω(err).ShouldNot(HaveOccurred())
+	defer lis.Close()
+	err = utils.RemoveStaleCSIEndpoint(endpoint)
+	Ω(errors.Is(err, utils.ErrSocketInUse)).Should(BeTrue())
+	Ω(sockFile).Should(BeAnExistingFile())
+
+	// Test case: Regular file
+	regFile := filepath.Join(t.TempDir(), "csi.sock")
+	Ω(os.WriteFile(regFile, nil, 0o600)).Should(Succeed())
+	Ω(utils.RemoveStaleCSIEndpoint(regFile)).Should(Succeed())
+	Ω(regFile).Should(BeAnExistingFile())
+
+	// Test case: TCP and inherited endpoints
+	Ω(utils.RemoveStaleCSIEndpoint("tcp://127.0.0.1:0")).Should(Succeed())
+	Ω(utils.RemoveStaleCSIEndpoint("fd://3")).Should(Succeed())
+	Ω(utils.RemoveStaleCSIEndpoint("systemd://")).Should(Succeed())
+}
+
+func TestListenCSIEndpointRemoveStale(t *testing.T) {
+	RegisterTestingT(t)
+
+	sockFile := filepath.Join(t.TempDir(), "csi.sock")
+	endpoint := "unix://" + sockFile
+
+	lis, err := net.Listen("unix", sockFile)
+	Ω(err).ShouldNot(HaveOccurred())
+	lis.(*net.UnixListener).SetUnlinkOnClose(false)
+	Ω(lis.Close()).Should(Succeed())
+
+	// Test case: Stale sock file is kept
+	_, _, err = utils.ListenCSIEndpointRemoveStale(endpoint, "false")
+	Ω(err).Should(HaveOccurred())
+	Ω(sockFile).Should(BeAnExistingFile())
+
+	// Test case: Stale sock file is removed by default
+	for _, removeStale := range []string{"", "true", "invalid"} {
+		lis, inherited, err := utils.ListenCSIEndpointRemoveStale(endpoint, removeStale)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(inherited).Should(BeFalse())
+		lis.(*net.UnixListener).SetUnlinkOnClose(false)
+		Ω(lis.Close()).Should(Succeed())
+	}
+}
+
 func TestIsVolumeCapabilityCompatible(t *testing.T) {
 	RegisterTestingT(t)
 
@@ -129,6 +129,131 @@ func ChainUnaryServer(
 	}
 }
 
+// ChainStreamClient chains one or more stream, client interceptors
+// together into a left-to-right series that can be provided to a
+// new gRPC client.
+func ChainStreamClient(
+	i ...grpc.StreamClientInterceptor,
+) grpc.StreamClientInterceptor {
+	switch len(i) {
+	case 0:
+		return func(
+			ctx context.Context,
+			desc *grpc.StreamDesc,
+			cc *grpc.ClientConn,
+			method string,
+			streamer grpc.Streamer,
+			opts ...grpc.CallOption,
+		) (grpc.ClientStream, error) {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+	case 1:
+		return i[0]
+	}
+
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		bc := func(
+			cur grpc.StreamClientInterceptor,
+			nxt grpc.Streamer,
+		) grpc.Streamer {
+			return func(
+				curCtx context.Context,
+				curDesc *grpc.StreamDesc,
+				curCC *grpc.ClientConn,
+				curMethod string,
+				curOpts ...grpc.CallOption,
+			) (grpc.ClientStream, error) {
+				return cur(
+					curCtx,
+					curDesc,
+					curCC,
+					curMethod,
+					nxt,
+					curOpts...)
+			}
+		}
+
+		c := streamer
+		for j := len(i) - 1; j >= 0; j-- {
+			c = bc(i[j], c)
+		}
+
+		return c(ctx, desc, cc, method, opts...)
+	}
+}
+
+// ChainStreamServer chains one or more stream, server interceptors
+// together into a left-to-right series that can be provided to a
+// new gRPC server.
+func ChainStreamServer(
+	i ...grpc.StreamServerInterceptor,
+) grpc.StreamServerInterceptor {
+	switch len(i) {
+	case 0:
+		return func(
+			srv interface{},
+			ss grpc.ServerStream,
+			_ *grpc.StreamServerInfo,
+			handler grpc.StreamHandler,
+		) error {
+			return handler(srv, ss)
+		}
+	case 1:
+		return i[0]
+	}
+
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		bc := func(
+			cur grpc.StreamServerInterceptor,
+			nxt grpc.StreamHandler,
+		) grpc.StreamHandler {
+			return func(
+				curSrv interface{},
+				curSS grpc.ServerStream,
+			) error {
+				return cur(curSrv, curSS, info, nxt)
+			}
+		}
+		c := handler
+		for j := len(i) - 1; j >= 0; j-- {
+			c = bc(i[j], c)
+		}
+		return c(srv, ss)
+	}
+}
+
+// NewServerStreamWithContext returns a grpc.ServerStream that wraps the
+// provided stream and returns ctx from its Context function. Stream,
+// server interceptors use this to hand a modified context to the
+// next handler in the chain.
+func NewServerStreamWithContext(
+	ctx context.Context,
+	ss grpc.ServerStream,
+) grpc.ServerStream {
+	return &serverStream{ServerStream: ss, ctx: ctx}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
 // IsNilResponse returns a flag indicating whether or not the provided
 // response object is a nil object wrapped inside a non-nil interface.
 func IsNilResponse(rep interface{}) bool {
@@ -215,6 +215,104 @@ func TestChainUnaryServer(t *testing.T) {
 	assert.Equal(t, "TestResponse", rep)
 }
 
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+type testCtxKey struct{}
+
+func TestChainStreamServer(t *testing.T) {
+	// Test case: Empty interceptors
+	chain0 := middleware.ChainStreamServer()
+	assert.NotNil(t, chain0)
+
+	ss := &testServerStream{ctx: context.Background()}
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream"}
+	var order []string
+	handler := func(_ interface{}, stream grpc.ServerStream) error {
+		order = append(order, "handler")
+		if v, ok := stream.Context().Value(testCtxKey{}).(string); ok {
+			order = append(order, v)
+		}
+		return nil
+	}
+	assert.NoError(t, chain0(nil, ss, info, handler))
+	assert.Equal(t, []string{"handler"}, order)
+
+	// Test case: Multiple interceptors invoked left-to-right
+	newInterceptor := func(name string) grpc.StreamServerInterceptor {
+		return func(
+			srv interface{},
+			ss grpc.ServerStream,
+			info *grpc.StreamServerInfo,
+			handler grpc.StreamHandler,
+		) error {
+			order = append(order, name+":"+info.FullMethod)
+			ctx := context.WithValue(ss.Context(), testCtxKey{}, name)
+			return handler(srv, middleware.NewServerStreamWithContext(ctx, ss))
+		}
+	}
+	order = nil
+	chainN := middleware.ChainStreamServer(
+		newInterceptor("first"), newInterceptor("second"))
+	assert.NoError(t, chainN(nil, ss, info, handler))
+	assert.Equal(t, []string{
+		"first:/test.Service/Stream",
+		"second:/test.Service/Stream",
+		"handler",
+		"second",
+	}, order)
+}
+
+func TestChainStreamClient(t *testing.T) {
+	// Test case: Empty interceptors
+	chain0 := middleware.ChainStreamClient()
+	assert.NotNil(t, chain0)
+
+	var order []string
+	streamer := func(
+		_ context.Context,
+		_ *grpc.StreamDesc,
+		_ *grpc.ClientConn,
+		method string,
+		_ ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		order = append(order, "streamer:"+method)
+		return nil, nil
+	}
+	_, err := chain0(context.Background(), &grpc.StreamDesc{},
+		&grpc.ClientConn{}, "TestMethod", streamer)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"streamer:TestMethod"}, order)
+
+	// Test case: Multiple interceptors invoked left-to-right
+	newInterceptor := func(name string) grpc.StreamClientInterceptor {
+		return func(
+			ctx context.Context,
+			desc *grpc.StreamDesc,
+			cc *grpc.ClientConn,
+			method string,
+			streamer grpc.Streamer,
+			opts ...grpc.CallOption,
+		) (grpc.ClientStream, error) {
+			order = append(order, name)
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+	}
+	order = nil
+	chainN := middleware.ChainStreamClient(
+		newInterceptor("first"), newInterceptor("second"))
+	_, err = chainN(context.Background(), &grpc.StreamDesc{},
+		&grpc.ClientConn{}, "TestMethod", streamer)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first", "second", "streamer:TestMethod"}, order)
+}
+
 func TestIsNilResponse(t *testing.T) {
 	tests := []struct {
 		name string
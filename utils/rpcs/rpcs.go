@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 )
 
 const parseMethodPatt = `^/csi\.v(\d+)\.([^/]+?)/(.+)$`
@@ -43,3 +44,18 @@ func ParseMethod(
 	}
 	return int32(v), m[2], m[3], nil
 }
+
+// ServiceMethod returns the service and method names for the provided
+// gRPC method. CSI methods are parsed with ParseMethod so that their
+// service names omit the CSI package, ex. "Controller". Other methods,
+// such as those of additional servers, are split on their last slash.
+func ServiceMethod(fullMethod string) (service, methodName string) {
+	if _, service, methodName, err := ParseMethod(fullMethod); err == nil {
+		return service, methodName
+	}
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
@@ -83,3 +83,26 @@ var _ = ginkgo.Describe("ParseMethod", func() {
 				`parsing "%d": value out of range`, math.MaxInt64)))
 	})
 })
+
+var _ = ginkgo.Describe("ServiceMethod", func() {
+	var (
+		service    string
+		methodName string
+	)
+	ginkgo.BeforeEach(func() {
+		service, methodName = rpcs.ServiceMethod(
+			ginkgo.CurrentGinkgoTestDescription().ComponentTexts[1])
+	})
+	ginkgo.It("/csi.v1.Identity/Probe", func() {
+		gomega.Ω(service).Should(gomega.Equal("Identity"))
+		gomega.Ω(methodName).Should(gomega.Equal("Probe"))
+	})
+	ginkgo.It("/ext.v1.Admin/Watch", func() {
+		gomega.Ω(service).Should(gomega.Equal("ext.v1.Admin"))
+		gomega.Ω(methodName).Should(gomega.Equal("Watch"))
+	})
+	ginkgo.It("Probe", func() {
+		gomega.Ω(service).Should(gomega.Equal("unknown"))
+		gomega.Ω(methodName).Should(gomega.Equal("Probe"))
+	})
+})